@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/saltyorg/sdc/internal/api"
+	"github.com/saltyorg/sdc/internal/client"
+	"github.com/saltyorg/sdc/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// addClientAuthFlags registers the credential flags shared by API client commands
+func addClientAuthFlags(cmd *cobra.Command, cfg *config.ClientAuthConfig) {
+	cmd.Flags().StringVar(&cfg.TokenFile, "token-file", "", "File containing the API bearer token")
+	cmd.Flags().StringVar(&cfg.TLSCA, "tls-ca", "", "CA bundle used to verify the controller certificate")
+	cmd.Flags().StringVar(&cfg.TLSCert, "tls-cert", "", "Client certificate for mTLS")
+	cmd.Flags().StringVar(&cfg.TLSKey, "tls-key", "", "Client private key for mTLS")
+}
+
+// configureClient applies token and TLS settings to an API client
+func configureClient(apiClient *client.Client, cfg config.ClientAuthConfig) error {
+	if cfg.TokenFile != "" {
+		token, err := client.ReadTokenFile(cfg.TokenFile)
+		if err != nil {
+			return err
+		}
+		apiClient.SetToken(token)
+	}
+
+	if cfg.TLSCA == "" && cfg.TLSCert == "" && cfg.TLSKey == "" {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.TLSCA != "" {
+		pool, err := loadCertPool(cfg.TLSCA)
+		if err != nil {
+			return err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.TLSCert != "" || cfg.TLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	apiClient.SetTLSConfig(tlsConfig)
+	return nil
+}
+
+// newAuthenticator builds the API authenticator from server flags.
+// Returns nil when no credentials are configured.
+func newAuthenticator(cfg config.ServerConfig) (*api.Authenticator, error) {
+	auth := api.NewAuthenticator()
+
+	if cfg.Token != "" {
+		auth.AddToken(cfg.Token)
+	}
+
+	if cfg.TokenFile != "" {
+		if err := auth.LoadTokenFile(cfg.TokenFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.TLSClientCA != "" {
+		scopes, err := api.ParseScopes(cfg.ClientCertScopes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate scopes: %w", err)
+		}
+		auth.AllowClientCerts(scopes...)
+	}
+
+	if !auth.Enabled() {
+		return nil, nil
+	}
+
+	return auth, nil
+}
+
+// serverTLSConfig builds the HTTPS configuration from server flags.
+// Returns nil when TLS is not configured.
+func serverTLSConfig(cfg config.ServerConfig) (*tls.Config, error) {
+	if cfg.TLSCert == "" && cfg.TLSKey == "" {
+		if cfg.TLSClientCA != "" {
+			return nil, fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if cfg.TLSClientCA != "" {
+		pool, err := loadCertPool(cfg.TLSClientCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		// Certificates are optional so token-only callers can still connect;
+		// presented certificates must verify against the CA
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCertPool reads a PEM CA bundle
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/client"
+	"github.com/saltyorg/sdc/internal/config"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the CLI client commands
+const (
+	exitError        = 1 // Request failed or invalid usage
+	exitJobFailed    = 2 // Job failed or some containers failed
+	exitJobCancelled = 3 // Job was superseded before it ran
+	exitBlocked      = 4 // Operation rejected by a block
+	exitLintFailed   = 5 // Lint found errors (or warnings, with --strict)
+)
+
+// Output formats of the CLI client commands
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// cliConfig holds the options shared by the CLI client commands
+var cliConfig struct {
+	ControllerURL string
+	Output        string
+	Retries       int
+	config.ClientAuthConfig
+}
+
+// exitCodeError carries a specific process exit code
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit code for an error returned by a command
+func exitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	if errors.Is(err, client.ErrBlocked) {
+		return exitBlocked
+	}
+	return exitError
+}
+
+// addCLIFlags registers the connection and output flags of a CLI client command
+func addCLIFlags(cmd *cobra.Command) {
+	defaultURL := os.Getenv("SDC_CONTROLLER_URL")
+	if defaultURL == "" {
+		defaultURL = "http://127.0.0.1:3377"
+	}
+
+	cmd.Flags().StringVar(&cliConfig.ControllerURL, "controller-url", defaultURL, "Controller API URL or unix:///path/to.sock (env SDC_CONTROLLER_URL)")
+	cmd.Flags().StringVarP(&cliConfig.Output, "output", "o", outputTable, "Output format (table, json)")
+	cmd.Flags().IntVar(&cliConfig.Retries, "retries", client.DefaultRetryPolicy.MaxAttempts, "Attempts per API request on connection failures and server errors")
+	addClientAuthFlags(cmd, &cliConfig.ClientAuthConfig)
+
+	// Errors are reported by main; usage only helps for flag errors
+	cmd.SilenceUsage = true
+}
+
+// newCLIClient creates an API client from the CLI flags. Client logs go to
+// stderr, at warn level unless --log-level was given, to keep stdout parseable.
+func newCLIClient(cmd *cobra.Command) (*client.Client, error) {
+	if cliConfig.Output != outputTable && cliConfig.Output != outputJSON {
+		return nil, fmt.Errorf("invalid output format %q: must be %s or %s", cliConfig.Output, outputTable, outputJSON)
+	}
+
+	level := slog.LevelWarn
+	if cmd.Flags().Changed("log-level") {
+		parsed, err := logger.ParseLevel(logConfig.Level)
+		if err != nil {
+			return nil, err
+		}
+		level = parsed
+	}
+
+	format, err := logger.ParseFormat(logConfig.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	log, err := logger.NewWithOptions(logger.Options{
+		Level:  level,
+		Format: format,
+		Output: os.Stderr,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	apiClient := client.NewClient(cliConfig.ControllerURL, log)
+	apiClient.SetUserAgent("sdc-cli/" + Version)
+
+	retryPolicy := client.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = cliConfig.Retries
+	apiClient.SetRetryPolicy(retryPolicy)
+
+	if err := configureClient(apiClient, cliConfig.ClientAuthConfig); err != nil {
+		return nil, fmt.Errorf("failed to configure client: %w", err)
+	}
+	return apiClient, nil
+}
+
+// cliContext returns a context cancelled on SIGINT/SIGTERM
+func cliContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+// printJSON writes v as indented JSON
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// newTable returns a tabwriter for aligned table output; call Flush when done
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+// joinOrDash joins names for table cells, showing "-" for an empty list
+func joinOrDash(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
+
+// formatTime formats a timestamp for table cells, showing "-" for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/client"
+	"github.com/spf13/cobra"
+)
+
+// blockFlags holds the options of the block command
+var blockFlags client.CreateBlockRequest
+
+var blockCmd = &cobra.Command{
+	Use:   "block [container...]",
+	Short: "Block start/stop operations",
+	Long: `Creates a block. With container names, operations on just those containers
+are blocked; otherwise all operations are, or only starts or stops with --scope.`,
+	RunE: runBlock,
+}
+
+var unblockCmd = &cobra.Command{
+	Use:   "unblock [block-id...]",
+	Short: "Remove blocks",
+	Long:  `Removes the given blocks, or all global blocks if no IDs are given.`,
+	RunE:  runUnblock,
+}
+
+var blocksCmd = &cobra.Command{
+	Use:   "blocks",
+	Short: "List active blocks",
+	Args:  cobra.NoArgs,
+	RunE:  runBlocks,
+}
+
+func init() {
+	blockCmd.Flags().StringVar(&blockFlags.Scope, "scope", "", "Block scope: global, start, stop or containers (default: containers if names are given, else global)")
+	blockCmd.Flags().StringVar(&blockFlags.Reason, "reason", "", "Why operations are blocked")
+	blockCmd.Flags().StringVar(&blockFlags.Duration, "duration", "", "How long the block lasts, e.g. 90m (default: server default)")
+	addCLIFlags(blockCmd)
+	rootCmd.AddCommand(blockCmd)
+
+	addCLIFlags(unblockCmd)
+	rootCmd.AddCommand(unblockCmd)
+
+	addCLIFlags(blocksCmd)
+	rootCmd.AddCommand(blocksCmd)
+}
+
+func runBlock(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	req := blockFlags
+	req.Containers = args
+
+	block, err := apiClient.CreateBlock(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to create block: %w", err)
+	}
+
+	return printBlocks(cmd, []client.Block{*block})
+}
+
+func runUnblock(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	if len(args) == 0 {
+		if err := apiClient.Unblock(ctx); err != nil {
+			return fmt.Errorf("failed to remove global blocks: %w", err)
+		}
+		fmt.Fprintln(cmd.ErrOrStderr(), "Removed all global blocks")
+		return nil
+	}
+
+	for _, id := range args {
+		if err := apiClient.DeleteBlock(ctx, id); err != nil {
+			return fmt.Errorf("failed to remove block %s: %w", id, err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Removed block %s\n", id)
+	}
+	return nil
+}
+
+func runBlocks(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	blocks, err := apiClient.ListBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	return printBlocks(cmd, blocks)
+}
+
+// printBlocks prints blocks in the selected output format
+func printBlocks(cmd *cobra.Command, blocks []client.Block) error {
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		return printJSON(out, blocks)
+	}
+
+	table := newTable(out)
+	fmt.Fprintln(table, "ID\tSCOPE\tCONTAINERS\tREASON\tCREATOR\tEXPIRES")
+	for _, block := range blocks {
+		reason := block.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		expires := fmt.Sprintf("%s (in %s)", formatTime(block.ExpiresAt), time.Until(block.ExpiresAt).Round(time.Second))
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			block.ID, block.Scope, joinOrDash(block.Containers), reason, block.Creator, expires)
+	}
+	return table.Flush()
+}
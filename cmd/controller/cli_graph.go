@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/saltyorg/sdc/internal/client"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show the container dependency graph and startup batches",
+	Args:  cobra.NoArgs,
+	RunE:  runGraph,
+}
+
+var containersCmd = &cobra.Command{
+	Use:   "containers",
+	Short: "List labelled containers and why each is or isn't managed",
+	Args:  cobra.NoArgs,
+	RunE:  runContainers,
+}
+
+// containersFlags holds the options of the containers command
+var containersFlags struct {
+	State   string
+	Name    string
+	Managed bool
+}
+
+func init() {
+	addCLIFlags(graphCmd)
+	rootCmd.AddCommand(graphCmd)
+
+	containersCmd.Flags().StringVar(&containersFlags.State, "state", "", "Only containers in this state (running, exited, ..., or missing)")
+	containersCmd.Flags().StringVar(&containersFlags.Name, "name", "", "Only containers whose name contains this text")
+	containersCmd.Flags().BoolVar(&containersFlags.Managed, "managed", false, "Only managed (--managed) or unmanaged (--managed=false) containers")
+	addCLIFlags(containersCmd)
+	rootCmd.AddCommand(containersCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	g, err := apiClient.GetGraph(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		return printJSON(out, g)
+	}
+
+	for _, cycle := range g.Cycles {
+		fmt.Fprintf(out, "Dependency cycle: %s\n", strings.Join(cycle, " -> "))
+	}
+	for i, batch := range g.Batches {
+		fmt.Fprintf(out, "Batch %d: %s\n", i, strings.Join(batch, ", "))
+	}
+	if len(g.Batches) > 0 || len(g.Cycles) > 0 {
+		fmt.Fprintln(out)
+	}
+
+	table := newTable(out)
+	fmt.Fprintln(table, "CONTAINER\tDEPENDS ON\tDEPENDENTS")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", node.Name, joinOrDash(markOptional(node.Parents, node.OptionalParents)), joinOrDash(node.Children))
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	printed := false
+	for _, node := range g.Nodes {
+		for _, pattern := range slices.Sorted(maps.Keys(node.Patterns)) {
+			if !printed {
+				fmt.Fprintln(out, "\nDependency patterns:")
+				printed = true
+			}
+			fmt.Fprintf(out, "  %s: %s -> %s\n", node.Name, pattern, joinOrDash(node.Patterns[pattern]))
+		}
+	}
+	return nil
+}
+
+// markOptional prefixes optional dependencies with "?", as in the depends_on label
+func markOptional(parents, optional []string) []string {
+	marked := make([]string, len(parents))
+	for i, parent := range parents {
+		if slices.Contains(optional, parent) {
+			parent = "?" + parent
+		}
+		marked[i] = parent
+	}
+	return marked
+}
+
+func runContainers(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	filter := client.ContainerFilter{State: containersFlags.State, Name: containersFlags.Name}
+	if cmd.Flags().Changed("managed") {
+		filter.Managed = &containersFlags.Managed
+	}
+
+	containers, err := apiClient.ListContainers(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		return printJSON(out, containers)
+	}
+
+	table := newTable(out)
+	fmt.Fprintln(table, "CONTAINER\tSTATE\tHEALTH\tMANAGED\tBATCH\tDEPENDS ON\tNOTES")
+	for _, info := range containers {
+		health := info.Health
+		if health == "" {
+			health = "-"
+		}
+		batch := "-"
+		if info.Depth != nil {
+			batch = fmt.Sprint(*info.Depth)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n",
+			info.Name, info.State, health, info.Managed, batch, joinOrDash(info.Parents), containerNotes(info))
+	}
+	return table.Flush()
+}
+
+// containerNotes explains why a container may not be started or stopped as expected
+func containerNotes(info client.ContainerInfo) string {
+	var notes []string
+	if info.UnmanagedReason != "" {
+		notes = append(notes, "unmanaged: "+info.UnmanagedReason)
+	}
+	if info.Placeholder {
+		notes = append(notes, "treated as a missing dependency")
+	}
+	if len(info.MissingDependencies) > 0 {
+		notes = append(notes, "missing dependencies: "+strings.Join(info.MissingDependencies, ","))
+	}
+	if len(notes) == 0 {
+		return "-"
+	}
+	return strings.Join(notes, "; ")
+}
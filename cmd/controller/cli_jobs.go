@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/client"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// jobFlags holds the options of the start, stop and restart commands
+var jobFlags struct {
+	Timeout        int
+	Ignore         []string
+	NoDependencies bool
+	Supersede      bool
+	Wait           bool
+	PollInterval   time.Duration
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start [container|group=name...]",
+	Short: "Start containers in dependency order",
+	Long: `Submits a start job for the given containers and groups (all managed containers
+if none are given), including the containers they depend on unless --no-deps is set.
+A group=<name> argument selects every container with that name in its groups label,
+and --ignore accepts group:<name> entries.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runJobCommand(cmd, client.JobTypeStart, args)
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [container|group=name...]",
+	Short: "Stop containers in reverse dependency order",
+	Long: `Submits a stop job for the given containers and groups (all managed containers
+if none are given), including the containers that depend on them unless --no-deps
+is set. A group=<name> argument selects every container with that name in its groups
+label, and --ignore accepts group:<name> entries.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runJobCommand(cmd, client.JobTypeStop, args)
+	},
+}
+
+var restartCmd = &cobra.Command{
+	Use:   "restart [container|group=name...]",
+	Short: "Stop and then start containers",
+	Long: `Stops the given containers and groups (all managed containers if none are given), waits
+for the stop job to finish and then starts them again, along with any dependents
+the stop job stopped. The start job is submitted even if some containers failed to
+stop, so that none are left down; the stop failure is still reported.`,
+	RunE: runRestart,
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status <job-id>",
+	Short: "Show the status and results of a job",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runStatus,
+}
+
+// statusFlags holds the options of the status command
+var statusFlags struct {
+	Wait         bool
+	Logs         bool
+	PollInterval time.Duration
+}
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List recent jobs, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runJobs,
+}
+
+// jobsFlags holds the options of the jobs command
+var jobsFlags client.JobFilter
+
+func init() {
+	for _, cmd := range []*cobra.Command{startCmd, stopCmd, restartCmd} {
+		cmd.Flags().IntVar(&jobFlags.Timeout, "timeout", 0, "Job timeout in seconds (default: server default for the operation)")
+		cmd.Flags().StringSliceVar(&jobFlags.Ignore, "ignore", nil, "Containers or group:<name> selectors to skip (comma-separated or repeated)")
+		cmd.Flags().BoolVar(&jobFlags.NoDependencies, "no-deps", false, "Only operate on the named containers, not their dependencies or dependents")
+		cmd.Flags().BoolVar(&jobFlags.Supersede, "supersede", false, "Cancel pending jobs of the opposite operation on the same containers")
+		cmd.Flags().BoolVarP(&jobFlags.Wait, "wait", "w", false, "Wait for the job to finish, showing its progress")
+		cmd.Flags().DurationVar(&jobFlags.PollInterval, "poll-interval", 1*time.Second, "Job status polling interval while waiting")
+		addCLIFlags(cmd)
+		rootCmd.AddCommand(cmd)
+	}
+
+	statusCmd.Flags().BoolVarP(&statusFlags.Wait, "wait", "w", false, "Wait for the job to finish, showing its progress")
+	statusCmd.Flags().BoolVar(&statusFlags.Logs, "logs", false, "Print the log lines captured for the job")
+	statusCmd.Flags().DurationVar(&statusFlags.PollInterval, "poll-interval", 1*time.Second, "Job status polling interval while waiting")
+	addCLIFlags(statusCmd)
+	rootCmd.AddCommand(statusCmd)
+
+	jobsCmd.Flags().StringVar(&jobsFlags.Status, "status", "", "Only jobs with this status (pending, running, completed, failed, cancelled)")
+	jobsCmd.Flags().StringVar(&jobsFlags.Type, "type", "", "Only jobs of this type (start, stop)")
+	jobsCmd.Flags().IntVar(&jobsFlags.Limit, "limit", 20, "Maximum number of jobs (0 for the server default)")
+	addCLIFlags(jobsCmd)
+	rootCmd.AddCommand(jobsCmd)
+}
+
+// jobRequest builds the request for a start, stop or restart command
+func jobRequest(targets []string) client.JobRequest {
+	req := client.JobRequest{
+		Timeout: jobFlags.Timeout,
+		Ignore:  jobFlags.Ignore,
+		Targets: targets,
+	}
+	if jobFlags.NoDependencies || jobFlags.Supersede {
+		req.Options = &client.JobOptions{Supersede: jobFlags.Supersede}
+		if jobFlags.NoDependencies {
+			dependencies := false
+			req.Options.Dependencies = &dependencies
+		}
+	}
+	return req
+}
+
+func runJobCommand(cmd *cobra.Command, jobType string, targets []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	job, err := submitJob(ctx, apiClient, jobType, jobRequest(targets), jobFlags.Wait)
+	if err != nil {
+		return err
+	}
+
+	if err := printJob(cmd.OutOrStdout(), job); err != nil {
+		return err
+	}
+	return jobResultError(job)
+}
+
+func runRestart(cmd *cobra.Command, targets []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	req := jobRequest(targets)
+
+	// The stop job must finish before the start job can be submitted
+	stopJob, err := submitJob(ctx, apiClient, client.JobTypeStop, req, true)
+	if err != nil {
+		return err
+	}
+
+	// Containers are started again even if some failed to stop, so that none
+	// are left down, unless the stop job was superseded before it ran
+	var startJob *client.Job
+	if stopJob.Status != client.JobStatusCancelled {
+		// Dependents stopped along with the targets are started again too
+		req.Targets = jobs.RestartTargets(req.Targets, stopJob.Stopped)
+		startJob, err = submitJob(ctx, apiClient, client.JobTypeStart, req, jobFlags.Wait)
+		if err != nil {
+			return err
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		if err := printJSON(out, map[string]*client.Job{"stop": stopJob, "start": startJob}); err != nil {
+			return err
+		}
+	} else {
+		if err := printJob(out, stopJob); err != nil {
+			return err
+		}
+		if startJob != nil {
+			fmt.Fprintln(out)
+			if err := printJob(out, startJob); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := jobResultError(stopJob); err != nil {
+		if startJob == nil {
+			return fmt.Errorf("not starting containers: %w", err)
+		}
+		return err
+	}
+	return jobResultError(startJob)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	jobID := args[0]
+
+	var job *client.Job
+	if statusFlags.Wait {
+		job, err = followJob(ctx, apiClient, jobID, statusFlags.PollInterval)
+	} else {
+		job, err = apiClient.GetJob(ctx, jobID)
+	}
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return fmt.Errorf("job %s not found", jobID)
+		}
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if err := printJob(out, job); err != nil {
+		return err
+	}
+
+	if statusFlags.Logs && !statusFlags.Wait {
+		logs, err := apiClient.GetJobLogs(ctx, jobID)
+		if err != nil {
+			return fmt.Errorf("failed to get job logs: %w", err)
+		}
+		if cliConfig.Output == outputJSON {
+			if err := printJSON(out, logs); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprintln(out)
+			if logs.Dropped > 0 {
+				fmt.Fprintf(out, "(%d earlier lines dropped)\n", logs.Dropped)
+			}
+			for _, entry := range logs.Entries {
+				printLogEntry(out, entry)
+			}
+		}
+	}
+
+	return jobResultError(job)
+}
+
+func runJobs(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	jobs, err := apiClient.ListJobs(ctx, jobsFlags)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		return printJSON(out, jobs)
+	}
+
+	table := newTable(out)
+	fmt.Fprintln(table, "ID\tTYPE\tSTATUS\tCREATED\tDURATION\tTARGETS\tFAILED")
+	for _, job := range jobs {
+		targets := "all"
+		if len(job.Targets) > 0 {
+			targets = strings.Join(job.Targets, ",")
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			job.ID, job.Type, job.Status, formatTime(job.CreatedAt), jobDuration(&job), targets, joinOrDash(job.Failed))
+	}
+	return table.Flush()
+}
+
+// submitJob submits a job and, if wait is set, follows it until it finishes.
+// Without wait, the returned job only carries the ID and initial status.
+func submitJob(ctx context.Context, apiClient *client.Client, jobType string, req client.JobRequest, wait bool) (*client.Job, error) {
+	resp, err := apiClient.SubmitJob(ctx, jobType, req)
+	if err != nil {
+		if errors.Is(err, client.ErrBlocked) {
+			return nil, fmt.Errorf("%s blocked: %w", jobType, err)
+		}
+		return nil, fmt.Errorf("failed to submit %s job: %w", jobType, err)
+	}
+
+	if resp.Coalesced {
+		fmt.Fprintf(os.Stderr, "Joined pending %s job %s\n", jobType, resp.ID)
+	} else {
+		fmt.Fprintf(os.Stderr, "Submitted %s job %s\n", jobType, resp.ID)
+	}
+
+	if !wait {
+		return apiClient.GetJob(ctx, resp.ID)
+	}
+	return followJob(ctx, apiClient, resp.ID, jobFlags.PollInterval)
+}
+
+// followJob waits for a job, printing its log lines to stderr as they appear
+func followJob(ctx context.Context, apiClient *client.Client, jobID string, pollInterval time.Duration) (*client.Job, error) {
+	job, err := apiClient.FollowJob(ctx, jobID, pollInterval, func(entry logger.Entry) {
+		printLogEntry(os.Stderr, entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for job %s: %w", jobID, err)
+	}
+	return job, nil
+}
+
+// jobResultError returns an error carrying the exit code for a finished job
+// that failed, had failed containers or was cancelled. Unfinished jobs and
+// nil are not errors.
+func jobResultError(job *client.Job) error {
+	if job == nil {
+		return nil
+	}
+
+	switch {
+	case job.Status == client.JobStatusCancelled:
+		return &exitCodeError{code: exitJobCancelled, err: fmt.Errorf("%s job %s was superseded by job %s", job.Type, job.ID, job.SupersededBy)}
+	case job.Status == client.JobStatusFailed:
+		return &exitCodeError{code: exitJobFailed, err: fmt.Errorf("%s job %s failed: %s", job.Type, job.ID, job.Error)}
+	case len(job.Failed) > 0:
+		return &exitCodeError{code: exitJobFailed, err: fmt.Errorf("%s job %s failed for: %s", job.Type, job.ID, strings.Join(job.Failed, ", "))}
+	default:
+		return nil
+	}
+}
+
+// printJob prints a job in the selected output format
+func printJob(w io.Writer, job *client.Job) error {
+	if cliConfig.Output == outputJSON {
+		return printJSON(w, job)
+	}
+
+	targets := "all managed containers"
+	if len(job.Targets) > 0 {
+		targets = strings.Join(job.Targets, ", ")
+	}
+
+	table := newTable(w)
+	fmt.Fprintf(table, "Job:\t%s\n", job.ID)
+	fmt.Fprintf(table, "Type:\t%s\n", job.Type)
+	fmt.Fprintf(table, "Status:\t%s\n", job.Status)
+	fmt.Fprintf(table, "Targets:\t%s\n", targets)
+	fmt.Fprintf(table, "Created:\t%s\n", formatTime(job.CreatedAt))
+	fmt.Fprintf(table, "Duration:\t%s\n", jobDuration(job))
+	if job.Type == client.JobTypeStop {
+		fmt.Fprintf(table, "Stopped:\t%s\n", joinOrDash(job.Stopped))
+	} else {
+		fmt.Fprintf(table, "Started:\t%s\n", joinOrDash(job.Started))
+	}
+	fmt.Fprintf(table, "Skipped:\t%s\n", withReasons(job.Skipped, job.SkipReasons))
+	fmt.Fprintf(table, "Failed:\t%s\n", withReasons(job.Failed, job.FailReasons))
+	if job.CriticalPath != nil {
+		fmt.Fprintf(table, "Critical path:\t%s\n", formatCriticalPath(job.CriticalPath))
+	}
+	if job.Error != "" {
+		fmt.Fprintf(table, "Error:\t%s\n", job.Error)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return printHooks(w, job.Hooks)
+}
+
+// printHooks prints the lifecycle hooks a job ran, by container
+func printHooks(w io.Writer, hooks map[string][]client.HookResult) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	table := newTable(w)
+	fmt.Fprintln(table, "CONTAINER\tHOOK\tACTION\tEXIT\tDURATION\tRESULT")
+	for _, name := range slices.Sorted(maps.Keys(hooks)) {
+		for _, hook := range hooks[name] {
+			result := "ok"
+			if hook.Error != "" {
+				result = hook.Error
+				if hook.OnFailure == "continue" {
+					result += " (ignored)"
+				}
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				name, hook.Phase, hook.Action, hook.ExitCode, time.Duration(hook.DurationMs)*time.Millisecond, result)
+		}
+	}
+	return table.Flush()
+}
+
+// formatCriticalPath formats a critical path as "db (5s) -> app (1.2s) = 6.2s"
+func formatCriticalPath(path *client.CriticalPath) string {
+	steps := make([]string, len(path.Steps))
+	for i, step := range path.Steps {
+		steps[i] = fmt.Sprintf("%s (%s)", step.Container, time.Duration(step.DurationMs)*time.Millisecond)
+	}
+	return fmt.Sprintf("%s = %s", strings.Join(steps, " -> "), time.Duration(path.DurationMs)*time.Millisecond)
+}
+
+// withReasons lists containers with the reason, if known, in parentheses
+func withReasons(names []string, reasons map[string]string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if reason := reasons[name]; reason != "" {
+			name += " (" + reason + ")"
+		}
+		result = append(result, name)
+	}
+	return strings.Join(result, ", ")
+}
+
+// jobDuration returns how long a job ran, or "-" if it hasn't started
+func jobDuration(job *client.Job) string {
+	if job.StartedAt.IsZero() {
+		return "-"
+	}
+	end := job.EndedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(job.StartedAt).Round(100 * time.Millisecond).String()
+}
+
+// printLogEntry prints a captured job log line as "15:04:05 LEVEL message key=value ..."
+func printLogEntry(w io.Writer, entry logger.Entry) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s", entry.Time.Local().Format(time.TimeOnly), entry.Level, entry.Message)
+	for _, key := range slices.Sorted(maps.Keys(entry.Fields)) {
+		fmt.Fprintf(&b, " %s=%v", key, entry.Fields[key])
+	}
+	fmt.Fprintln(w, b.String())
+}
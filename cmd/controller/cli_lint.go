@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// lintFlags holds the options of the lint command
+var lintFlags struct {
+	Strict bool
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check container labels and dependencies for mistakes",
+	Long: `Reports unknown or malformed Saltbox labels, dependencies on missing or
+unmanaged containers, healthcheck waits that can't work and dependency cycles.
+Exits with status 5 if there are errors (or warnings, with --strict).`,
+	Args: cobra.NoArgs,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintFlags.Strict, "strict", false, "Also fail on warnings")
+	addCLIFlags(lintCmd)
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	report, err := apiClient.Lint(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		if err := printJSON(out, report); err != nil {
+			return err
+		}
+	} else {
+		table := newTable(out)
+		fmt.Fprintln(table, "SEVERITY\tCONTAINER\tRULE\tLABEL\tMESSAGE")
+		for _, finding := range report.Findings {
+			container := finding.Container
+			if container == "" {
+				container = "-"
+			}
+			label := finding.Label
+			if label == "" {
+				label = "-"
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", finding.Severity, container, finding.Rule, label, finding.Message)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n%d errors, %d warnings, %d infos\n", report.Errors, report.Warnings, report.Infos)
+	}
+
+	failures := report.Errors
+	if lintFlags.Strict {
+		failures += report.Warnings
+	}
+	if failures > 0 {
+		return &exitCodeError{code: exitLintFailed, err: fmt.Errorf("lint found %d errors and %d warnings", report.Errors, report.Warnings)}
+	}
+	return nil
+}
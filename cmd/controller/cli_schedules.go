@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/saltyorg/sdc/internal/client"
+	"github.com/spf13/cobra"
+)
+
+// scheduleFlags holds the options of the schedule command
+var scheduleFlags struct {
+	client.CreateScheduleRequest
+	Timeout        int
+	NoDependencies bool
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule --cron <expression> [container|group=name...]",
+	Short: "Start, stop or restart containers on a cron schedule",
+	Long: `Creates a schedule that submits a job for the given containers and groups (all managed
+containers if none are given) whenever the cron expression matches, in the server's local
+time. A run is skipped while the previous run is still going or while a block covers
+the operation.`,
+	RunE: runSchedule,
+}
+
+var unscheduleCmd = &cobra.Command{
+	Use:   "unschedule <schedule-id...>",
+	Short: "Remove schedules",
+	Long:  `Removes schedules created through the API. Schedules from the schedule file cannot be removed.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runUnschedule,
+}
+
+var schedulesCmd = &cobra.Command{
+	Use:   "schedules [schedule-id]",
+	Short: "List schedules, or show the recent runs of one",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runSchedules,
+}
+
+func init() {
+	scheduleCmd.Flags().StringVar(&scheduleFlags.Cron, "cron", "", `Cron expression, e.g. "30 4 * * *" or @daily`)
+	scheduleCmd.Flags().StringVar(&scheduleFlags.Operation, "operation", "restart", "Operation to run: start, stop or restart")
+	scheduleCmd.Flags().StringVar(&scheduleFlags.Name, "name", "", "Unique schedule name")
+	scheduleCmd.Flags().IntVar(&scheduleFlags.Timeout, "timeout", 0, "Timeout of each job in seconds (default: server default for the operation)")
+	scheduleCmd.Flags().StringSliceVar(&scheduleFlags.Ignore, "ignore", nil, "Containers or group:<name> selectors to skip (comma-separated or repeated)")
+	scheduleCmd.Flags().BoolVar(&scheduleFlags.NoDependencies, "no-deps", false, "Only operate on the named containers, not their dependencies or dependents")
+	scheduleCmd.MarkFlagRequired("cron")
+	addCLIFlags(scheduleCmd)
+	rootCmd.AddCommand(scheduleCmd)
+
+	addCLIFlags(unscheduleCmd)
+	rootCmd.AddCommand(unscheduleCmd)
+
+	addCLIFlags(schedulesCmd)
+	rootCmd.AddCommand(schedulesCmd)
+}
+
+func runSchedule(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	req := scheduleFlags.CreateScheduleRequest
+	req.Targets = args
+	if scheduleFlags.Timeout > 0 {
+		req.Timeout = &scheduleFlags.Timeout
+	}
+	if scheduleFlags.NoDependencies {
+		deps := false
+		req.Dependencies = &deps
+	}
+
+	schedule, err := apiClient.CreateSchedule(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return printSchedules(cmd, []client.Schedule{*schedule})
+}
+
+func runUnschedule(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	for _, id := range args {
+		if err := apiClient.DeleteSchedule(ctx, id); err != nil {
+			return fmt.Errorf("failed to remove schedule %s: %w", id, err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Removed schedule %s\n", id)
+	}
+	return nil
+}
+
+func runSchedules(cmd *cobra.Command, args []string) error {
+	apiClient, err := newCLIClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := cliContext(cmd)
+	defer cancel()
+
+	if len(args) == 1 {
+		schedule, err := apiClient.GetSchedule(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		return printScheduleRuns(cmd, schedule)
+	}
+
+	schedules, err := apiClient.ListSchedules(ctx)
+	if err != nil {
+		return err
+	}
+	return printSchedules(cmd, schedules)
+}
+
+// printSchedules prints schedules in the selected output format
+func printSchedules(cmd *cobra.Command, schedules []client.Schedule) error {
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		return printJSON(out, schedules)
+	}
+
+	table := newTable(out)
+	fmt.Fprintln(table, "ID\tNAME\tCRON\tOPERATION\tTARGETS\tSOURCE\tNEXT RUN\tLAST RUN")
+	for _, schedule := range schedules {
+		name := schedule.Name
+		if name == "" {
+			name = "-"
+		}
+		last := "-"
+		if len(schedule.Runs) > 0 {
+			last = schedule.Runs[0].Status
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			schedule.ID, name, schedule.Cron, schedule.Operation, joinOrDash(schedule.Targets),
+			schedule.Source, formatTime(schedule.NextRun), last)
+	}
+	return table.Flush()
+}
+
+// printScheduleRuns prints the recent runs of a schedule, newest first
+func printScheduleRuns(cmd *cobra.Command, schedule *client.Schedule) error {
+	out := cmd.OutOrStdout()
+	if cliConfig.Output == outputJSON {
+		return printJSON(out, schedule)
+	}
+
+	fmt.Fprintf(out, "Schedule %s: %s %s, next run %s\n\n",
+		schedule.ID, schedule.Operation, schedule.Cron, formatTime(schedule.NextRun))
+
+	table := newTable(out)
+	fmt.Fprintln(table, "SCHEDULED\tSTATUS\tFINISHED\tJOBS\tREASON")
+	for _, run := range schedule.Runs {
+		reason := run.Reason
+		if reason == "" {
+			reason = "-"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n",
+			formatTime(run.ScheduledAt), run.Status, formatTime(run.FinishedAt), joinOrDash(run.JobIDs), reason)
+	}
+	return table.Flush()
+}
@@ -2,16 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/saltyorg/sdc/internal/client"
 	"github.com/saltyorg/sdc/internal/config"
-	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/saltyorg/sdc/internal/systemd"
 	"github.com/spf13/cobra"
 )
 
@@ -30,12 +30,14 @@ func init() {
 	helperCmd.Flags().DurationVar(&helperConfig.StartupDelay, "startup-delay", 5*time.Second, "Initial delay before starting containers")
 	helperCmd.Flags().IntVar(&helperConfig.Timeout, "timeout", 600, "Operation timeout in seconds")
 	helperCmd.Flags().DurationVar(&helperConfig.PollInterval, "poll-interval", 5*time.Second, "Job status polling interval")
+	helperCmd.Flags().IntVar(&helperConfig.Retries, "retries", client.DefaultRetryPolicy.MaxAttempts, "Attempts per API request on connection failures and server errors")
+	addClientAuthFlags(helperCmd, &helperConfig.ClientAuthConfig)
 	rootCmd.AddCommand(helperCmd)
 }
 
 func runHelper(cmd *cobra.Command, args []string) error {
 	// Initialize logger
-	log, err := logger.New(false)
+	log, err := newLogger()
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
@@ -52,30 +54,52 @@ func runHelper(cmd *cobra.Command, args []string) error {
 	// Create client
 	apiClient := client.NewClient(helperConfig.ControllerURL, log)
 	apiClient.SetUserAgent("sdc/" + Version)
+	retryPolicy := client.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = helperConfig.Retries
+	apiClient.SetRetryPolicy(retryPolicy)
+	if err := configureClient(apiClient, helperConfig.ClientAuthConfig); err != nil {
+		return fmt.Errorf("failed to configure client: %w", err)
+	}
+
+	// Progress and readiness are reported to systemd (no-op outside systemd)
+	notifier := systemd.NewNotifier()
+	watchdogCtx, stopWatchdog := context.WithCancel(ctx)
+	defer stopWatchdog()
+
+	// Only the controller's Docker connection matters to the watchdog; a busy
+	// job queue must not get the unit restarted
+	startWatchdog(watchdogCtx, notifier, log, func(ctx context.Context) bool {
+		return apiClient.DockerReady(ctx) == nil
+	})
 
 	// Wait for controller to become ready
 	log.Info("Waiting for controller to become ready")
+	notifier.Status("Waiting for controller at %s", helperConfig.ControllerURL)
 	if err := apiClient.WaitForServerReady(ctx, 60*time.Second); err != nil {
 		return fmt.Errorf("controller not ready: %w", err)
 	}
 
 	// Wait for initial delay
 	log.Info("Waiting for startup delay", "delay", helperConfig.StartupDelay)
+	notifier.Status("Waiting %s before starting containers", helperConfig.StartupDelay)
 	time.Sleep(helperConfig.StartupDelay)
 
 	// Submit start job
 	log.Info("Submitting container start job")
+	notifier.Status("Submitting container start job")
 	startResp, err := apiClient.StartContainers(ctx, helperConfig.Timeout, nil)
 	if err != nil {
-		// Check if operations are blocked (503 error)
-		if isOperationBlocked(err) {
+		// Operations may be blocked on purpose, e.g. during a backup
+		if errors.Is(err, client.ErrBlocked) {
 			log.Info("Container start operation is currently blocked, skipping")
+			notifier.Status("Container start blocked, skipped")
 		} else {
 			return fmt.Errorf("failed to submit start job: %w", err)
 		}
 	} else {
 		log.Info("Start job submitted, waiting for completion",
 			"job_id", startResp.ID)
+		notifier.Status("Starting containers (job %s)", startResp.ID)
 
 		// Wait for job to complete
 		startJob, err := apiClient.WaitForJob(ctx, startResp.ID, helperConfig.PollInterval)
@@ -83,18 +107,28 @@ func runHelper(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to wait for start job: %w", err)
 		}
 
-		if startJob.Status == "failed" {
+		if startJob.Status == client.JobStatusCancelled {
+			log.Info("Start job was superseded before it ran",
+				"superseded_by", startJob.SupersededBy)
+			notifier.Status("Start job superseded by job %s", startJob.SupersededBy)
+		} else if startJob.Status == client.JobStatusFailed {
 			log.Error("Start job failed",
 				"error", startJob.Error,
 				"failed", startJob.Failed)
+			notifier.Status("Start job failed: %s", startJob.Error)
 		} else {
 			log.Info("Containers started successfully",
 				"started", startJob.Started,
 				"skipped", startJob.Skipped,
 				"failed", startJob.Failed)
+			notifier.Status("Started %d containers (%d skipped, %d failed)",
+				len(startJob.Started), len(startJob.Skipped), len(startJob.Failed))
 		}
 	}
 
+	// Boot start has finished, let dependent units proceed
+	notifier.Ready()
+
 	// Setup signal handler for graceful stop
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
@@ -104,12 +138,14 @@ func runHelper(cmd *cobra.Command, args []string) error {
 
 	log.Info("Shutdown signal received, stopping containers",
 		"signal", sig.String())
+	notifier.Stopping()
+	notifier.Status("Stopping containers")
 
 	// Submit stop job
 	stopResp, err := apiClient.StopContainers(ctx, helperConfig.Timeout, nil)
 	if err != nil {
-		// Check if operations are blocked (503 error)
-		if isOperationBlocked(err) {
+		// Operations may be blocked on purpose, e.g. during a backup
+		if errors.Is(err, client.ErrBlocked) {
 			log.Info("Container stop operation is currently blocked, skipping")
 		} else {
 			log.Error("Failed to submit stop job", "error", err)
@@ -118,6 +154,7 @@ func runHelper(cmd *cobra.Command, args []string) error {
 	} else {
 		log.Info("Stop job submitted, waiting for completion",
 			"job_id", stopResp.ID)
+		notifier.Status("Stopping containers (job %s)", stopResp.ID)
 
 		// Wait for stop job to complete
 		stopJob, err := apiClient.WaitForJob(ctx, stopResp.ID, helperConfig.PollInterval)
@@ -126,7 +163,10 @@ func runHelper(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
-		if stopJob.Status == "failed" {
+		if stopJob.Status == client.JobStatusCancelled {
+			log.Info("Stop job was superseded before it ran",
+				"superseded_by", stopJob.SupersededBy)
+		} else if stopJob.Status == client.JobStatusFailed {
 			log.Error("Stop job failed",
 				"error", stopJob.Error,
 				"failed", stopJob.Failed)
@@ -141,12 +181,3 @@ func runHelper(cmd *cobra.Command, args []string) error {
 	log.Info("Helper shutdown complete")
 	return nil
 }
-
-// isOperationBlocked checks if the error is due to operations being blocked (503)
-func isOperationBlocked(err error) bool {
-	if err == nil {
-		return false
-	}
-	// Check if error contains "503" status code
-	return strings.Contains(err.Error(), "status 503") || strings.Contains(err.Error(), "Operation blocked")
-}
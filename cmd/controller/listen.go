@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/saltyorg/sdc/internal/config"
+	"github.com/saltyorg/sdc/internal/listener"
+	"github.com/saltyorg/sdc/internal/systemd"
+	"github.com/saltyorg/sdc/pkg/logger"
+)
+
+// openListeners returns the sockets the API server should serve on.
+// Sockets passed by systemd socket activation take precedence; otherwise a
+// TCP listener (unless --port is 0) and a Unix socket (if --socket is set)
+// are opened.
+func openListeners(cfg config.ServerConfig, log *logger.Logger) ([]net.Listener, error) {
+	activated, err := systemd.Listeners()
+	if err != nil {
+		return nil, err
+	}
+	if len(activated) > 0 {
+		log.Info("Using systemd socket activation", "sockets", len(activated))
+		return activated, nil
+	}
+
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+
+	if cfg.Port > 0 {
+		addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	if cfg.Socket != "" {
+		mode, err := listener.ParseMode(cfg.SocketMode)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+
+		l, err := listener.ListenUnix(cfg.Socket, listener.UnixOptions{
+			Owner: cfg.SocketOwner,
+			Group: cfg.SocketGroup,
+			Mode:  mode,
+		})
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listeners configured: set --port or --socket")
+	}
+
+	return listeners, nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/saltyorg/sdc/internal/config"
+	"github.com/saltyorg/sdc/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +16,8 @@ var (
 	BuildTime = "unknown"
 )
 
+var logConfig config.LogConfig
+
 var rootCmd = &cobra.Command{
 	Use:   "saltbox-docker-controller",
 	Short: "Saltbox Docker Container Orchestrator",
@@ -24,11 +28,31 @@ Manages container startup/shutdown order based on dependency labels.`,
 
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().StringVar(&logConfig.Level, "log-level", "info", "Log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logConfig.Format, "log-format", "text", "Log format (text, json)")
+}
+
+// newLogger creates a logger from the --log-level and --log-format flags
+func newLogger() (*logger.Logger, error) {
+	level, err := logger.ParseLevel(logConfig.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := logger.ParseFormat(logConfig.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return logger.NewWithOptions(logger.Options{
+		Level:  level,
+		Format: format,
+	})
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
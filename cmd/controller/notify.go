@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/systemd"
+	"github.com/saltyorg/sdc/pkg/logger"
+)
+
+// dockerPingTimeout bounds each readiness/watchdog ping to the Docker daemon
+const dockerPingTimeout = 5 * time.Second
+
+// notifyWhenDockerReady waits until the Docker daemon answers a ping, then
+// reports READY=1 to systemd. It gives up silently when ctx is cancelled.
+func notifyWhenDockerReady(ctx context.Context, dockerClient *docker.Client, notifier *systemd.Notifier, log *logger.Logger) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if pingDocker(ctx, dockerClient) {
+			log.Info("Docker daemon reachable, controller ready")
+			notifier.Ready()
+			notifier.Status("Serving API")
+			return
+		}
+
+		log.Warn("Docker daemon not reachable yet, delaying readiness notification")
+		notifier.Status("Waiting for Docker daemon")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startWatchdog sends systemd watchdog pings while healthy reports true, if
+// WatchdogSec= is configured for the unit
+func startWatchdog(ctx context.Context, notifier *systemd.Notifier, log *logger.Logger, healthy func(context.Context) bool) {
+	interval, ok := systemd.WatchdogInterval()
+	if !ok || !notifier.Enabled() {
+		return
+	}
+
+	log.Info("systemd watchdog enabled", "timeout", interval)
+
+	// Ping at half the timeout, as recommended by sd_watchdog_enabled(3)
+	go notifier.RunWatchdog(ctx, interval/2, healthy)
+}
+
+// pingDocker reports whether the Docker daemon answers within dockerPingTimeout
+func pingDocker(ctx context.Context, dockerClient *docker.Client) bool {
+	pingCtx, cancel := context.WithTimeout(ctx, dockerPingTimeout)
+	defer cancel()
+	return dockerClient.Ping(pingCtx) == nil
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,13 +17,27 @@ import (
 	"github.com/saltyorg/sdc/internal/config"
 	"github.com/saltyorg/sdc/internal/docker"
 	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/internal/notify"
 	"github.com/saltyorg/sdc/internal/orchestrator"
-	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/saltyorg/sdc/internal/schedule"
+	"github.com/saltyorg/sdc/internal/systemd"
 	"github.com/spf13/cobra"
 )
 
 var serverConfig config.ServerConfig
 
+const (
+	// blockStateFile is the name of the block state file within the state directory
+	blockStateFile = "blocks.json"
+
+	// scheduleStateFile is the name of the file within the state directory
+	// holding schedules created through the API
+	scheduleStateFile = "schedules.json"
+
+	// fallbackStateDir is the state directory used outside systemd's StateDirectory=
+	fallbackStateDir = "/var/lib/sdc"
+)
+
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Run the Docker controller API server",
@@ -29,13 +47,30 @@ var serverCmd = &cobra.Command{
 
 func init() {
 	serverCmd.Flags().StringVar(&serverConfig.Host, "host", "127.0.0.1", "API server host")
-	serverCmd.Flags().IntVar(&serverConfig.Port, "port", 3377, "API server port")
+	serverCmd.Flags().IntVar(&serverConfig.Port, "port", 3377, "API server port (0 disables TCP)")
+	serverCmd.Flags().StringVar(&serverConfig.Socket, "socket", "", "Unix socket path to listen on (e.g. /run/sdc/sdc.sock)")
+	serverCmd.Flags().StringVar(&serverConfig.SocketOwner, "socket-owner", "", "Unix socket owner (user name or UID)")
+	serverCmd.Flags().StringVar(&serverConfig.SocketGroup, "socket-group", "", "Unix socket group (group name or GID)")
+	serverCmd.Flags().StringVar(&serverConfig.SocketMode, "socket-mode", "0660", "Unix socket permissions (octal)")
+	serverCmd.Flags().StringVar(&serverConfig.Token, "token", "", "Static API bearer token (grants all scopes)")
+	serverCmd.Flags().StringVar(&serverConfig.TokenFile, "token-file", "", "File with one \"<token> [read,write]\" entry per line")
+	serverCmd.Flags().StringVar(&serverConfig.TLSCert, "tls-cert", "", "Server certificate (enables HTTPS)")
+	serverCmd.Flags().StringVar(&serverConfig.TLSKey, "tls-key", "", "Server private key")
+	serverCmd.Flags().StringVar(&serverConfig.TLSClientCA, "tls-client-ca", "", "CA bundle for verifying client certificates (enables mTLS)")
+	serverCmd.Flags().StringVar(&serverConfig.ClientCertScopes, "client-cert-scopes", "read,write", "Scopes granted to verified client certificates")
+	serverCmd.Flags().StringVar(&serverConfig.StateDir, "state-dir", defaultStateDir(), "Directory for state kept across restarts, such as active blocks ($STATE_DIRECTORY if set, otherwise "+fallbackStateDir+"; empty keeps state in memory only)")
+	serverCmd.Flags().StringVar(&serverConfig.CyclePolicy, "cycle-policy", string(orchestrator.CyclePolicyFail), "On dependency cycles, fail the whole job (fail) or only the containers connected to a cycle (quarantine)")
+	serverCmd.Flags().IntVar(&serverConfig.MaxConcurrentOperations, "max-concurrent-operations", 0, "Maximum container starts and stops running at once across all jobs (0 = unlimited)")
+	serverCmd.Flags().StringToIntVar(&serverConfig.GroupConcurrency, "group-concurrency", nil, "Per-group concurrency limits overriding the group_concurrency labels (e.g. media=2,downloads=1)")
+	serverCmd.Flags().StringVar(&serverConfig.ScheduleFile, "schedule-file", "", "JSON file with scheduled start, stop and restart operations")
+	serverCmd.Flags().IntVar(&serverConfig.ScheduleHistory, "schedule-history", schedule.DefaultHistory, "Number of runs kept per schedule")
+	serverCmd.Flags().StringVar(&serverConfig.WebhookFile, "webhook-file", "", "JSON file with webhooks notified of job events")
 	rootCmd.AddCommand(serverCmd)
 }
 
 func runServer(cmd *cobra.Command, args []string) error {
 	// Initialize logger
-	log, err := logger.New(false)
+	log, err := newLogger()
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
@@ -47,6 +82,7 @@ func runServer(cmd *cobra.Command, args []string) error {
 		"build_time", BuildTime,
 		"host", serverConfig.Host,
 		"port", serverConfig.Port,
+		"socket", serverConfig.Socket,
 	)
 
 	// Initialize Docker client
@@ -58,32 +94,154 @@ func runServer(cmd *cobra.Command, args []string) error {
 	log.Info("Docker client initialized")
 
 	// Initialize orchestrator
+	cyclePolicy, err := orchestrator.ParseCyclePolicy(serverConfig.CyclePolicy)
+	if err != nil {
+		return err
+	}
 	orch := orchestrator.New(dockerClient, log)
-	log.Info("Orchestrator initialized")
+	orch.SetCyclePolicy(cyclePolicy)
+	if serverConfig.MaxConcurrentOperations < 0 {
+		return fmt.Errorf("invalid --max-concurrent-operations %d: must be 0 (unlimited) or more", serverConfig.MaxConcurrentOperations)
+	}
+	for group, limit := range serverConfig.GroupConcurrency {
+		if limit < 1 {
+			return fmt.Errorf("invalid --group-concurrency for group %q: limit must be at least 1", group)
+		}
+	}
+	orch.SetConcurrencyLimits(serverConfig.MaxConcurrentOperations, serverConfig.GroupConcurrency)
+	log.Info("Orchestrator initialized",
+		"cycle_policy", cyclePolicy,
+		"max_concurrent_operations", serverConfig.MaxConcurrentOperations)
 
 	// Initialize job manager with 3 workers
 	jobManager := jobs.NewManager(orch, log, 3)
 
+	// Deliver job events to the configured webhooks in the background
+	var webhooks []notify.Webhook
+	if serverConfig.WebhookFile != "" {
+		webhooks, err = loadWebhookFile(serverConfig.WebhookFile)
+		if err != nil {
+			return err
+		}
+	}
+	var webhookNotifier *notify.Notifier
+	if len(webhooks) > 0 {
+		webhookNotifier, err = notify.NewNotifier(webhooks, log)
+		if err != nil {
+			return fmt.Errorf("invalid webhook in %s: %w", serverConfig.WebhookFile, err)
+		}
+		webhookNotifier.SetUserAgent("sdc/" + Version)
+		webhookNotifier.Start()
+		jobManager.Subscribe(webhookNotifier.Notify)
+		log.Info("Webhooks enabled", "webhooks", len(webhooks))
+	}
+
 	// Initialize API server
 	apiServer := api.NewServer(jobManager, log)
+	apiServer.SetDockerPinger(dockerClient)
+	apiServer.SetGraphProvider(orch)
+	apiServer.SetInventoryProvider(orch)
+	apiServer.SetLintProvider(orch)
+
+	// Restore blocks from before a restart
+	if serverConfig.StateDir != "" {
+		stateFile := filepath.Join(serverConfig.StateDir, blockStateFile)
+		if err := apiServer.SetBlockStateFile(stateFile); err != nil {
+			return fmt.Errorf("failed to restore blocks: %w", err)
+		}
+		log.Info("Block state persisted", "path", stateFile)
+	} else {
+		log.Warn("State directory disabled, blocks and schedules created through the API are kept in memory only")
+	}
+
+	// Skip containers covered by blocks created through the API
+	orch.SetBlocker(apiServer.Blocks())
+
+	// Submit jobs for scheduled operations, skipping runs while blocked
+	scheduler := schedule.NewScheduler(jobManager, apiServer.Blocks(), log)
+	scheduler.SetHistory(serverConfig.ScheduleHistory)
+	if serverConfig.ScheduleFile != "" {
+		definitions, err := loadScheduleFile(serverConfig.ScheduleFile)
+		if err != nil {
+			return err
+		}
+		for _, def := range definitions {
+			if _, err := scheduler.Add(def); err != nil {
+				return fmt.Errorf("invalid schedule in %s: %w", serverConfig.ScheduleFile, err)
+			}
+		}
+	}
+	if serverConfig.StateDir != "" {
+		stateFile := filepath.Join(serverConfig.StateDir, scheduleStateFile)
+		if err := scheduler.Persist(stateFile); err != nil {
+			return fmt.Errorf("failed to restore schedules: %w", err)
+		}
+	}
+	apiServer.SetScheduleProvider(scheduler)
+	scheduler.Start()
+	log.Info("Scheduler started", "schedules", len(scheduler.List()))
+
+	auth, err := newAuthenticator(serverConfig)
+	if err != nil {
+		return fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	if auth != nil {
+		apiServer.SetAuthenticator(auth)
+		log.Info("API authentication enabled")
+	} else {
+		log.Warn("API authentication disabled, anyone who can reach the API can control containers")
+	}
+
+	tlsConfig, err := serverTLSConfig(serverConfig)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+
 	router := apiServer.Router()
 
+	// Open listeners (systemd-activated, TCP and/or Unix socket)
+	listeners, err := openListeners(serverConfig, log)
+	if err != nil {
+		return err
+	}
+
 	// Create HTTP server
-	addr := fmt.Sprintf("%s:%d", serverConfig.Host, serverConfig.Port)
 	srv := &http.Server{
-		Addr:         addr,
 		Handler:      router,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		TLSConfig:    tlsConfig,
 	}
 
-	// Start server in goroutine
-	serverErrors := make(chan error, 1)
-	go func() {
-		log.Info("API server listening", "addr", addr)
-		serverErrors <- srv.ListenAndServe()
-	}()
+	// Start serving each listener in its own goroutine
+	serverErrors := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			// TLS only applies to TCP; Unix sockets rely on filesystem permissions
+			useTLS := tlsConfig != nil && l.Addr().Network() == "tcp"
+			log.Info("API server listening",
+				"network", l.Addr().Network(),
+				"addr", l.Addr().String(),
+				"tls", useTLS)
+			if useTLS {
+				// Certificates are already loaded into TLSConfig
+				serverErrors <- srv.ServeTLS(l, "", "")
+			} else {
+				serverErrors <- srv.Serve(l)
+			}
+		}(l)
+	}
+
+	// Report readiness to systemd once Docker is reachable (no-op outside systemd)
+	notifier := systemd.NewNotifier()
+	notifyCtx, stopNotify := context.WithCancel(context.Background())
+	defer stopNotify()
+
+	go notifyWhenDockerReady(notifyCtx, dockerClient, notifier, log)
+	startWatchdog(notifyCtx, notifier, log, func(ctx context.Context) bool {
+		return pingDocker(ctx, dockerClient)
+	})
 
 	// Setup signal handling for graceful shutdown
 	shutdown := make(chan os.Signal, 1)
@@ -95,6 +253,8 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("server error: %w", err)
 	case sig := <-shutdown:
 		log.Info("Shutdown signal received", "signal", sig.String())
+		notifier.Stopping()
+		stopNotify()
 
 		// Give outstanding requests 30 seconds to complete
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -108,6 +268,9 @@ func runServer(cmd *cobra.Command, args []string) error {
 			log.Info("HTTP server stopped gracefully")
 		}
 
+		// Stop submitting scheduled jobs before the job manager goes away
+		scheduler.Stop()
+
 		// Shutdown job manager
 		if err := jobManager.Shutdown(10 * time.Second); err != nil {
 			log.Error("Job manager shutdown error", "error", err)
@@ -115,8 +278,67 @@ func runServer(cmd *cobra.Command, args []string) error {
 			log.Info("Job manager stopped gracefully")
 		}
 
+		// Deliver the events of the last jobs
+		if webhookNotifier != nil {
+			webhookNotifier.Stop(10 * time.Second)
+		}
+
 		log.Info("Server shutdown complete")
 	}
 
 	return nil
 }
+
+// defaultStateDir returns the directory systemd created through StateDirectory=,
+// or fallbackStateDir outside systemd
+func defaultStateDir() string {
+	if dir := os.Getenv("STATE_DIRECTORY"); dir != "" {
+		// Multiple directories are colon-separated; use the first
+		first, _, _ := strings.Cut(dir, ":")
+		return first
+	}
+	return fallbackStateDir
+}
+
+// loadWebhookFile reads the webhooks defined in a JSON file of the form
+// {"webhooks": [{"url": ..., "events": [...], "secret": ..., "template": ...}]}
+func loadWebhookFile(path string) ([]notify.Webhook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook file: %w", err)
+	}
+
+	var file struct {
+		Webhooks []notify.Webhook `json:"webhooks"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse webhook file %s: %w", path, err)
+	}
+	return file.Webhooks, nil
+}
+
+// loadScheduleFile reads the schedules defined in a JSON file of the form
+// {"schedules": [{"name": ..., "cron": ..., "operation": ..., "targets": [...]}]}
+func loadScheduleFile(path string) ([]schedule.Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule file: %w", err)
+	}
+
+	var file struct {
+		Schedules []api.CreateScheduleRequest `json:"schedules"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule file %s: %w", path, err)
+	}
+
+	definitions := make([]schedule.Definition, 0, len(file.Schedules))
+	for i, req := range file.Schedules {
+		def, err := req.Definition(schedule.SourceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %d in %s: %w", i+1, path, err)
+		}
+		definitions = append(definitions, def)
+	}
+	return definitions, nil
+}
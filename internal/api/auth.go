@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Scope represents a permission granted to an authenticated caller
+type Scope string
+
+const (
+	// ScopeRead allows read-only endpoints (/ping, /healthz, /readyz, /job_status, job logs, /blocks, /openapi.json)
+	ScopeRead Scope = "read"
+	// ScopeWrite allows mutating endpoints (/start, /stop, /blocks, /block, /unblock)
+	ScopeWrite Scope = "write"
+)
+
+// AllScopes is granted when no scopes are given explicitly
+var AllScopes = []Scope{ScopeRead, ScopeWrite}
+
+// ParseScopes parses a comma-separated list of scopes ("read,write")
+func ParseScopes(value string) ([]Scope, error) {
+	var scopes []Scope
+	for part := range strings.SplitSeq(value, ",") {
+		trimmed := strings.ToLower(strings.TrimSpace(part))
+		if trimmed == "" {
+			continue
+		}
+		scope := Scope(trimmed)
+		if scope != ScopeRead && scope != ScopeWrite {
+			return nil, fmt.Errorf("unknown scope: %s", trimmed)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return AllScopes, nil
+	}
+	return scopes, nil
+}
+
+// Authenticator verifies bearer tokens and TLS client certificates
+type Authenticator struct {
+	tokens     map[[sha256.Size]byte][]Scope // Keyed by token hash so lookups don't leak timing
+	certScopes []Scope                       // Scopes granted to verified client certificates (nil = certificates not accepted)
+}
+
+// NewAuthenticator creates an authenticator without any credentials
+func NewAuthenticator() *Authenticator {
+	return &Authenticator{
+		tokens: make(map[[sha256.Size]byte][]Scope),
+	}
+}
+
+// AddToken registers a bearer token with the given scopes (all scopes if empty)
+func (a *Authenticator) AddToken(token string, scopes ...Scope) {
+	if len(scopes) == 0 {
+		scopes = AllScopes
+	}
+	a.tokens[sha256.Sum256([]byte(token))] = scopes
+}
+
+// AllowClientCerts grants the given scopes to callers presenting a verified TLS client certificate
+func (a *Authenticator) AllowClientCerts(scopes ...Scope) {
+	if len(scopes) == 0 {
+		scopes = AllScopes
+	}
+	a.certScopes = scopes
+}
+
+// LoadTokenFile reads tokens from a file, see ParseTokens for the format
+func (a *Authenticator) LoadTokenFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer f.Close()
+
+	if err := a.ParseTokens(f); err != nil {
+		return fmt.Errorf("failed to parse token file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ParseTokens reads one token per line, optionally followed by a
+// comma-separated scope list ("s3cret read"). Blank lines and lines
+// starting with '#' are ignored.
+func (a *Authenticator) ParseTokens(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return fmt.Errorf("line %d: expected \"<token> [scopes]\"", lineNum)
+		}
+
+		scopes := AllScopes
+		if len(fields) == 2 {
+			parsed, err := ParseScopes(fields[1])
+			if err != nil {
+				return fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			scopes = parsed
+		}
+
+		a.AddToken(fields[0], scopes...)
+	}
+	return scanner.Err()
+}
+
+// Enabled reports whether any credentials are configured
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (len(a.tokens) > 0 || a.certScopes != nil)
+}
+
+// scopesFor returns the scopes granted to the request and whether it authenticated at all
+func (a *Authenticator) scopesFor(r *http.Request) ([]Scope, bool) {
+	var granted []Scope
+	authenticated := false
+
+	if a.certScopes != nil && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		granted = append(granted, a.certScopes...)
+		authenticated = true
+	}
+
+	if token, ok := bearerToken(r); ok {
+		scopes, exists := a.tokens[sha256.Sum256([]byte(token))]
+		if !exists {
+			return nil, false
+		}
+		granted = append(granted, scopes...)
+		authenticated = true
+	}
+
+	return granted, authenticated
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RequireScope rejects requests that are not authenticated with the given scope.
+// It is a no-op when no authenticator is configured.
+func (s *Server) RequireScope(scope Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.auth.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scopes, ok := s.auth.scopesFor(r)
+			if !ok {
+				s.logger.Warn("Unauthenticated request rejected",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="sdc"`)
+				s.writeError(w, http.StatusUnauthorized, ErrorCodeUnauthenticated, "Unauthorized")
+				return
+			}
+
+			if !slices.Contains(scopes, scope) {
+				s.logger.Warn("Request rejected for missing scope",
+					"method", r.Method,
+					"path", r.URL.Path,
+					"remote_addr", r.RemoteAddr,
+					"required_scope", string(scope))
+				s.writeError(w, http.StatusForbidden, ErrorCodePermissionDenied, "Forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
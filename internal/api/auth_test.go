@@ -0,0 +1,113 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthTestServer(t *testing.T, auth *Authenticator) http.Handler {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	server := NewServer(jobManager, log)
+	server.SetAuthenticator(auth)
+	return server.Router()
+}
+
+func doRequest(router http.Handler, method, path, token string, tlsState *tls.ConnectionState) int {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.TLS = tlsState
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAuth_Disabled(t *testing.T) {
+	router := newAuthTestServer(t, nil)
+
+	assert.Equal(t, http.StatusOK, doRequest(router, "GET", "/ping", "", nil))
+	assert.Equal(t, http.StatusOK, doRequest(router, "POST", "/unblock", "", nil))
+}
+
+func TestAuth_BearerTokenScopes(t *testing.T) {
+	auth := NewAuthenticator()
+	auth.AddToken("admin-token")
+	auth.AddToken("read-token", ScopeRead)
+	router := newAuthTestServer(t, auth)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"no token on read route", "GET", "/ping", "", http.StatusUnauthorized},
+		{"no token on write route", "POST", "/unblock", "", http.StatusUnauthorized},
+		{"wrong token", "GET", "/ping", "nope", http.StatusUnauthorized},
+		{"read token on read route", "GET", "/ping", "read-token", http.StatusOK},
+		{"read token on write route", "POST", "/unblock", "read-token", http.StatusForbidden},
+		{"admin token on write route", "POST", "/unblock", "admin-token", http.StatusOK},
+		{"admin token on read route", "GET", "/job_status/missing", "admin-token", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, doRequest(router, tt.method, tt.path, tt.token, nil))
+		})
+	}
+}
+
+func TestAuth_ClientCertificate(t *testing.T) {
+	auth := NewAuthenticator()
+	auth.AllowClientCerts(ScopeRead)
+	router := newAuthTestServer(t, auth)
+
+	verified := &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+	unverified := &tls.ConnectionState{}
+
+	assert.Equal(t, http.StatusOK, doRequest(router, "GET", "/ping", "", verified))
+	assert.Equal(t, http.StatusForbidden, doRequest(router, "POST", "/unblock", "", verified))
+	assert.Equal(t, http.StatusUnauthorized, doRequest(router, "GET", "/ping", "", unverified))
+}
+
+func TestAuthenticator_ParseTokens(t *testing.T) {
+	auth := NewAuthenticator()
+	err := auth.ParseTokens(strings.NewReader(`
+# helper token
+helper-token
+monitor-token read
+ops-token read,write
+`))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set("Authorization", "Bearer monitor-token")
+	scopes, ok := auth.scopesFor(req)
+	assert.True(t, ok)
+	assert.Equal(t, []Scope{ScopeRead}, scopes)
+
+	req.Header.Set("Authorization", "Bearer helper-token")
+	scopes, ok = auth.scopesFor(req)
+	assert.True(t, ok)
+	assert.Equal(t, AllScopes, scopes)
+
+	err = NewAuthenticator().ParseTokens(strings.NewReader("token admin"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/saltyorg/sdc/internal/blocks"
+)
+
+// CreateBlockRequest is the body of POST /blocks
+type CreateBlockRequest struct {
+	Scope      blocks.Scope `json:"scope,omitempty"`      // Defaults to "containers" if containers are given, "global" otherwise
+	Containers []string     `json:"containers,omitempty"` // Only with scope "containers"
+	Reason     string       `json:"reason,omitempty"`
+	Creator    string       `json:"creator,omitempty"`  // Defaults to the caller's User-Agent
+	Duration   string       `json:"duration,omitempty"` // Go duration such as "90m"; defaults to 10m
+}
+
+// BlocksResponse is returned by GET /blocks
+type BlocksResponse struct {
+	Blocks []blocks.Block `json:"blocks"`
+}
+
+// HandleListBlocks handles GET /blocks
+func (s *Server) HandleListBlocks(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, BlocksResponse{Blocks: s.blocks.List()})
+}
+
+// HandleCreateBlock handles POST /blocks
+func (s *Server) HandleCreateBlock(w http.ResponseWriter, r *http.Request) {
+	var req CreateBlockRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
+		return
+	}
+
+	if req.Scope == "" {
+		req.Scope = blocks.ScopeGlobal
+		if len(req.Containers) > 0 {
+			req.Scope = blocks.ScopeContainers
+		}
+	}
+
+	for _, name := range req.Containers {
+		if !containerNamePattern.MatchString(name) {
+			s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, "invalid container name in containers: "+strconv.Quote(name))
+			return
+		}
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		parsed, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, "invalid duration "+strconv.Quote(req.Duration))
+			return
+		}
+		duration = parsed
+	}
+
+	block, err := s.blocks.Add(blocks.Block{
+		Scope:      req.Scope,
+		Containers: req.Containers,
+		Reason:     req.Reason,
+		Creator:    blockCreator(r, req.Creator),
+	}, duration)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, block)
+}
+
+// HandleDeleteBlock handles DELETE /blocks/{block_id}
+func (s *Server) HandleDeleteBlock(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "block_id")
+
+	if !s.blocks.Remove(id) {
+		s.writeError(w, http.StatusNotFound, ErrorCodeNotFound, "Block not found: "+id)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, MessageResponse{Message: "Block " + id + " removed"})
+}
+
+// HandleBlock handles POST /block/{duration}.
+// It replaces any existing global block with a new one lasting duration minutes.
+func (s *Server) HandleBlock(w http.ResponseWriter, r *http.Request) {
+	// Parse duration from URL parameter (in minutes)
+	durationStr := chi.URLParam(r, "duration")
+	duration := 10 // Default 10 minutes
+	if durationStr != "" {
+		if parsedDuration, err := strconv.Atoi(durationStr); err == nil {
+			duration = parsedDuration
+		}
+	}
+
+	s.blocks.RemoveScope(blocks.ScopeGlobal)
+
+	_, err := s.blocks.Add(blocks.Block{
+		Scope:   blocks.ScopeGlobal,
+		Creator: blockCreator(r, ""),
+	}, time.Duration(duration)*time.Minute)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
+		return
+	}
+
+	s.logger.Info("Operations are now blocked", "duration_minutes", duration)
+	s.writeJSON(w, http.StatusOK, MessageResponse{
+		Message: "Operations are now blocked for " + strconv.Itoa(duration) + " minutes",
+	})
+}
+
+// HandleUnblock handles POST /unblock by removing all global blocks
+func (s *Server) HandleUnblock(w http.ResponseWriter, r *http.Request) {
+	s.blocks.RemoveScope(blocks.ScopeGlobal)
+
+	s.logger.Info("Operations are now unblocked")
+	s.writeJSON(w, http.StatusOK, MessageResponse{Message: "Operations are now unblocked"})
+}
+
+// blockCreator returns the creator recorded for a block: the given name,
+// else the caller's User-Agent, else its address
+func blockCreator(r *http.Request, given string) string {
+	if given != "" {
+		return given
+	}
+	if ua := r.UserAgent(); ua != "" {
+		return ua
+	}
+	return r.RemoteAddr
+}
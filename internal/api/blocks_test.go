@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/blocks"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocksEndpoints(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	server := NewServer(jobManager, log)
+	router := server.Router()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("User-Agent", "backup-script/1.0")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Block only plex while a backup runs
+	w := do("POST", "/blocks", `{"containers": ["plex"], "reason": "backup", "duration": "30m"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var block blocks.Block
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&block))
+	assert.NotEmpty(t, block.ID)
+	assert.Equal(t, blocks.ScopeContainers, block.Scope)
+	assert.Equal(t, []string{"plex"}, block.Containers)
+	assert.Equal(t, "backup", block.Reason)
+	assert.Equal(t, "backup-script/1.0", block.Creator)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), block.ExpiresAt, 5*time.Second)
+
+	// Container blocks don't reject whole requests
+	w = do("POST", "/start", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	blocked, ok := server.Blocks().Blocking(blocks.OperationStart, "plex")
+	require.True(t, ok)
+	assert.Equal(t, block.ID, blocked.ID)
+	_, ok = server.Blocks().Blocking(blocks.OperationStart, "sonarr")
+	assert.False(t, ok)
+
+	// A stop-only block rejects stop requests but not start requests
+	w = do("POST", "/blocks", `{"scope": "stop", "reason": "maintenance"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = do("POST", "/stop", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var errResp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, ErrorCodeBlocked, errResp.Code)
+	assert.Contains(t, errResp.Error, "maintenance")
+
+	// Malformed requests are rejected as such even while blocked
+	w = do("POST", "/stop?timeout=soon", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, ErrorCodeInvalidArgument, errResp.Code)
+
+	w = do("POST", "/start", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Both blocks are listed, oldest first
+	w = do("GET", "/blocks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list BlocksResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Blocks, 2)
+	assert.Equal(t, block.ID, list.Blocks[0].ID)
+	assert.Equal(t, blocks.ScopeStop, list.Blocks[1].Scope)
+
+	// Removing a block
+	w = do("DELETE", "/blocks/"+block.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = do("DELETE", "/blocks/"+block.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Len(t, server.Blocks().List(), 1)
+}
+
+func TestCreateBlock_Invalid(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	router := NewServer(jobManager, log).Router()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unknown scope", `{"scope": "sometimes"}`},
+		{"containers scope without containers", `{"scope": "containers"}`},
+		{"containers with global scope", `{"scope": "global", "containers": ["plex"]}`},
+		{"invalid container name", `{"containers": ["../plex"]}`},
+		{"invalid duration", `{"duration": "forever"}`},
+		{"negative duration", `{"duration": "-5m"}`},
+		{"duration too long", `{"duration": "9000h"}`},
+		{"unknown field", `{"until": "tomorrow"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/blocks", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response ErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, ErrorCodeInvalidArgument, response.Code)
+		})
+	}
+}
+
+func TestUnblock_KeepsScopedBlocks(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	server := NewServer(jobManager, log)
+	router := server.Router()
+
+	_, err := server.Blocks().Add(blocks.Block{Scope: blocks.ScopeContainers, Containers: []string{"plex"}}, time.Hour)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/block/5", "/block/10"} {
+		req := httptest.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// Re-blocking replaces the previous global block
+	assert.Len(t, server.Blocks().List(), 2)
+
+	req := httptest.NewRequest("POST", "/unblock", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	remaining := server.Blocks().List()
+	require.Len(t, remaining, 1)
+	assert.Equal(t, blocks.ScopeContainers, remaining[0].Scope)
+}
+
+func TestBlockSurvivesRestart(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	stateFile := filepath.Join(t.TempDir(), "blocks.json")
+
+	server := NewServer(jobManager, log)
+	require.NoError(t, server.SetBlockStateFile(stateFile))
+
+	req := httptest.NewRequest("POST", "/block/60", nil)
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	server.Blocks().Close()
+
+	// The controller restarts mid-maintenance
+	restarted := NewServer(jobManager, log)
+	require.NoError(t, restarted.SetBlockStateFile(stateFile))
+	defer restarted.Blocks().Close()
+
+	req = httptest.NewRequest("POST", "/start", nil)
+	w = httptest.NewRecorder()
+	restarted.Router().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
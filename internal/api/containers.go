@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/internal/orchestrator"
+)
+
+// States of dependencies that aren't labelled containers
+const (
+	StateMissing   = "missing"   // No such container exists
+	StateUnmanaged = "unmanaged" // The container exists without the saltbox_managed label
+)
+
+// InventoryProvider lists the labelled containers and their dependency graph
+type InventoryProvider interface {
+	Inventory(ctx context.Context) (*orchestrator.Inventory, error)
+}
+
+// ContainerLabels are the parsed Saltbox labels of a container
+type ContainerLabels struct {
+	Managed            bool     `json:"managed"`            // saltbox_managed
+	ControllerEnabled  bool     `json:"controller_enabled"` // saltbox_controller
+	DependsOn          []string `json:"depends_on"`
+	OptionalDependsOn  []string `json:"optional_depends_on,omitempty"`
+	StartupDelay       int      `json:"startup_delay"`
+	WaitForHealthcheck bool     `json:"wait_for_healthcheck"`
+	Priority           int      `json:"priority"`
+	Group              string   `json:"group,omitempty"`
+	GroupConcurrency   int      `json:"group_concurrency,omitempty"`
+	Groups             []string `json:"groups,omitempty"` // Selection groups
+
+	Hooks map[string]ContainerHook `json:"hooks,omitempty"` // Lifecycle hooks by phase
+}
+
+// ContainerHook is a lifecycle hook from a container's labels
+type ContainerHook struct {
+	Action    string `json:"action"`     // exec:<command> or [METHOD ]http(s)://host/path
+	Timeout   int    `json:"timeout"`    // Seconds
+	OnFailure string `json:"on_failure"` // continue or fail
+}
+
+// newContainerHooks converts parsed hook labels for the API
+func newContainerHooks(hooks map[string]docker.Hook) map[string]ContainerHook {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	result := make(map[string]ContainerHook, len(hooks))
+	for phase, hook := range hooks {
+		result[phase] = ContainerHook{Action: hook.Action, Timeout: hook.Timeout, OnFailure: string(hook.OnFailure)}
+	}
+	return result
+}
+
+// ContainerInfo describes a container as the controller sees it
+type ContainerInfo struct {
+	Name                string           `json:"name"`
+	ID                  string           `json:"id,omitempty"`
+	Image               string           `json:"image,omitempty"`
+	State               string           `json:"state"`            // Docker state, "missing" or "unmanaged"
+	Status              string           `json:"status,omitempty"` // Docker's human-readable status
+	Health              string           `json:"health,omitempty"` // starting, healthy or unhealthy; empty without a healthcheck
+	Running             bool             `json:"running"`
+	Managed             bool             `json:"managed"`
+	UnmanagedReason     string           `json:"unmanaged_reason,omitempty"`
+	Placeholder         bool             `json:"placeholder,omitempty"` // Treated as a missing dependency
+	Labels              *ContainerLabels `json:"labels,omitempty"`      // Omitted for missing dependencies
+	Parents             []string         `json:"parents"`               // Containers this one depends on
+	Children            []string         `json:"children"`              // Containers that depend on this one
+	MissingDependencies []string         `json:"missing_dependencies,omitempty"`
+	Depth               *int             `json:"depth,omitempty"` // Startup batch index; omitted if unmanaged or in a cycle
+}
+
+// ContainersResponse is returned by GET /containers
+type ContainersResponse struct {
+	Containers []ContainerInfo `json:"containers"`       // Sorted by name
+	Cycles     [][]string      `json:"cycles,omitempty"` // Every dependency cycle, as a path
+}
+
+// SetInventoryProvider enables the container inventory endpoint
+func (s *Server) SetInventoryProvider(provider InventoryProvider) {
+	s.inventory = provider
+}
+
+// HandleListContainers handles GET /containers?state=&name=&managed=
+func (s *Server) HandleListContainers(w http.ResponseWriter, r *http.Request) {
+	if s.inventory == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Container inventory is not available")
+		return
+	}
+
+	query := r.URL.Query()
+	state := query.Get("state")
+	name := strings.ToLower(query.Get("name"))
+
+	var managed *bool
+	if managedStr := query.Get("managed"); managedStr != "" {
+		value, err := strconv.ParseBool(managedStr)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, fmt.Sprintf("invalid managed %q: must be true or false", managedStr))
+			return
+		}
+		managed = &value
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), GraphTimeout)
+	defer cancel()
+
+	inventory, err := s.inventory.Inventory(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list containers", "error", err)
+		s.writeError(w, http.StatusInternalServerError, ErrorCodeInternal, "Failed to list containers: "+err.Error())
+		return
+	}
+
+	resp := newContainersResponse(inventory)
+	resp.Containers = slices.DeleteFunc(resp.Containers, func(info ContainerInfo) bool {
+		return (state != "" && info.State != state) ||
+			(name != "" && !strings.Contains(strings.ToLower(info.Name), name)) ||
+			(managed != nil && info.Managed != *managed)
+	})
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// newContainersResponse joins the labelled containers with their graph nodes.
+// Dependencies that aren't managed containers appear as placeholders.
+func newContainersResponse(inventory *orchestrator.Inventory) ContainersResponse {
+	resp := ContainersResponse{Containers: make([]ContainerInfo, 0, len(inventory.Containers))}
+	byName := make(map[string]int)
+
+	for _, summary := range inventory.Containers {
+		info := newContainerInfo(summary)
+		byName[info.Name] = len(resp.Containers)
+		resp.Containers = append(resp.Containers, info)
+	}
+
+	resp.Cycles = cyclePaths(inventory.Graph)
+
+	// Without batches (the graph has cycles) no container has a depth
+	depths := make(map[string]int)
+	if batches, err := inventory.Graph.GetStartupBatches(); err == nil {
+		for depth, batch := range batches {
+			for _, node := range batch {
+				depths[node.Name] = depth
+			}
+		}
+	}
+
+	unmanaged := make(map[string]bool)
+	for _, node := range inventory.Graph.Nodes {
+		for _, name := range node.UnmanagedDependencies {
+			unmanaged[name] = true
+		}
+	}
+
+	for _, node := range inventory.Graph.Nodes {
+		i, exists := byName[node.Name]
+		if !exists {
+			// Referenced as a dependency, but no such container is labelled
+			state := StateMissing
+			if unmanaged[node.Name] {
+				state = StateUnmanaged
+			}
+			i = len(resp.Containers)
+			resp.Containers = append(resp.Containers, ContainerInfo{Name: node.Name, State: state})
+		}
+
+		info := &resp.Containers[i]
+		info.Placeholder = node.IsPlaceholder
+		info.Parents = sortedNames(node.Parents)
+		info.Children = sortedNames(node.Children)
+		for _, parent := range node.Parents {
+			if parent.IsPlaceholder {
+				info.MissingDependencies = append(info.MissingDependencies, parent.Name)
+			}
+		}
+		slices.Sort(info.MissingDependencies)
+		if depth, ok := depths[node.Name]; ok {
+			info.Depth = &depth
+		}
+	}
+
+	slices.SortFunc(resp.Containers, func(a, b ContainerInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return resp
+}
+
+// newContainerInfo describes a labelled container before its graph node is known
+func newContainerInfo(summary container.Summary) ContainerInfo {
+	labels := docker.ParseLabels(summary.Labels)
+
+	info := ContainerInfo{
+		Name:            graph.NewNode(summary).Name,
+		ID:              summary.ID,
+		Image:           summary.Image,
+		State:           string(summary.State),
+		Status:          summary.Status,
+		Running:         summary.State == container.StateRunning,
+		Managed:         labels.IsManaged(),
+		UnmanagedReason: labels.UnmanagedReason(),
+		Labels: &ContainerLabels{
+			Managed:            labels.Managed,
+			ControllerEnabled:  labels.ControllerEnabled,
+			DependsOn:          labels.GetDependencies(),
+			OptionalDependsOn:  labels.GetOptionalDependencies(),
+			StartupDelay:       labels.GetStartupDelay(),
+			WaitForHealthcheck: labels.ShouldWaitForHealthcheck(),
+			Priority:           labels.GetPriority(),
+			Group:              labels.Group,
+			GroupConcurrency:   labels.GroupConcurrency,
+			Groups:             labels.Groups,
+			Hooks:              newContainerHooks(labels.Hooks),
+		},
+		Parents:  []string{},
+		Children: []string{},
+	}
+	if summary.Health != nil && summary.Health.Status != container.NoHealthcheck {
+		info.Health = string(summary.Health.Status)
+	}
+	return info
+}
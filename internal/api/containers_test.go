@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/internal/orchestrator"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noInspect fails every inspect, leaving stop timeouts at their default. Only
+// the containers in existing exist beyond the listed ones.
+type noInspect struct {
+	existing []string
+}
+
+func (noInspect) GetContainer(ctx context.Context, containerID string) (*client.ContainerInspectResult, error) {
+	return nil, errors.New("not available")
+}
+
+func (n noInspect) ContainerExists(ctx context.Context, containerNameOrID string) (bool, error) {
+	return slices.Contains(n.existing, containerNameOrID), nil
+}
+
+type fakeInventoryProvider struct {
+	containers []container.Summary
+	unlisted   []string // Containers without the saltbox_managed label
+}
+
+func (f *fakeInventoryProvider) Inventory(ctx context.Context) (*orchestrator.Inventory, error) {
+	log, _ := logger.New(false)
+	g, err := graph.NewBuilder(noInspect{existing: f.unlisted}, log).Build(ctx, f.containers)
+	if err != nil {
+		return nil, err
+	}
+	return &orchestrator.Inventory{Containers: f.containers, Graph: g}, nil
+}
+
+func testSummary(name string, state container.ContainerState, labels map[string]string) container.Summary {
+	labels["com.github.saltbox.saltbox_managed"] = "true"
+	return container.Summary{ID: name + "-id", Names: []string{"/" + name}, State: state, Labels: labels}
+}
+
+func listContainers(t *testing.T, provider InventoryProvider, query string) (int, ContainersResponse) {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	server := NewServer(jobManager, log)
+	server.SetInventoryProvider(provider)
+
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, httptest.NewRequest("GET", "/containers"+query, nil))
+
+	var resp ContainersResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	}
+	return w.Code, resp
+}
+
+func testInventory() *fakeInventoryProvider {
+	postgres := testSummary("postgres", container.StateRunning, map[string]string{})
+	postgres.Health = &container.HealthSummary{Status: container.Healthy}
+
+	return &fakeInventoryProvider{containers: []container.Summary{
+		postgres,
+		testSummary("app", container.StateExited, map[string]string{
+			"com.github.saltbox.depends_on":       "postgres,redis",
+			"com.github.saltbox.depends_on.delay": "5",
+		}),
+		testSummary("redis", container.StateRunning, map[string]string{
+			"com.github.saltbox.saltbox_controller": "false",
+		}),
+	}}
+}
+
+func TestListContainers(t *testing.T) {
+	status, resp := listContainers(t, testInventory(), "")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, resp.Containers, 3)
+
+	app, postgres, redis := resp.Containers[0], resp.Containers[1], resp.Containers[2]
+
+	assert.Equal(t, "app", app.Name)
+	assert.True(t, app.Managed)
+	assert.Equal(t, "exited", app.State)
+	assert.Equal(t, []string{"postgres", "redis"}, app.Parents)
+	assert.Equal(t, []string{"redis"}, app.MissingDependencies)
+	assert.Equal(t, 5, app.Labels.StartupDelay)
+	require.NotNil(t, app.Depth)
+	assert.Equal(t, 1, *app.Depth)
+
+	assert.Equal(t, "healthy", postgres.Health)
+	assert.Equal(t, []string{"app"}, postgres.Children)
+	require.NotNil(t, postgres.Depth)
+	assert.Equal(t, 0, *postgres.Depth)
+
+	// Opted out, so app's dependency on it is treated as missing
+	assert.Equal(t, "redis", redis.Name)
+	assert.False(t, redis.Managed)
+	assert.Equal(t, "saltbox_controller label is false", redis.UnmanagedReason)
+	assert.True(t, redis.Placeholder)
+	assert.True(t, redis.Running)
+	assert.Equal(t, []string{"app"}, redis.Children)
+}
+
+func TestListContainers_MissingDependency(t *testing.T) {
+	provider := &fakeInventoryProvider{containers: []container.Summary{
+		testSummary("app", container.StateExited, map[string]string{"com.github.saltbox.depends_on": "db"}),
+	}}
+
+	status, resp := listContainers(t, provider, "?state=missing")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, resp.Containers, 1)
+	assert.Equal(t, "db", resp.Containers[0].Name)
+	assert.True(t, resp.Containers[0].Placeholder)
+	assert.Nil(t, resp.Containers[0].Labels)
+	assert.Equal(t, []string{"app"}, resp.Containers[0].Children)
+}
+
+func TestListContainers_UnmanagedDependency(t *testing.T) {
+	provider := &fakeInventoryProvider{
+		containers: []container.Summary{
+			testSummary("app", container.StateExited, map[string]string{"com.github.saltbox.depends_on": "db,nfs"}),
+		},
+		unlisted: []string{"nfs"},
+	}
+
+	status, resp := listContainers(t, provider, "")
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, resp.Containers, 3)
+	assert.Equal(t, StateMissing, resp.Containers[1].State)
+	assert.Equal(t, "nfs", resp.Containers[2].Name)
+	assert.Equal(t, StateUnmanaged, resp.Containers[2].State)
+	assert.True(t, resp.Containers[2].Placeholder)
+}
+
+func TestListContainers_Filters(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"?state=running", []string{"postgres", "redis"}},
+		{"?name=GRES", []string{"postgres"}},
+		{"?managed=false", []string{"redis"}},
+		{"?managed=true&state=running", []string{"postgres"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			status, resp := listContainers(t, testInventory(), tt.query)
+			require.Equal(t, http.StatusOK, status)
+
+			var names []string
+			for _, info := range resp.Containers {
+				names = append(names, info.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestListContainers_InvalidManaged(t *testing.T) {
+	status, _ := listContainers(t, testInventory(), "?managed=maybe")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
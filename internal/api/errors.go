@@ -0,0 +1,52 @@
+package api
+
+import "net/http"
+
+// ErrorCode is a machine-readable error identifier returned in every error response
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidArgument  ErrorCode = "invalid_argument"  // Malformed or out-of-range request parameters
+	ErrorCodeNotFound         ErrorCode = "not_found"         // Unknown job or route
+	ErrorCodeBlocked          ErrorCode = "blocked"           // Start/stop operations are blocked
+	ErrorCodeUnauthenticated  ErrorCode = "unauthenticated"   // Missing or unknown credentials
+	ErrorCodePermissionDenied ErrorCode = "permission_denied" // Credentials lack the required scope
+	ErrorCodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	ErrorCodeConflict         ErrorCode = "conflict"    // Idempotency-Key reused with different parameters, or a schedule conflict
+	ErrorCodeUnavailable      ErrorCode = "unavailable" // Job queue full or shutting down
+	ErrorCodeInternal         ErrorCode = "internal"
+)
+
+// ErrorCodes lists all error codes, in the order they are documented
+var ErrorCodes = []ErrorCode{
+	ErrorCodeInvalidArgument,
+	ErrorCodeNotFound,
+	ErrorCodeBlocked,
+	ErrorCodeUnauthenticated,
+	ErrorCodePermissionDenied,
+	ErrorCodeMethodNotAllowed,
+	ErrorCodeConflict,
+	ErrorCodeUnavailable,
+	ErrorCodeInternal,
+}
+
+// ErrorResponse is the envelope of every error response
+type ErrorResponse struct {
+	Code  ErrorCode `json:"code"`
+	Error string    `json:"error"` // Human-readable description
+}
+
+// writeError writes an error JSON response
+func (s *Server) writeError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	s.writeJSON(w, status, ErrorResponse{Code: code, Error: message})
+}
+
+// handleNotFound answers requests for unknown routes
+func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	s.writeError(w, http.StatusNotFound, ErrorCodeNotFound, "No route for "+r.URL.Path)
+}
+
+// handleMethodNotAllowed answers requests using the wrong method for a known route
+func (s *Server) handleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	s.writeError(w, http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed, "Method "+r.Method+" not allowed for "+r.URL.Path)
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+// GraphTimeout bounds the Docker queries needed to build the dependency graph
+const GraphTimeout = 30 * time.Second
+
+// GraphProvider builds the current container dependency graph
+type GraphProvider interface {
+	Graph(ctx context.Context) (*graph.Graph, error)
+}
+
+// GraphNode is a container in the dependency graph
+type GraphNode struct {
+	Name               string              `json:"name"`
+	Running            bool                `json:"running"`
+	Placeholder        bool                `json:"placeholder,omitempty"`      // Referenced as a dependency but doesn't exist
+	Parents            []string            `json:"parents"`                    // Containers this one depends on
+	OptionalParents    []string            `json:"optional_parents,omitempty"` // Parents that are optional dependencies
+	Patterns           map[string][]string `json:"patterns,omitempty"`         // What each dependency pattern expanded to
+	Children           []string            `json:"children"`                   // Containers that depend on this one
+	StartupDelay       int                 `json:"startup_delay,omitempty"`
+	WaitForHealthcheck bool                `json:"wait_for_healthcheck,omitempty"`
+	Priority           int                 `json:"priority,omitempty"`
+	Group              string              `json:"group,omitempty"`
+	GroupConcurrency   int                 `json:"group_concurrency,omitempty"`
+	Groups             []string            `json:"groups,omitempty"` // Selection groups
+}
+
+// GraphResponse is returned by GET /graph
+type GraphResponse struct {
+	Nodes   []GraphNode `json:"nodes"`             // Sorted by name
+	Batches [][]string  `json:"batches,omitempty"` // Startup batches in scheduling order; omitted if the graph has cycles
+	Cycles  [][]string  `json:"cycles,omitempty"`  // Every dependency cycle, as a path
+}
+
+// SetGraphProvider enables the dependency graph endpoint
+func (s *Server) SetGraphProvider(provider GraphProvider) {
+	s.graph = provider
+}
+
+// HandleGetGraph handles GET /graph
+func (s *Server) HandleGetGraph(w http.ResponseWriter, r *http.Request) {
+	if s.graph == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Dependency graph is not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), GraphTimeout)
+	defer cancel()
+
+	g, err := s.graph.Graph(ctx)
+	if err != nil {
+		s.logger.Error("Failed to build dependency graph", "error", err)
+		s.writeError(w, http.StatusInternalServerError, ErrorCodeInternal, "Failed to build dependency graph: "+err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, newGraphResponse(g))
+}
+
+// newGraphResponse converts a graph into its API representation
+func newGraphResponse(g *graph.Graph) GraphResponse {
+	resp := GraphResponse{Nodes: make([]GraphNode, 0, len(g.Nodes))}
+
+	for _, node := range g.Nodes {
+		resp.Nodes = append(resp.Nodes, GraphNode{
+			Name:               node.Name,
+			Running:            node.IsRunning,
+			Placeholder:        node.IsPlaceholder,
+			Parents:            sortedNames(node.Parents),
+			OptionalParents:    slices.Sorted(maps.Keys(node.OptionalParents)),
+			Patterns:           node.DependencyPatterns,
+			Children:           sortedNames(node.Children),
+			StartupDelay:       node.StartupDelay,
+			WaitForHealthcheck: node.WaitForHealthcheck,
+			Priority:           node.Priority,
+			Group:              node.Group,
+			GroupConcurrency:   node.GroupConcurrency,
+			Groups:             node.Groups,
+		})
+	}
+	slices.SortFunc(resp.Nodes, func(a, b GraphNode) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if resp.Cycles = cyclePaths(g); len(resp.Cycles) > 0 {
+		return resp
+	}
+
+	batches, err := g.GetStartupBatches()
+	if err != nil {
+		return resp
+	}
+	for _, batch := range batches {
+		resp.Batches = append(resp.Batches, graph.GetNodeNames(batch))
+	}
+	return resp
+}
+
+// cyclePaths returns the path of every dependency cycle in g
+func cyclePaths(g *graph.Graph) [][]string {
+	var paths [][]string
+	for _, cycle := range g.Cycles() {
+		paths = append(paths, cycle.Path)
+	}
+	return paths
+}
+
+// sortedNames returns the names of nodes in alphabetical order
+func sortedNames(nodes []*graph.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGraphProvider struct {
+	graph *graph.Graph
+	err   error
+}
+
+func (f *fakeGraphProvider) Graph(ctx context.Context) (*graph.Graph, error) {
+	return f.graph, f.err
+}
+
+func testNode(name string, running bool) *graph.Node {
+	state := "exited"
+	if running {
+		state = "running"
+	}
+	return graph.NewNode(container.Summary{ID: name + "-id", Names: []string{"/" + name}, State: state})
+}
+
+func getGraph(t *testing.T, provider GraphProvider) (int, GraphResponse) {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	server := NewServer(jobManager, log)
+	server.SetGraphProvider(provider)
+
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, httptest.NewRequest("GET", "/graph", nil))
+
+	var resp GraphResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	}
+	return w.Code, resp
+}
+
+func TestGetGraph(t *testing.T) {
+	postgres := testNode("postgres", true)
+	app := testNode("app", false)
+	worker := testNode("worker", false)
+	redis := graph.NewPlaceholderNode("redis")
+	app.AddParent(postgres)
+	worker.AddParent(app)
+	worker.AddParent(redis)
+
+	g := &graph.Graph{Nodes: map[string]*graph.Node{
+		"postgres": postgres, "app": app, "worker": worker, "redis": redis,
+	}}
+
+	status, resp := getGraph(t, &fakeGraphProvider{graph: g})
+	require.Equal(t, http.StatusOK, status)
+
+	require.Len(t, resp.Nodes, 4)
+	assert.Equal(t, "app", resp.Nodes[0].Name)
+	assert.Equal(t, []string{"postgres"}, resp.Nodes[0].Parents)
+	assert.Equal(t, []string{"worker"}, resp.Nodes[0].Children)
+	assert.True(t, resp.Nodes[1].Running, "postgres is running")
+	assert.True(t, resp.Nodes[2].Placeholder, "redis is a placeholder")
+	assert.Equal(t, []string{"app", "redis"}, resp.Nodes[3].Parents)
+
+	assert.Empty(t, resp.Cycles)
+	require.NotEmpty(t, resp.Batches)
+	assert.Contains(t, resp.Batches[0], "postgres")
+	assert.Contains(t, resp.Batches[len(resp.Batches)-1], "worker")
+}
+
+func TestGetGraph_Cycle(t *testing.T) {
+	a := testNode("a", false)
+	b := testNode("b", false)
+	a.AddParent(b)
+	b.AddParent(a)
+
+	status, resp := getGraph(t, &fakeGraphProvider{graph: &graph.Graph{Nodes: map[string]*graph.Node{"a": a, "b": b}}})
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, [][]string{{"a", "b", "a"}}, resp.Cycles)
+	assert.Empty(t, resp.Batches)
+}
+
+func TestGetGraph_ProviderError(t *testing.T) {
+	status, _ := getGraph(t, &fakeGraphProvider{err: errors.New("docker unreachable")})
+	assert.Equal(t, http.StatusInternalServerError, status)
+}
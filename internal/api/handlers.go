@@ -1,38 +1,60 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
-	"strings"
-	"sync"
-	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/saltyorg/sdc/internal/blocks"
 	"github.com/saltyorg/sdc/internal/jobs"
 	"github.com/saltyorg/sdc/pkg/logger"
 )
 
 // Server represents the API server
 type Server struct {
-	jobManager      *jobs.Manager
-	logger          *logger.Logger
-	isBlocked       bool
-	blockMutex      sync.RWMutex
-	unblockTimer    *time.Timer
-	unblockCancel   context.CancelFunc
+	jobManager  *jobs.Manager
+	logger      *logger.Logger
+	blocks      *blocks.Registry
+	idempotency *idempotencyStore
+	auth        *Authenticator
+	docker      DockerPinger
+	graph       GraphProvider
+	inventory   InventoryProvider
+	lint        LintProvider
+	schedules   ScheduleProvider
 }
 
 // NewServer creates a new API server
 func NewServer(jobManager *jobs.Manager, logger *logger.Logger) *Server {
 	return &Server{
-		jobManager: jobManager,
-		logger:     logger,
+		jobManager:  jobManager,
+		logger:      logger,
+		blocks:      blocks.NewRegistry(logger),
+		idempotency: newIdempotencyStore(),
 	}
 }
 
-// Router creates and configures the HTTP router
+// SetBlockStateFile restores blocks saved in path and keeps the file up to
+// date, so blocks survive controller restarts
+func (s *Server) SetBlockStateFile(path string) error {
+	return s.blocks.Persist(path)
+}
+
+// Blocks returns the registry of active blocks, for the orchestrator to
+// skip blocked containers
+func (s *Server) Blocks() *blocks.Registry {
+	return s.blocks
+}
+
+// SetAuthenticator enables authentication for all API routes.
+// Passing nil (the default) leaves the API open.
+func (s *Server) SetAuthenticator(auth *Authenticator) {
+	s.auth = auth
+}
+
+// Router creates and configures the HTTP router.
+// Routes are served under APIPrefix and, for existing clients, unversioned.
 func (s *Server) Router() http.Handler {
 	r := chi.NewRouter()
 
@@ -40,117 +62,111 @@ func (s *Server) Router() http.Handler {
 	r.Use(s.RecoveryMiddleware)
 	r.Use(s.LoggingMiddleware)
 
-	// Main API routes (spec-compliant)
-	r.Post("/start", s.HandleStartContainers)
-	r.Post("/stop", s.HandleStopContainers)
-	r.Get("/ping", s.HandleHealth)
-
-	// Block/unblock routes
-	r.Post("/block/{duration}", s.HandleBlock)
-	r.Post("/unblock", s.HandleUnblock)
+	r.NotFound(s.handleNotFound)
+	r.MethodNotAllowed(s.handleMethodNotAllowed)
 
-	// Job status route
-	r.Get("/job_status/{job_id}", s.HandleGetJobStatus)
+	r.Route(APIPrefix, s.mountRoutes)
+	s.mountRoutes(r)
 
 	return r
 }
 
+// mountRoutes registers every API route on r, guarded by its scope.
+// Mutating routes honour the Idempotency-Key header.
+func (s *Server) mountRoutes(r chi.Router) {
+	for _, rt := range s.routes() {
+		var handler http.Handler = rt.handler
+		if rt.mutating() {
+			handler = s.IdempotencyMiddleware(handler)
+		}
+		r.With(s.RequireScope(rt.scope)).Method(rt.method, rt.path, handler)
+	}
+}
+
 // JobResponse represents a job response
 type JobResponse struct {
-	JobID string `json:"job_id"`
+	JobID     string `json:"job_id"`
+	Coalesced bool   `json:"coalesced,omitempty"` // An identical pending job was reused
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error string `json:"error"`
+// JobLogsResponse represents the captured log lines of a job
+type JobLogsResponse struct {
+	JobID   string         `json:"job_id"`
+	Entries []logger.Entry `json:"entries"`
+	Dropped int            `json:"dropped"` // Oldest lines discarded once the buffer filled up
 }
 
-// HandleStartContainers handles POST /start
-func (s *Server) HandleStartContainers(w http.ResponseWriter, r *http.Request) {
-	// Check if operations are blocked
-	s.blockMutex.RLock()
-	blocked := s.isBlocked
-	s.blockMutex.RUnlock()
-
-	if blocked {
-		s.writeError(w, http.StatusServiceUnavailable, "Operation blocked")
-		return
-	}
-
-	// Parse query parameters
-	timeout := 600 // 10 minutes default
-	if timeoutStr := r.URL.Query().Get("timeout"); timeoutStr != "" {
-		if parsedTimeout, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = parsedTimeout
-		}
-	}
-
-	// Create and submit job
-	job := jobs.NewJob(jobs.JobTypeStart, timeout, nil)
-	if err := s.jobManager.Submit(job); err != nil {
-		s.logger.Error("Failed to submit job", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "Failed to submit job")
-		return
-	}
+// StatusResponse is returned by the health endpoints
+type StatusResponse struct {
+	Status string `json:"status"`
+}
 
-	s.logger.Info("Start job created",
-		"job_id", job.ID,
-		"timeout", timeout)
+// MessageResponse is returned by operations without a resource to report
+type MessageResponse struct {
+	Message string `json:"message"`
+}
 
-	s.writeJSON(w, http.StatusOK, JobResponse{
-		JobID: job.ID,
-	})
+// HandleStartContainers handles POST /start
+func (s *Server) HandleStartContainers(w http.ResponseWriter, r *http.Request) {
+	s.handleJobRequest(w, r, jobs.JobTypeStart, blocks.OperationStart, DefaultStartTimeout)
 }
 
 // HandleStopContainers handles POST /stop
 func (s *Server) HandleStopContainers(w http.ResponseWriter, r *http.Request) {
-	// Check if operations are blocked
-	s.blockMutex.RLock()
-	blocked := s.isBlocked
-	s.blockMutex.RUnlock()
+	s.handleJobRequest(w, r, jobs.JobTypeStop, blocks.OperationStop, DefaultStopTimeout)
+}
 
-	if blocked {
-		s.writeError(w, http.StatusServiceUnavailable, "Operation blocked")
+// handleJobRequest validates a start/stop request and submits the job.
+// Requests are rejected only when a block covers the whole operation;
+// container-scoped blocks are applied by the orchestrator as skips.
+func (s *Server) handleJobRequest(w http.ResponseWriter, r *http.Request, jobType jobs.JobType, op blocks.Operation, defaultTimeout int) {
+	// Validate first, so a malformed request is rejected even while blocked
+	req, err := parseJobRequest(r, defaultTimeout)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
 		return
 	}
 
-	// Parse timeout query parameter
-	timeout := 300 // 5 minutes default
-	if timeoutStr := r.URL.Query().Get("timeout"); timeoutStr != "" {
-		if parsedTimeout, err := strconv.Atoi(timeoutStr); err == nil {
-			timeout = parsedTimeout
+	if block, blocked := s.blocks.BlockingOperation(op); blocked {
+		message := "Operation blocked"
+		if block.Reason != "" {
+			message += ": " + block.Reason
 		}
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeBlocked, message+" (block "+block.ID+")")
+		return
 	}
 
-	// Parse ignore query parameter (supports both comma-separated and repeated params)
-	var ignore []string
-	query := r.URL.Query()
-
-	// Handle repeated params: ?ignore=traefik&ignore=nginx
-	if ignoreParams := query["ignore"]; len(ignoreParams) > 0 {
-		for _, param := range ignoreParams {
-			// Also support comma-separated within each param: ?ignore=traefik,nginx
-			parts := strings.SplitSeq(param, ",")
-			for part := range parts {
-				if trimmed := strings.TrimSpace(part); trimmed != "" {
-					ignore = append(ignore, trimmed)
-				}
-			}
+	// Create and submit job
+	job := jobs.NewJob(jobType, *req.Timeout, req.Ignore)
+	job.Targets = req.Targets
+	job.IncludeDependencies = req.Options.IncludeDependencies()
+	job.Supersede = req.Options.Supersede
+
+	jobID, err := s.jobManager.Submit(job)
+	if err != nil {
+		s.logger.Error("Failed to submit job", "error", err)
+		if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrShuttingDown) {
+			s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Failed to submit job: "+err.Error())
+			return
 		}
+		s.writeError(w, http.StatusInternalServerError, ErrorCodeInternal, "Failed to submit job")
+		return
 	}
 
-	// Create and submit job
-	job := jobs.NewJob(jobs.JobTypeStop, timeout, ignore)
-	if err := s.jobManager.Submit(job); err != nil {
-		s.logger.Error("Failed to submit job", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "Failed to submit job")
+	if jobID != job.ID {
+		s.writeJSON(w, http.StatusOK, JobResponse{
+			JobID:     jobID,
+			Coalesced: true,
+		})
 		return
 	}
 
-	s.logger.Info("Stop job created",
+	s.logger.Info("Job created",
 		"job_id", job.ID,
-		"timeout", timeout,
-		"ignore", ignore)
+		"type", string(jobType),
+		"timeout", job.Timeout,
+		"ignore", job.Ignore,
+		"targets", job.Targets)
 
 	s.writeJSON(w, http.StatusOK, JobResponse{
 		JobID: job.ID,
@@ -164,90 +180,34 @@ func (s *Server) HandleGetJobStatus(w http.ResponseWriter, r *http.Request) {
 	job, err := s.jobManager.Get(jobID)
 	if err != nil {
 		s.logger.Debug("Job not found", "job_id", jobID)
-		s.writeJSON(w, http.StatusNotFound, map[string]string{
-			"status": "not_found",
-		})
+		s.writeError(w, http.StatusNotFound, ErrorCodeNotFound, "Job not found: "+jobID)
 		return
 	}
 
 	s.writeJSON(w, http.StatusOK, job)
 }
 
-// HandleHealth handles GET /health
-func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	s.writeJSON(w, http.StatusOK, map[string]string{
-		"status": "healthy",
-	})
-}
-
-// HandleBlock handles POST /block/{duration}
-func (s *Server) HandleBlock(w http.ResponseWriter, r *http.Request) {
-	// Parse duration from URL parameter (in minutes)
-	durationStr := chi.URLParam(r, "duration")
-	duration := 10 // Default 10 minutes
-	if durationStr != "" {
-		if parsedDuration, err := strconv.Atoi(durationStr); err == nil {
-			duration = parsedDuration
-		}
-	}
-
-	s.blockMutex.Lock()
-	defer s.blockMutex.Unlock()
+// HandleGetJobLogs handles GET /job/{job_id}/logs
+func (s *Server) HandleGetJobLogs(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "job_id")
 
-	// Cancel any existing unblock timer
-	if s.unblockCancel != nil {
-		s.unblockCancel()
+	entries, dropped, err := s.jobManager.GetLogs(jobID)
+	if err != nil {
+		s.logger.Debug("Job not found", "job_id", jobID)
+		s.writeError(w, http.StatusNotFound, ErrorCodeNotFound, "Job not found: "+jobID)
+		return
 	}
 
-	// Set blocked state
-	s.isBlocked = true
-
-	// Create context for auto-unblock
-	ctx, cancel := context.WithCancel(context.Background())
-	s.unblockCancel = cancel
-
-	// Start auto-unblock timer
-	go func() {
-		timer := time.NewTimer(time.Duration(duration) * time.Minute)
-		defer timer.Stop()
-
-		select {
-		case <-timer.C:
-			s.blockMutex.Lock()
-			s.isBlocked = false
-			s.unblockCancel = nil
-			s.blockMutex.Unlock()
-			s.logger.Info("Auto unblock complete")
-		case <-ctx.Done():
-			// Timer was cancelled
-			return
-		}
-	}()
-
-	s.logger.Info("Operations are now blocked", "duration_minutes", duration)
-	s.writeJSON(w, http.StatusOK, map[string]string{
-		"message": "Operations are now blocked for " + strconv.Itoa(duration) + " minutes",
+	s.writeJSON(w, http.StatusOK, JobLogsResponse{
+		JobID:   jobID,
+		Entries: entries,
+		Dropped: dropped,
 	})
 }
 
-// HandleUnblock handles POST /unblock
-func (s *Server) HandleUnblock(w http.ResponseWriter, r *http.Request) {
-	s.blockMutex.Lock()
-	defer s.blockMutex.Unlock()
-
-	// Cancel auto-unblock timer if exists
-	if s.unblockCancel != nil {
-		s.unblockCancel()
-		s.unblockCancel = nil
-	}
-
-	// Unblock operations
-	s.isBlocked = false
-
-	s.logger.Info("Operations are now unblocked")
-	s.writeJSON(w, http.StatusOK, map[string]string{
-		"message": "Operations are now unblocked",
-	})
+// HandleHealth handles GET /ping
+func (s *Server) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, StatusResponse{Status: "healthy"})
 }
 
 // writeJSON writes a JSON response
@@ -259,8 +219,3 @@ func (s *Server) writeJSON(w http.ResponseWriter, status int, data any) {
 		s.logger.Error("Failed to encode JSON response", "error", err)
 	}
 }
-
-// writeError writes an error JSON response
-func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
-	s.writeJSON(w, status, ErrorResponse{Error: message})
-}
@@ -1,13 +1,14 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/saltyorg/sdc/internal/blocks"
 	"github.com/saltyorg/sdc/internal/jobs"
 	"github.com/saltyorg/sdc/pkg/logger"
 )
@@ -48,11 +49,9 @@ func TestBlockUnblock(t *testing.T) {
 		}
 
 		// Verify operations are blocked
-		server.blockMutex.RLock()
-		if !server.isBlocked {
+		if _, blocked := server.blocks.BlockingOperation(blocks.OperationStart); !blocked {
 			t.Error("Expected operations to be blocked")
 		}
-		server.blockMutex.RUnlock()
 	})
 
 	t.Run("start/stop blocked when blocked", func(t *testing.T) {
@@ -70,8 +69,8 @@ func TestBlockUnblock(t *testing.T) {
 			t.Fatalf("Failed to decode response: %v", err)
 		}
 
-		if response.Error != "Operation blocked" {
-			t.Errorf("Expected 'Operation blocked', got '%s'", response.Error)
+		if !strings.HasPrefix(response.Error, "Operation blocked") || response.Code != ErrorCodeBlocked {
+			t.Errorf("Expected 'Operation blocked', got '%s' (%s)", response.Error, response.Code)
 		}
 
 		// Try to stop containers while blocked
@@ -87,8 +86,8 @@ func TestBlockUnblock(t *testing.T) {
 			t.Fatalf("Failed to decode response: %v", err)
 		}
 
-		if response.Error != "Operation blocked" {
-			t.Errorf("Expected 'Operation blocked', got '%s'", response.Error)
+		if !strings.HasPrefix(response.Error, "Operation blocked") || response.Code != ErrorCodeBlocked {
+			t.Errorf("Expected 'Operation blocked', got '%s' (%s)", response.Error, response.Code)
 		}
 	})
 
@@ -113,11 +112,9 @@ func TestBlockUnblock(t *testing.T) {
 		}
 
 		// Verify operations are unblocked
-		server.blockMutex.RLock()
-		if server.isBlocked {
+		if _, blocked := server.blocks.BlockingOperation(blocks.OperationStart); blocked {
 			t.Error("Expected operations to be unblocked")
 		}
-		server.blockMutex.RUnlock()
 	})
 
 	t.Run("block with explicit 10 minute duration", func(t *testing.T) {
@@ -161,44 +158,83 @@ func TestAutoUnblock(t *testing.T) {
 	// Create server
 	server := NewServer(jobManager, log)
 
-	// Block for 1 second (we'll use a very short duration for testing)
-	// Note: We can't actually test with 1 second via the API since it expects minutes
-	// So we'll manually set up the test
-	server.blockMutex.Lock()
-	server.isBlocked = true
-	ctx, cancel := context.WithCancel(context.Background())
-	server.unblockCancel = cancel
-
-	go func() {
-		timer := time.NewTimer(2 * time.Second)
-		defer timer.Stop()
-
-		select {
-		case <-timer.C:
-			server.blockMutex.Lock()
-			server.isBlocked = false
-			server.unblockCancel = nil
-			server.blockMutex.Unlock()
-		case <-ctx.Done():
-			return
-		}
-	}()
-	server.blockMutex.Unlock()
+	// The API only accepts whole minutes, so add a short block directly
+	if _, err := server.blocks.Add(blocks.Block{Scope: blocks.ScopeGlobal}, 2*time.Second); err != nil {
+		t.Fatalf("Failed to add block: %v", err)
+	}
 
 	// Verify initially blocked
-	server.blockMutex.RLock()
-	if !server.isBlocked {
+	if _, blocked := server.blocks.BlockingOperation(blocks.OperationStop); !blocked {
 		t.Error("Expected operations to be blocked initially")
 	}
-	server.blockMutex.RUnlock()
 
 	// Wait for auto-unblock (2 seconds + small buffer)
 	time.Sleep(3 * time.Second)
 
 	// Verify auto-unblocked
-	server.blockMutex.RLock()
-	if server.isBlocked {
+	if _, blocked := server.blocks.BlockingOperation(blocks.OperationStop); blocked {
 		t.Error("Expected operations to be auto-unblocked after timeout")
 	}
-	server.blockMutex.RUnlock()
+	if len(server.blocks.List()) != 0 {
+		t.Error("Expected expired block to be removed")
+	}
+}
+
+func TestGetJobLogs(t *testing.T) {
+	log, err := logger.New(false)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	server := NewServer(jobManager, log)
+	router := server.Router()
+
+	t.Run("unknown job returns 404", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/job/missing/logs", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("captured lines are returned", func(t *testing.T) {
+		// An unknown job type fails immediately without touching Docker
+		job := jobs.NewJob(jobs.JobType("noop"), 600, nil)
+		if _, err := jobManager.Submit(job); err != nil {
+			t.Fatalf("Failed to submit job: %v", err)
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if j, err := jobManager.Get(job.ID); err == nil && j.Status == jobs.JobStatusFailed {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		req := httptest.NewRequest("GET", "/job/"+job.ID+"/logs", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var response JobLogsResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if response.JobID != job.ID {
+			t.Errorf("Expected job ID %s, got %s", job.ID, response.JobID)
+		}
+		if len(response.Entries) == 0 || response.Entries[0].Message != "Processing job" {
+			t.Errorf("Unexpected entries: %+v", response.Entries)
+		}
+	})
 }
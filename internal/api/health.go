@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	// DockerPingTimeout bounds the Docker ping performed by the readiness check
+	DockerPingTimeout = 5 * time.Second
+
+	// DockerLatencyThreshold is the ping latency above which Docker is reported as degraded
+	DockerLatencyThreshold = 1 * time.Second
+
+	// QueueSaturationThreshold is the fraction of queue capacity at which the controller stops being ready
+	QueueSaturationThreshold = 0.9
+)
+
+// Check statuses reported by the readiness endpoint
+const (
+	CheckStatusOK       = "ok"
+	CheckStatusDegraded = "degraded" // Working, but slow; does not fail readiness
+	CheckStatusFailed   = "failed"
+	CheckStatusSkipped  = "skipped" // Check not configured
+)
+
+// DockerPinger checks connectivity to the Docker daemon
+type DockerPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status  string         `json:"status"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// ReadinessResponse is returned by GET /readyz
+type ReadinessResponse struct {
+	Status string                 `json:"status"` // "ready" or "not_ready"
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// SetDockerPinger enables the Docker connectivity readiness check
+func (s *Server) SetDockerPinger(docker DockerPinger) {
+	s.docker = docker
+}
+
+// HandleLiveness handles GET /healthz
+// The process is alive if it can answer HTTP requests at all.
+func (s *Server) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, StatusResponse{Status: "alive"})
+}
+
+// HandleReadiness handles GET /readyz
+// The controller is ready when Docker answers, the job queue has room and
+// all workers are running. Returns HTTP 503 with per-check detail otherwise.
+func (s *Server) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]CheckResult{
+		"docker":    s.checkDocker(r.Context()),
+		"job_queue": s.checkJobQueue(),
+		"workers":   s.checkWorkers(),
+	}
+
+	response := ReadinessResponse{
+		Status: "ready",
+		Checks: checks,
+	}
+	status := http.StatusOK
+
+	for name, check := range checks {
+		if check.Status == CheckStatusFailed {
+			response.Status = "not_ready"
+			status = http.StatusServiceUnavailable
+			s.logger.Debug("Readiness check failed",
+				"check", name,
+				"message", check.Message)
+		}
+	}
+
+	s.writeJSON(w, status, response)
+}
+
+// checkDocker pings the Docker daemon and reports latency
+func (s *Server) checkDocker(ctx context.Context) CheckResult {
+	if s.docker == nil {
+		return CheckResult{Status: CheckStatusSkipped, Message: "Docker check not configured"}
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, DockerPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.docker.Ping(pingCtx)
+	latency := time.Since(start)
+
+	details := map[string]any{
+		"latency_ms": latency.Milliseconds(),
+	}
+
+	if err != nil {
+		return CheckResult{
+			Status:  CheckStatusFailed,
+			Message: "Docker daemon unreachable: " + err.Error(),
+			Details: details,
+		}
+	}
+
+	if latency > DockerLatencyThreshold {
+		return CheckResult{
+			Status:  CheckStatusDegraded,
+			Message: "Docker daemon responding slowly",
+			Details: details,
+		}
+	}
+
+	return CheckResult{Status: CheckStatusOK, Details: details}
+}
+
+// checkJobQueue fails when the job queue is close to full
+func (s *Server) checkJobQueue() CheckResult {
+	stats := s.jobManager.Stats()
+	details := map[string]any{
+		"queued":   stats.Queued,
+		"capacity": stats.Capacity,
+	}
+
+	if stats.Capacity > 0 && float64(stats.Queued) >= float64(stats.Capacity)*QueueSaturationThreshold {
+		return CheckResult{
+			Status:  CheckStatusFailed,
+			Message: "Job queue saturated",
+			Details: details,
+		}
+	}
+
+	return CheckResult{Status: CheckStatusOK, Details: details}
+}
+
+// checkWorkers fails when any worker goroutine has exited
+func (s *Server) checkWorkers() CheckResult {
+	stats := s.jobManager.Stats()
+	details := map[string]any{
+		"alive":    stats.WorkersAlive,
+		"expected": stats.Workers,
+	}
+
+	if stats.WorkersAlive < stats.Workers {
+		return CheckResult{
+			Status:  CheckStatusFailed,
+			Message: "Job workers not running",
+			Details: details,
+		}
+	}
+
+	return CheckResult{Status: CheckStatusOK, Details: details}
+}
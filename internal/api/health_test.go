@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePinger is a DockerPinger with a configurable result
+type fakePinger struct {
+	err   error
+	delay time.Duration
+}
+
+func (f *fakePinger) Ping(ctx context.Context) error {
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	return f.err
+}
+
+func newHealthTestServer(t *testing.T, pinger DockerPinger) http.Handler {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 2)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	server := NewServer(jobManager, log)
+	if pinger != nil {
+		server.SetDockerPinger(pinger)
+	}
+	return server.Router()
+}
+
+func getReadiness(t *testing.T, router http.Handler) (int, ReadinessResponse) {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response ReadinessResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	return w.Code, response
+}
+
+func TestLiveness(t *testing.T) {
+	router := newHealthTestServer(t, &fakePinger{err: errors.New("daemon down")})
+
+	req := httptest.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Liveness doesn't depend on Docker
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadiness_Ready(t *testing.T) {
+	router := newHealthTestServer(t, &fakePinger{})
+
+	code, response := getReadiness(t, router)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", response.Status)
+	assert.Equal(t, CheckStatusOK, response.Checks["docker"].Status)
+	assert.Equal(t, CheckStatusOK, response.Checks["job_queue"].Status)
+	assert.Equal(t, CheckStatusOK, response.Checks["workers"].Status)
+	assert.EqualValues(t, 2, response.Checks["workers"].Details["alive"])
+}
+
+func TestReadiness_DockerDown(t *testing.T) {
+	router := newHealthTestServer(t, &fakePinger{err: errors.New("connection refused")})
+
+	code, response := getReadiness(t, router)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", response.Status)
+	assert.Equal(t, CheckStatusFailed, response.Checks["docker"].Status)
+	assert.Contains(t, response.Checks["docker"].Message, "connection refused")
+}
+
+func TestReadiness_DockerSlow(t *testing.T) {
+	router := newHealthTestServer(t, &fakePinger{delay: DockerLatencyThreshold + 50*time.Millisecond})
+
+	code, response := getReadiness(t, router)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, CheckStatusDegraded, response.Checks["docker"].Status)
+}
+
+func TestReadiness_WorkersStopped(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 2)
+	router := NewServer(jobManager, log).Router()
+
+	// Shutting down the manager stops its workers
+	jobManager.Shutdown(1 * time.Second)
+
+	code, response := getReadiness(t, router)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, CheckStatusSkipped, response.Checks["docker"].Status)
+	assert.Equal(t, CheckStatusFailed, response.Checks["workers"].Status)
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key of a mutating request
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// IdempotencyRetention is how long a key is remembered after its request succeeded
+	IdempotencyRetention = 24 * time.Hour
+
+	// MaxIdempotencyKeyLength is the longest accepted key
+	MaxIdempotencyKeyLength = 255
+
+	// maxIdempotencyKeys bounds the number of remembered keys; the oldest are
+	// forgotten first
+	maxIdempotencyKeys = 1000
+)
+
+// idempotencyEntry is the outcome of the first request made with a key
+type idempotencyEntry struct {
+	fingerprint string
+	createdAt   time.Time
+	done        chan struct{} // Closed once the first request has finished
+
+	// Set when the first request succeeded; entries for failed requests are dropped
+	status      int
+	contentType string
+	body        []byte
+}
+
+// idempotencyStore remembers the responses of successful mutating requests by key
+type idempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{entries: make(map[string]*idempotencyEntry)}
+}
+
+// claim returns the entry for key, creating it if the key is unknown or
+// expired. created reports whether the caller now owns the new entry.
+func (st *idempotencyStore) claim(key, fingerprint string) (entry *idempotencyEntry, created bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := st.entries[key]; ok && now.Sub(existing.createdAt) < IdempotencyRetention {
+		return existing, false
+	}
+
+	st.pruneLocked(now)
+	entry = &idempotencyEntry{
+		fingerprint: fingerprint,
+		createdAt:   now,
+		done:        make(chan struct{}),
+	}
+	st.entries[key] = entry
+	return entry, true
+}
+
+// complete records the response of the request owning entry, or forgets the
+// key if the request failed so it can be retried
+func (st *idempotencyStore) complete(key string, entry *idempotencyEntry, rec *responseRecorder) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if rec.status >= 200 && rec.status < 300 {
+		entry.status = rec.status
+		entry.contentType = rec.Header().Get("Content-Type")
+		entry.body = rec.body.Bytes()
+	} else if st.entries[key] == entry {
+		delete(st.entries, key)
+	}
+	close(entry.done)
+}
+
+// pruneLocked drops expired entries, then the oldest finished entries while
+// the store is full. Caller must hold st.mu.
+func (st *idempotencyStore) pruneLocked(now time.Time) {
+	for key, entry := range st.entries {
+		if now.Sub(entry.createdAt) >= IdempotencyRetention {
+			delete(st.entries, key)
+		}
+	}
+
+	for len(st.entries) >= maxIdempotencyKeys {
+		oldestKey := ""
+		var oldest *idempotencyEntry
+		for key, entry := range st.entries {
+			if entry.status == 0 {
+				continue // Still in flight
+			}
+			if oldest == nil || entry.createdAt.Before(oldest.createdAt) {
+				oldestKey, oldest = key, entry
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		delete(st.entries, oldestKey)
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(code int) {
+	if rr.status == 0 {
+		rr.status = code
+	}
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	rr.body.Write(p)
+	return rr.ResponseWriter.Write(p)
+}
+
+// IdempotencyMiddleware makes retries of a mutating request safe. A request
+// repeating the Idempotency-Key of an earlier successful request with the same
+// parameters gets the original response replayed instead of running again;
+// with different parameters it is rejected with 409. Requests without the
+// header are passed through.
+func (s *Server) IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > MaxIdempotencyKeyLength {
+			s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBodySize))
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped to the caller's credentials
+		storeKey := credentialID(r) + "\x00" + key
+		fingerprint := requestFingerprint(r, body)
+
+		for {
+			entry, created := s.idempotency.claim(storeKey, fingerprint)
+			if created {
+				rec := &responseRecorder{ResponseWriter: w}
+				defer func() {
+					if rec.status == 0 {
+						rec.status = http.StatusInternalServerError // Handler panicked
+					}
+					s.idempotency.complete(storeKey, entry, rec)
+				}()
+				next.ServeHTTP(rec, r)
+				return
+			}
+
+			if entry.fingerprint != fingerprint {
+				s.writeError(w, http.StatusConflict, ErrorCodeConflict, "Idempotency-Key was already used with different parameters")
+				return
+			}
+
+			// Wait for the first request with this key to finish
+			select {
+			case <-entry.done:
+			case <-r.Context().Done():
+				return
+			}
+
+			if entry.status == 0 {
+				continue // It failed and released the key; run this request instead
+			}
+
+			s.logger.Debug("Replaying idempotent response",
+				"method", r.Method,
+				"path", r.URL.Path)
+			if entry.contentType != "" {
+				w.Header().Set("Content-Type", entry.contentType)
+			}
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(entry.status)
+			w.Write(entry.body)
+			return
+		}
+	})
+}
+
+// credentialID identifies the credentials a request was made with, without
+// retaining the credentials themselves
+func credentialID(r *http.Request) string {
+	h := sha256.New()
+	if token, ok := bearerToken(r); ok {
+		h.Write([]byte("token:" + token))
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		h.Write([]byte("cert:"))
+		h.Write(r.TLS.PeerCertificates[0].Raw)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// requestFingerprint summarizes the parameters of a request. Versioned and
+// unversioned paths, query parameter order and JSON formatting don't matter.
+func requestFingerprint(r *http.Request, body []byte) string {
+	path := strings.TrimPrefix(r.URL.Path, APIPrefix)
+	if path == "" {
+		path = "/"
+	}
+
+	// Re-encoding sorts object keys and drops insignificant whitespace
+	var parsed any
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		if canonical, err := json.Marshal(parsed); err == nil {
+			body = canonical
+		}
+	}
+
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(r.Method), []byte(path), []byte(r.URL.Query().Encode()), body} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
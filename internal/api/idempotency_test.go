@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyTestServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	server := NewServer(jobManager, log)
+	return server, server.Router()
+}
+
+func doIdempotent(router http.Handler, method, path, key, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeID(t *testing.T, w *httptest.ResponseRecorder, field string) string {
+	t.Helper()
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	id, _ := body[field].(string)
+	require.NotEmpty(t, id, "response has no %s", field)
+	return id
+}
+
+func TestIdempotency_ReplaysJobSubmission(t *testing.T) {
+	_, router := newIdempotencyTestServer(t)
+
+	first := doIdempotent(router, "POST", "/start", "boot-1", "", `{"timeout": 60, "targets": ["plex"]}`)
+	require.Equal(t, http.StatusOK, first.Code, first.Body.String())
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	jobID := decodeID(t, first, "job_id")
+
+	// Same key and parameters, formatted differently and sent to the versioned path
+	retry := doIdempotent(router, "POST", APIPrefix+"/start", "boot-1", "", `{"targets":["plex"],"timeout":60}`)
+	require.Equal(t, http.StatusOK, retry.Code, retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, jobID, decodeID(t, retry, "job_id"))
+
+	// Same key, different parameters
+	conflict := doIdempotent(router, "POST", "/start", "boot-1", "", `{"timeout": 120, "targets": ["plex"]}`)
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+	assert.Equal(t, ErrorCodeConflict, decodeError(t, conflict).Code)
+
+	// The same key on another endpoint is a different request too
+	conflict = doIdempotent(router, "POST", "/stop", "boot-1", "", `{"timeout": 60, "targets": ["plex"]}`)
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+}
+
+func TestIdempotency_CreatesOnce(t *testing.T) {
+	server, router := newIdempotencyTestServer(t)
+
+	body := `{"containers": ["plex"], "reason": "backup"}`
+	first := doIdempotent(router, "POST", "/blocks", "backup-42", "", body)
+	require.Equal(t, http.StatusCreated, first.Code)
+	blockID := decodeID(t, first, "id")
+
+	retry := doIdempotent(router, "POST", "/blocks", "backup-42", "", body)
+	require.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, blockID, decodeID(t, retry, "id"))
+
+	// Without a key every request creates a block
+	other := doIdempotent(router, "POST", "/blocks", "", "", body)
+	require.Equal(t, http.StatusCreated, other.Code)
+	assert.NotEqual(t, blockID, decodeID(t, other, "id"))
+
+	assert.Len(t, server.Blocks().List(), 2)
+}
+
+func TestIdempotency_FailedRequestReleasesKey(t *testing.T) {
+	_, router := newIdempotencyTestServer(t)
+
+	bad := doIdempotent(router, "POST", "/blocks", "key", "", `{"scope": "nonsense"}`)
+	require.Equal(t, http.StatusBadRequest, bad.Code)
+
+	// A corrected retry with the same key is processed, not rejected as a conflict
+	good := doIdempotent(router, "POST", "/blocks", "key", "", `{"scope": "global"}`)
+	assert.Equal(t, http.StatusCreated, good.Code, good.Body.String())
+}
+
+func TestIdempotency_ConcurrentRetriesRunOnce(t *testing.T) {
+	server, router := newIdempotencyTestServer(t)
+
+	var wg sync.WaitGroup
+	ids := make([]string, 5)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := doIdempotent(router, "POST", "/blocks", "same", "", `{"scope": "stop"}`)
+			if w.Code == http.StatusCreated {
+				var body map[string]any
+				if json.NewDecoder(w.Body).Decode(&body) == nil {
+					ids[i], _ = body["id"].(string)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	assert.Len(t, server.Blocks().List(), 1)
+}
+
+func TestIdempotency_KeysScopedToCredentials(t *testing.T) {
+	server, _ := newIdempotencyTestServer(t)
+	auth := NewAuthenticator()
+	auth.AddToken("alice", ScopeRead, ScopeWrite)
+	auth.AddToken("bob", ScopeRead, ScopeWrite)
+	server.SetAuthenticator(auth)
+	router := server.Router()
+
+	body := `{"scope": "global"}`
+	a := doIdempotent(router, "POST", "/blocks", "shared", "alice", body)
+	b := doIdempotent(router, "POST", "/blocks", "shared", "bob", body)
+	require.Equal(t, http.StatusCreated, a.Code)
+	require.Equal(t, http.StatusCreated, b.Code)
+	assert.NotEqual(t, decodeID(t, a, "id"), decodeID(t, b, "id"))
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	_, router := newIdempotencyTestServer(t)
+
+	w := doIdempotent(router, "POST", "/unblock", strings.Repeat("k", MaxIdempotencyKeyLength+1), "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIdempotencyStore_Expiry(t *testing.T) {
+	st := newIdempotencyStore()
+
+	entry, created := st.claim("key", "a")
+	require.True(t, created)
+	st.complete("key", entry, &responseRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK})
+
+	_, created = st.claim("key", "b")
+	assert.False(t, created, "key is remembered within the retention window")
+
+	entry.createdAt = time.Now().Add(-IdempotencyRetention)
+	reclaimed, created := st.claim("key", "b")
+	assert.True(t, created, "expired key can be reused")
+	assert.Equal(t, "b", reclaimed.fingerprint)
+}
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+	var resp ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	return resp
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+)
+
+// DefaultJobListLimit is the number of jobs GET /jobs returns when no limit is given
+const DefaultJobListLimit = 50
+
+// JobsResponse is returned by GET /jobs
+type JobsResponse struct {
+	Jobs []*jobs.Job `json:"jobs"` // Newest first
+}
+
+// HandleListJobs handles GET /jobs?status=&type=&limit=
+func (s *Server) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := jobs.JobStatus(query.Get("status"))
+	jobType := jobs.JobType(query.Get("type"))
+
+	limit := DefaultJobListLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, "invalid limit "+strconv.Quote(value)+": must be a non-negative integer")
+			return
+		}
+		limit = parsed
+	}
+
+	result := make([]*jobs.Job, 0)
+	for _, job := range s.jobManager.List() {
+		if status != "" && job.Status != status {
+			continue
+		}
+		if jobType != "" && job.Type != jobType {
+			continue
+		}
+		result = append(result, job)
+	}
+
+	slices.SortFunc(result, func(a, b *jobs.Job) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	s.writeJSON(w, http.StatusOK, JobsResponse{Jobs: result})
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListJobs(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	router := NewServer(jobManager, log).Router()
+
+	// Distinct targets keep the jobs from being coalesced
+	var submitted []*jobs.Job
+	for i, jobType := range []jobs.JobType{jobs.JobTypeStart, jobs.JobTypeStop, jobs.JobTypeStart} {
+		job := jobs.NewJob(jobType, 60, nil)
+		job.Targets = []string{string(rune('a' + i))}
+		job.CreatedAt = time.Now().Add(time.Duration(i) * time.Second)
+		_, err := jobManager.Submit(job)
+		require.NoError(t, err)
+		submitted = append(submitted, job)
+	}
+
+	list := func(query string) []string {
+		req := httptest.NewRequest("GET", "/jobs"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Jobs []struct {
+				ID string `json:"id"`
+			} `json:"jobs"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+		var ids []string
+		for _, job := range resp.Jobs {
+			ids = append(ids, job.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{submitted[2].ID, submitted[1].ID, submitted[0].ID}, list(""), "newest first")
+	assert.Equal(t, []string{submitted[2].ID, submitted[0].ID}, list("?type=start"))
+	assert.Equal(t, []string{submitted[2].ID}, list("?limit=1"))
+	assert.Empty(t, list("?status=running&type=nothing"))
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/saltyorg/sdc/internal/lint"
+)
+
+// LintProvider checks the container labels and dependency graph
+type LintProvider interface {
+	Lint(ctx context.Context) (*lint.Report, error)
+}
+
+// SetLintProvider enables the lint endpoint
+func (s *Server) SetLintProvider(provider LintProvider) {
+	s.lint = provider
+}
+
+// HandleLint handles GET /lint
+func (s *Server) HandleLint(w http.ResponseWriter, r *http.Request) {
+	if s.lint == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Lint is not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), GraphTimeout)
+	defer cancel()
+
+	report, err := s.lint.Lint(ctx)
+	if err != nil {
+		s.logger.Error("Failed to lint containers", "error", err)
+		s.writeError(w, http.StatusInternalServerError, ErrorCodeInternal, "Failed to lint containers: "+err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/internal/lint"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLintProvider struct {
+	report *lint.Report
+	err    error
+}
+
+func (f *fakeLintProvider) Lint(ctx context.Context) (*lint.Report, error) {
+	return f.report, f.err
+}
+
+func getLint(t *testing.T, provider LintProvider) *httptest.ResponseRecorder {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	server := NewServer(jobManager, log)
+	server.SetLintProvider(provider)
+
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, httptest.NewRequest("GET", "/lint", nil))
+	return w
+}
+
+func TestLint(t *testing.T) {
+	report := &lint.Report{
+		Findings: []lint.Finding{{Severity: lint.SeverityError, Rule: lint.RuleCycle, Message: "dependency cycle: a -> b -> a"}},
+		Errors:   1,
+	}
+
+	w := getLint(t, &fakeLintProvider{report: report})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp lint.Report
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, *report, resp)
+}
+
+func TestLint_ProviderError(t *testing.T) {
+	w := getLint(t, &fakeLintProvider{err: errors.New("docker unreachable")})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
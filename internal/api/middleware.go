@@ -65,7 +65,7 @@ func (s *Server) RecoveryMiddleware(next http.Handler) http.Handler {
 					"method", r.Method,
 					"path", r.URL.Path)
 
-				s.writeError(w, http.StatusInternalServerError, "Internal server error")
+				s.writeError(w, http.StatusInternalServerError, ErrorCodeInternal, "Internal server error")
 			}
 		}()
 
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/blocks"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+)
+
+// APIVersion is the version of the API described by the OpenAPI document
+const APIVersion = "1.0.0"
+
+// OpenAPIDocument is the subset of the OpenAPI 3 document model used by this API
+type OpenAPIDocument struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       OpenAPIInfo                     `json:"info"`
+	Servers    []OpenAPIServer                 `json:"servers"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+	Security   []map[string][]string           `json:"security"`
+}
+
+// OpenAPIInfo describes the API
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIServer is a base URL the paths are relative to
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+// Operation is a single method on a path
+type Operation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []Parameter                `json:"parameters,omitempty"`
+	RequestBody *RequestBody               `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Explode     *bool   `json:"explode,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes a JSON request body
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// OpenAPIResponse describes one response of an operation
+type OpenAPIResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how callers authenticate
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema is a JSON schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// schemaNames overrides component names for types whose Go name is too generic
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeFor[logger.Entry](): "LogEntry",
+}
+
+// schemaEnums lists the allowed values of string enum types
+var schemaEnums = map[reflect.Type][]any{
+	reflect.TypeFor[ErrorCode](): enumValues(ErrorCodes),
+	reflect.TypeFor[jobs.JobType](): {
+		jobs.JobTypeStart,
+		jobs.JobTypeStop,
+	},
+	reflect.TypeFor[blocks.Scope](): enumValues(blocks.Scopes),
+	reflect.TypeFor[jobs.JobStatus](): {
+		jobs.JobStatusPending,
+		jobs.JobStatusRunning,
+		jobs.JobStatusCompleted,
+		jobs.JobStatusFailed,
+		jobs.JobStatusCancelled,
+	},
+}
+
+func enumValues[T any](values []T) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+// OpenAPI generates the OpenAPI 3 document for the versioned API
+func (s *Server) OpenAPI() *OpenAPIDocument {
+	gen := &schemaGenerator{schemas: make(map[string]*Schema)}
+
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       "Saltbox Docker Controller API",
+			Description: "Dependency-aware start/stop orchestration for Docker containers.",
+			Version:     APIVersion,
+		},
+		Servers: []OpenAPIServer{{URL: APIPrefix}},
+		Paths:   make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas: gen.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Only enforced when the server is configured with tokens",
+				},
+			},
+		},
+		// Authentication is optional server-side
+		Security: []map[string][]string{{"bearerAuth": {}}, {}},
+	}
+
+	errorSchema := gen.schemaFor(reflect.TypeFor[ErrorResponse]())
+
+	for _, rt := range s.routes() {
+		op := Operation{
+			OperationID: rt.operationID,
+			Summary:     rt.summary,
+			Description: "Requires the " + string(rt.scope) + " scope when authentication is enabled.",
+			Responses:   make(map[string]OpenAPIResponse),
+		}
+
+		params := rt.params
+		if rt.mutating() {
+			params = append(slices.Clone(params), idempotencyKeyParam)
+		}
+
+		for _, p := range params {
+			param := Parameter{
+				Name:        p.name,
+				In:          p.in,
+				Description: p.description,
+				Required:    p.in == "path",
+				Schema:      gen.schemaFor(reflect.TypeOf(p.example)),
+			}
+			if param.Schema.Type == "array" {
+				explode := true
+				param.Explode = &explode
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+
+		if rt.mutating() {
+			op.Responses[strconv.Itoa(http.StatusConflict)] = OpenAPIResponse{
+				Description: "Idempotency-Key was already used with different parameters",
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			}
+		}
+
+		if rt.request != nil {
+			op.RequestBody = &RequestBody{
+				Content: map[string]MediaType{
+					"application/json": {Schema: gen.schemaFor(reflect.TypeOf(rt.request))},
+				},
+			}
+		}
+
+		for status, resp := range rt.responses {
+			op.Responses[strconv.Itoa(status)] = OpenAPIResponse{
+				Description: resp.description,
+				Content: map[string]MediaType{
+					"application/json": {Schema: gen.schemaFor(reflect.TypeOf(resp.body))},
+				},
+			}
+		}
+
+		// Returned by middleware on every route
+		for status, description := range map[int]string{
+			http.StatusUnauthorized:        "Missing or unknown credentials",
+			http.StatusForbidden:           "Credentials lack the " + string(rt.scope) + " scope",
+			http.StatusInternalServerError: "Internal error",
+		} {
+			op.Responses[strconv.Itoa(status)] = OpenAPIResponse{
+				Description: description,
+				Content:     map[string]MediaType{"application/json": {Schema: errorSchema}},
+			}
+		}
+
+		path := rt.path
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]Operation)
+		}
+		doc.Paths[path][strings.ToLower(rt.method)] = op
+	}
+
+	return doc
+}
+
+// HandleOpenAPI handles GET /openapi.json
+func (s *Server) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.OpenAPI())
+}
+
+// schemaGenerator derives JSON schemas from Go types, registering named
+// struct types as components
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type) *Schema {
+	if enum, ok := schemaEnums[t]; ok {
+		return &Schema{Type: "string", Enum: enum}
+	}
+
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeFor[time.Duration]():
+		return &Schema{Type: "integer", Format: "int64"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		// Nil slices and maps encode as null
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		return &Schema{} // Any value
+	}
+}
+
+// structSchema registers a struct type as a component and returns a reference to it
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	name, ok := schemaNames[t]
+	if !ok {
+		name = t.Name()
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+
+	if _, exists := g.schemas[name]; exists {
+		return ref
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.schemas[name] = schema // Registered before recursing so self-references terminate
+
+	g.addFields(schema, t)
+	sort.Strings(schema.Required)
+
+	return ref
+}
+
+// addFields adds the JSON fields of struct type t to schema. Fields of
+// embedded structs without a JSON name are promoted, as encoding/json does.
+func (g *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+		if fieldName == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			g.addFields(schema, field.Type)
+			continue
+		}
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+
+		schema.Properties[fieldName] = g.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+}
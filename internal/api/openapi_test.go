@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pathParamPattern matches {name} segments of a route pattern
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func newOpenAPITestServer(t *testing.T) (*Server, *jobs.Manager) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	t.Cleanup(func() { jobManager.Shutdown(1 * time.Second) })
+
+	return NewServer(jobManager, log), jobManager
+}
+
+func TestOpenAPI_MatchesRouter(t *testing.T) {
+	server, _ := newOpenAPITestServer(t)
+	doc := server.OpenAPI()
+
+	documented := make(map[string]bool)
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			documented[strings.ToUpper(method)+" "+path] = true
+
+			// Every path parameter in the pattern is declared
+			for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+				found := slices.ContainsFunc(op.Parameters, func(p Parameter) bool {
+					return p.In == "path" && p.Name == match[1] && p.Required
+				})
+				assert.True(t, found, "%s %s: path parameter %s not declared", method, path, match[1])
+			}
+		}
+	}
+
+	versioned := make(map[string]bool)
+	legacy := make(map[string]bool)
+	err := chi.Walk(server.Router().(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if path, ok := strings.CutPrefix(route, APIPrefix); ok {
+			versioned[method+" "+path] = true
+		} else {
+			legacy[method+" "+route] = true
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, documented, versioned, "OpenAPI paths and /api/v1 routes differ")
+	assert.Equal(t, versioned, legacy, "unversioned aliases and /api/v1 routes differ")
+}
+
+func TestOpenAPI_ResponsesMatchSchemas(t *testing.T) {
+	server, jobManager := newOpenAPITestServer(t)
+	router := server.Router()
+	doc := server.OpenAPI()
+
+	job := jobs.NewJob(jobs.JobTypeStart, 600, nil)
+	_, err := jobManager.Submit(job)
+	require.NoError(t, err)
+
+	tests := []struct {
+		method  string
+		path    string // Path template as documented
+		url     string
+		body    string
+		status  int
+		errCode ErrorCode
+	}{
+		{"GET", "/ping", "/ping", "", http.StatusOK, ""},
+		{"GET", "/healthz", "/healthz", "", http.StatusOK, ""},
+		{"GET", "/readyz", "/readyz", "", http.StatusOK, ""},
+		{"GET", "/openapi.json", "/openapi.json", "", http.StatusOK, ""},
+		{"GET", "/job_status/{job_id}", "/job_status/" + job.ID, "", http.StatusOK, ""},
+		{"GET", "/job_status/{job_id}", "/job_status/missing", "", http.StatusNotFound, ErrorCodeNotFound},
+		{"GET", "/job/{job_id}/logs", "/job/" + job.ID + "/logs", "", http.StatusOK, ""},
+		{"GET", "/job/{job_id}/logs", "/job/missing/logs", "", http.StatusNotFound, ErrorCodeNotFound},
+		{"GET", "/jobs", "/jobs?type=start", "", http.StatusOK, ""},
+		{"GET", "/jobs", "/jobs?limit=-1", "", http.StatusBadRequest, ErrorCodeInvalidArgument},
+		{"GET", "/graph", "/graph", "", http.StatusServiceUnavailable, ErrorCodeUnavailable},
+		{"GET", "/containers", "/containers", "", http.StatusServiceUnavailable, ErrorCodeUnavailable},
+		{"GET", "/lint", "/lint", "", http.StatusServiceUnavailable, ErrorCodeUnavailable},
+		{"GET", "/schedules", "/schedules", "", http.StatusServiceUnavailable, ErrorCodeUnavailable},
+		{"POST", "/start", "/start?timeout=0", "", http.StatusBadRequest, ErrorCodeInvalidArgument},
+		{"POST", "/stop", "/stop", `{"bogus": 1}`, http.StatusBadRequest, ErrorCodeInvalidArgument},
+		{"POST", "/block/{duration}", "/block/5", "", http.StatusOK, ""},
+		{"POST", "/start", "/start", "", http.StatusServiceUnavailable, ErrorCodeBlocked},
+		{"POST", "/unblock", "/unblock", "", http.StatusOK, ""},
+		{"POST", "/stop", "/stop", `{"timeout": 60, "ignore": ["traefik"]}`, http.StatusOK, ""},
+		{"POST", "/blocks", "/blocks", `{"containers": ["plex"], "reason": "backup", "duration": "1h"}`, http.StatusCreated, ""},
+		{"POST", "/blocks", "/blocks", `{"scope": "sometimes"}`, http.StatusBadRequest, ErrorCodeInvalidArgument},
+		{"GET", "/blocks", "/blocks", "", http.StatusOK, ""},
+		{"DELETE", "/blocks/{block_id}", "/blocks/missing", "", http.StatusNotFound, ErrorCodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, APIPrefix+tt.url, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			op, ok := doc.Paths[tt.path][strings.ToLower(tt.method)]
+			require.True(t, ok, "operation not documented")
+
+			resp, ok := op.Responses[strconv.Itoa(w.Code)]
+			require.True(t, ok, "status %d not documented", w.Code)
+
+			var body any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			for _, problem := range validateSchema(doc, resp.Content["application/json"].Schema, body, "body") {
+				t.Error(problem)
+			}
+
+			if tt.errCode != "" {
+				var errResp ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.errCode, errResp.Code)
+				assert.NotEmpty(t, errResp.Error)
+			}
+		})
+	}
+}
+
+func TestErrorEnvelope_UnknownRoute(t *testing.T) {
+	server, _ := newOpenAPITestServer(t)
+	router := server.Router()
+
+	for _, path := range []string{"/nope", APIPrefix + "/nope"} {
+		req := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, ErrorCodeNotFound, response.Code)
+	}
+
+	req := httptest.NewRequest("GET", APIPrefix+"/start", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	var response ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, ErrorCodeMethodNotAllowed, response.Code)
+}
+
+// validateSchema checks a decoded JSON value against a schema of the document
+// and returns a description of every mismatch
+func validateSchema(doc *OpenAPIDocument, schema *Schema, value any, path string) []string {
+	if schema == nil {
+		return []string{path + ": no schema"}
+	}
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := doc.Components.Schemas[name]
+		if !ok {
+			return []string{path + ": unresolved reference " + schema.Ref}
+		}
+		return validateSchema(doc, resolved, value, path)
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return []string{path + ": unexpected null"}
+	}
+
+	var problems []string
+	mismatch := func() []string {
+		return []string{fmt.Sprintf("%s: expected %s, got %T", path, schema.Type, value)}
+	}
+
+	switch schema.Type {
+	case "":
+		// Any value
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return fmt.Sprint(e) == str }) {
+			problems = append(problems, fmt.Sprintf("%s: %q not in enum %v", path, str, schema.Enum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			return mismatch()
+		}
+		if schema.Type == "integer" && num != float64(int64(num)) {
+			return mismatch()
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch()
+		}
+		for i, item := range items {
+			problems = append(problems, validateSchema(doc, schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				problems = append(problems, path+": missing required property "+name)
+			}
+		}
+		for name, v := range obj {
+			if prop, ok := schema.Properties[name]; ok {
+				problems = append(problems, validateSchema(doc, prop, v, path+"."+name)...)
+			} else if schema.AdditionalProperties != nil {
+				problems = append(problems, validateSchema(doc, schema.AdditionalProperties, v, path+"."+name)...)
+			} else if schema.Properties != nil {
+				problems = append(problems, path+": undocumented property "+name)
+			}
+		}
+	default:
+		problems = append(problems, path+": unknown schema type "+schema.Type)
+	}
+
+	return problems
+}
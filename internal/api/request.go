@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+const (
+	// DefaultStartTimeout is the start job timeout in seconds when none is given
+	DefaultStartTimeout = 600
+
+	// DefaultStopTimeout is the stop job timeout in seconds when none is given
+	DefaultStopTimeout = 300
+
+	// MaxTimeout is the longest accepted job timeout in seconds (24 hours)
+	MaxTimeout = 86400
+
+	// maxRequestBodySize limits JSON request bodies
+	maxRequestBodySize = 1 << 20
+)
+
+// containerNamePattern matches valid Docker container names
+var containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// JobRequest is the request schema shared by POST /start and POST /stop.
+// All fields are optional. The same fields may be given as query parameters
+// (?timeout=600&ignore=a,b&targets=c&dependencies=false&supersede=true).
+type JobRequest struct {
+	Timeout *int       `json:"timeout,omitempty"` // Seconds; defaults per operation
+	Ignore  []string   `json:"ignore,omitempty"`  // Containers, patterns or "group:<name>" selectors to skip
+	Targets []string   `json:"targets,omitempty"` // Containers, patterns or "group=<name>" selectors to operate on (empty = all)
+	Options JobOptions `json:"options,omitempty"`
+}
+
+// JobOptions holds optional behavior switches for a job request
+type JobOptions struct {
+	// Dependencies pulls in what targets depend on (start) or what depends
+	// on targets (stop). Defaults to true; only relevant with targets.
+	Dependencies *bool `json:"dependencies,omitempty"`
+
+	// Supersede cancels pending jobs of the opposite operation on
+	// overlapping containers, e.g. a stop cancelling a queued start
+	Supersede bool `json:"supersede,omitempty"`
+}
+
+// IncludeDependencies returns the effective dependencies option
+func (o JobOptions) IncludeDependencies() bool {
+	return o.Dependencies == nil || *o.Dependencies
+}
+
+// requestError is a client error that maps to HTTP 400
+type requestError struct {
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...any) error {
+	return &requestError{message: fmt.Sprintf(format, args...)}
+}
+
+// parseJobRequest reads a job request from the JSON body and query parameters.
+// Query parameters are merged with the body; a timeout given in both places
+// must agree. The returned request has its timeout resolved to defaultTimeout
+// when unset.
+func parseJobRequest(r *http.Request, defaultTimeout int) (*JobRequest, error) {
+	req := &JobRequest{}
+
+	if err := decodeJSONBody(r, req); err != nil {
+		return nil, err
+	}
+
+	query := r.URL.Query()
+
+	if timeoutStr := query.Get("timeout"); timeoutStr != "" {
+		timeout, err := strconv.Atoi(timeoutStr)
+		if err != nil {
+			return nil, badRequest("invalid timeout %q: must be a whole number of seconds", timeoutStr)
+		}
+		if req.Timeout != nil && *req.Timeout != timeout {
+			return nil, badRequest("timeout given in both query (%d) and body (%d)", timeout, *req.Timeout)
+		}
+		req.Timeout = &timeout
+	}
+
+	req.Ignore = append(req.Ignore, splitListParam(query["ignore"])...)
+	req.Targets = append(req.Targets, splitListParam(query["targets"])...)
+
+	if depsStr := query.Get("dependencies"); depsStr != "" {
+		deps, err := strconv.ParseBool(depsStr)
+		if err != nil {
+			return nil, badRequest("invalid dependencies %q: must be true or false", depsStr)
+		}
+		req.Options.Dependencies = &deps
+	}
+
+	if supersedeStr := query.Get("supersede"); supersedeStr != "" {
+		supersede, err := strconv.ParseBool(supersedeStr)
+		if err != nil {
+			return nil, badRequest("invalid supersede %q: must be true or false", supersedeStr)
+		}
+		req.Options.Supersede = supersede
+	}
+
+	if req.Timeout == nil {
+		timeout := defaultTimeout
+		req.Timeout = &timeout
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// Validate checks the request fields
+func (req *JobRequest) Validate() error {
+	if req.Timeout != nil && (*req.Timeout <= 0 || *req.Timeout > MaxTimeout) {
+		return badRequest("invalid timeout %d: must be between 1 and %d seconds", *req.Timeout, MaxTimeout)
+	}
+
+	for _, name := range req.Ignore {
+		if !validSelector(name) {
+			return badRequest("invalid container name or pattern in ignore: %q", name)
+		}
+	}
+
+	for _, name := range req.Targets {
+		if !validSelector(name) {
+			return badRequest("invalid container name or pattern in targets: %q", name)
+		}
+	}
+
+	return nil
+}
+
+// validSelector reports whether s is a container name, a group selector
+// ("group=<name>" or "group:<name>") or a valid name pattern (glob or /regexp/)
+func validSelector(s string) bool {
+	if group, ok := graph.ParseGroupSelector(s); ok {
+		return containerNamePattern.MatchString(group)
+	}
+	if graph.IsPattern(s) {
+		return graph.ValidatePattern(s) == nil
+	}
+	return containerNamePattern.MatchString(s)
+}
+
+// decodeJSONBody decodes an optional JSON body, rejecting unknown fields.
+// An empty body leaves dst untouched.
+func decodeJSONBody(r *http.Request, dst any) error {
+	if r.Body == nil {
+		return nil
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(dst); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil // No body
+		}
+		return badRequest("invalid request body: %v", err)
+	}
+
+	if decoder.More() {
+		return badRequest("invalid request body: unexpected data after JSON object")
+	}
+
+	return nil
+}
+
+// splitListParam flattens repeated and comma-separated query values
+// (?ignore=traefik&ignore=nginx or ?ignore=traefik,nginx)
+func splitListParam(values []string) []string {
+	var result []string
+	for _, param := range values {
+		for part := range strings.SplitSeq(param, ",") {
+			if trimmed := strings.TrimSpace(part); trimmed != "" {
+				result = append(result, trimmed)
+			}
+		}
+	}
+	return result
+}
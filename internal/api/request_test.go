@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJobRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		body        string
+		wantTimeout int
+		wantIgnore  []string
+		wantTargets []string
+		wantDeps    bool
+		wantErr     string
+	}{
+		{
+			name:        "defaults",
+			wantTimeout: 600,
+			wantDeps:    true,
+		},
+		{
+			name:        "query parameters",
+			query:       "?timeout=120&ignore=traefik,nginx&ignore=autoheal&targets=plex",
+			wantTimeout: 120,
+			wantIgnore:  []string{"traefik", "nginx", "autoheal"},
+			wantTargets: []string{"plex"},
+			wantDeps:    true,
+		},
+		{
+			name:        "json body",
+			body:        `{"timeout": 300, "ignore": ["traefik"], "targets": ["sonarr"], "options": {"dependencies": false}}`,
+			wantTimeout: 300,
+			wantIgnore:  []string{"traefik"},
+			wantTargets: []string{"sonarr"},
+			wantDeps:    false,
+		},
+		{
+			name:        "body and query merged",
+			query:       "?ignore=nginx&timeout=300",
+			body:        `{"timeout": 300, "ignore": ["traefik"]}`,
+			wantTimeout: 300,
+			wantIgnore:  []string{"traefik", "nginx"},
+			wantDeps:    true,
+		},
+		{
+			name:    "non-numeric timeout",
+			query:   "?timeout=ten",
+			wantErr: "invalid timeout",
+		},
+		{
+			name:    "negative timeout",
+			body:    `{"timeout": -5}`,
+			wantErr: "must be between",
+		},
+		{
+			name:    "zero timeout",
+			query:   "?timeout=0",
+			wantErr: "must be between",
+		},
+		{
+			name:    "timeout too large",
+			body:    `{"timeout": 100000}`,
+			wantErr: "must be between",
+		},
+		{
+			name:    "conflicting timeouts",
+			query:   "?timeout=60",
+			body:    `{"timeout": 120}`,
+			wantErr: "both query",
+		},
+		{
+			name:    "unknown field",
+			body:    `{"timeout": 60, "ignroe": ["traefik"]}`,
+			wantErr: "unknown field",
+		},
+		{
+			name:    "unknown option",
+			body:    `{"options": {"force": true}}`,
+			wantErr: "unknown field",
+		},
+		{
+			name:    "malformed json",
+			body:    `{"timeout": `,
+			wantErr: "invalid request body",
+		},
+		{
+			name:    "trailing data",
+			body:    `{"timeout": 60} {}`,
+			wantErr: "unexpected data",
+		},
+		{
+			name:    "invalid container name",
+			body:    `{"targets": ["plex; rm -rf"]}`,
+			wantErr: "invalid container name",
+		},
+		{
+			name:        "group selectors",
+			query:       "?targets=group=downloaders&ignore=group:torrents",
+			body:        `{"targets": ["group:media"]}`,
+			wantTimeout: 600,
+			wantIgnore:  []string{"group:torrents"},
+			wantTargets: []string{"group:media", "group=downloaders"},
+			wantDeps:    true,
+		},
+		{
+			name:        "patterns",
+			body:        `{"targets": ["sonarr*"], "ignore": ["/radarr-.*/"]}`,
+			wantTimeout: 600,
+			wantIgnore:  []string{"/radarr-.*/"},
+			wantTargets: []string{"sonarr*"},
+			wantDeps:    true,
+		},
+		{
+			name:    "invalid pattern",
+			body:    `{"ignore": ["sonarr["]}`,
+			wantErr: "invalid container name or pattern in ignore",
+		},
+		{
+			name:    "invalid group name",
+			body:    `{"ignore": ["group=../media"]}`,
+			wantErr: "invalid container name or pattern in ignore",
+		},
+		{
+			name:    "invalid dependencies flag",
+			query:   "?dependencies=maybe",
+			wantErr: "invalid dependencies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/start"+tt.query, strings.NewReader(tt.body))
+
+			parsed, err := parseJobRequest(req, DefaultStartTimeout)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTimeout, *parsed.Timeout)
+			assert.Equal(t, tt.wantIgnore, parsed.Ignore)
+			assert.Equal(t, tt.wantTargets, parsed.Targets)
+			assert.Equal(t, tt.wantDeps, parsed.Options.IncludeDependencies())
+		})
+	}
+}
+
+func TestStartStop_RequestBody(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	router := NewServer(jobManager, log).Router()
+
+	t.Run("start honours ignore list from body", func(t *testing.T) {
+		body := `{"timeout": 120, "ignore": ["traefik"]}`
+		req := httptest.NewRequest("POST", "/start", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response JobResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+		job, err := jobManager.Get(response.JobID)
+		require.NoError(t, err)
+		assert.Equal(t, jobs.JobTypeStart, job.Type)
+		assert.Equal(t, 120, job.Timeout)
+		assert.Equal(t, []string{"traefik"}, job.Ignore)
+	})
+
+	t.Run("stop with targets", func(t *testing.T) {
+		body := `{"targets": ["plex"], "options": {"dependencies": false}}`
+		req := httptest.NewRequest("POST", "/stop", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response JobResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+		job, err := jobManager.Get(response.JobID)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultStopTimeout, job.Timeout)
+		assert.Equal(t, []string{"plex"}, job.Targets)
+		assert.False(t, job.IncludeDependencies)
+	})
+
+	t.Run("invalid timeout rejected", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/stop?timeout=abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response ErrorResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Contains(t, response.Error, "invalid timeout")
+	})
+}
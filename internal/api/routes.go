@@ -0,0 +1,353 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/saltyorg/sdc/internal/blocks"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/internal/lint"
+	"github.com/saltyorg/sdc/internal/schedule"
+)
+
+// APIPrefix is the path prefix of the versioned API
+const APIPrefix = "/api/v1"
+
+// route describes an API endpoint. The same table registers the handlers
+// and generates the OpenAPI document, so the two cannot drift apart.
+type route struct {
+	method      string
+	path        string // chi pattern relative to APIPrefix
+	scope       Scope
+	handler     http.HandlerFunc
+	operationID string
+	summary     string
+	params      []param
+	request     any // Example value of the JSON request body type, if any
+	responses   map[int]response
+}
+
+// param describes a path or query parameter
+type param struct {
+	name        string
+	in          string // "path", "query" or "header"
+	example     any    // Value of the parameter's Go type
+	description string
+}
+
+// response describes one documented response of a route
+type response struct {
+	description string
+	body        any // Example value of the JSON body type
+}
+
+// jobRequestParams are the query parameters accepted by /start and /stop
+var jobRequestParams = []param{
+	{name: "timeout", in: "query", example: 0, description: "Job timeout in seconds; must match the body timeout if both are given"},
+	{name: "ignore", in: "query", example: []string{}, description: "Containers to skip, comma-separated or repeated"},
+	{name: "targets", in: "query", example: []string{}, description: "Containers to operate on, comma-separated or repeated"},
+	{name: "dependencies", in: "query", example: false, description: "Include dependencies (start) or dependents (stop) of targets"},
+	{name: "supersede", in: "query", example: false, description: "Cancel pending jobs of the opposite operation on overlapping containers"},
+}
+
+var jobIDParam = param{name: "job_id", in: "path", example: "", description: "Job ID"}
+
+var scheduleIDParam = param{name: "schedule_id", in: "path", example: "", description: "Schedule ID"}
+
+// idempotencyKeyParam is accepted by every mutating route
+var idempotencyKeyParam = param{
+	name:        IdempotencyKeyHeader,
+	in:          "header",
+	example:     "",
+	description: "Client-chosen key; retries with the same key and parameters return the original response",
+}
+
+// mutating reports whether the route changes server state
+func (rt route) mutating() bool {
+	return rt.method != http.MethodGet && rt.method != http.MethodHead
+}
+
+// routes returns the API route table
+func (s *Server) routes() []route {
+	return []route{
+		{
+			method:      http.MethodGet,
+			path:        "/ping",
+			scope:       ScopeRead,
+			handler:     s.HandleHealth,
+			operationID: "ping",
+			summary:     "Health check",
+			responses: map[int]response{
+				http.StatusOK: {"Server is up", StatusResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/healthz",
+			scope:       ScopeRead,
+			handler:     s.HandleLiveness,
+			operationID: "liveness",
+			summary:     "Liveness check",
+			responses: map[int]response{
+				http.StatusOK: {"Process is alive", StatusResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/readyz",
+			scope:       ScopeRead,
+			handler:     s.HandleReadiness,
+			operationID: "readiness",
+			summary:     "Readiness check with per-component detail",
+			responses: map[int]response{
+				http.StatusOK:                 {"Ready", ReadinessResponse{}},
+				http.StatusServiceUnavailable: {"At least one check failed", ReadinessResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/openapi.json",
+			scope:       ScopeRead,
+			handler:     s.HandleOpenAPI,
+			operationID: "getOpenAPI",
+			summary:     "This OpenAPI document",
+			responses: map[int]response{
+				http.StatusOK: {"OpenAPI 3 document", map[string]any{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/jobs",
+			scope:       ScopeRead,
+			handler:     s.HandleListJobs,
+			operationID: "listJobs",
+			summary:     "List jobs, newest first",
+			params: []param{
+				{name: "status", in: "query", example: jobs.JobStatus(""), description: "Only jobs with this status"},
+				{name: "type", in: "query", example: jobs.JobType(""), description: "Only jobs of this type"},
+				{name: "limit", in: "query", example: 0, description: "Maximum number of jobs (default 50, 0 for all)"},
+			},
+			responses: map[int]response{
+				http.StatusOK:         {"Jobs", JobsResponse{}},
+				http.StatusBadRequest: {"Invalid limit", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/graph",
+			scope:       ScopeRead,
+			handler:     s.HandleGetGraph,
+			operationID: "getGraph",
+			summary:     "Get the container dependency graph and startup batches",
+			responses: map[int]response{
+				http.StatusOK:                 {"Dependency graph", GraphResponse{}},
+				http.StatusServiceUnavailable: {"No graph provider configured", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/containers",
+			scope:       ScopeRead,
+			handler:     s.HandleListContainers,
+			operationID: "listContainers",
+			summary:     "List labelled containers with their parsed labels, state and dependencies",
+			params: []param{
+				{name: "state", in: "query", example: "", description: "Only containers in this state (running, exited, ..., or missing)"},
+				{name: "name", in: "query", example: "", description: "Only containers whose name contains this text"},
+				{name: "managed", in: "query", example: false, description: "Only managed (true) or unmanaged (false) containers"},
+			},
+			responses: map[int]response{
+				http.StatusOK:                 {"Containers", ContainersResponse{}},
+				http.StatusBadRequest:         {"Invalid filter", ErrorResponse{}},
+				http.StatusServiceUnavailable: {"No inventory provider configured", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/lint",
+			scope:       ScopeRead,
+			handler:     s.HandleLint,
+			operationID: "lint",
+			summary:     "Check container labels and dependencies for mistakes",
+			responses: map[int]response{
+				http.StatusOK:                 {"Lint report", lint.Report{}},
+				http.StatusServiceUnavailable: {"No lint provider configured", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/job_status/{job_id}",
+			scope:       ScopeRead,
+			handler:     s.HandleGetJobStatus,
+			operationID: "getJob",
+			summary:     "Get job status and results",
+			params:      []param{jobIDParam},
+			responses: map[int]response{
+				http.StatusOK:       {"Job", jobs.Job{}},
+				http.StatusNotFound: {"Unknown job", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/job/{job_id}/logs",
+			scope:       ScopeRead,
+			handler:     s.HandleGetJobLogs,
+			operationID: "getJobLogs",
+			summary:     "Get log lines captured while the job ran",
+			params:      []param{jobIDParam},
+			responses: map[int]response{
+				http.StatusOK:       {"Captured log lines", JobLogsResponse{}},
+				http.StatusNotFound: {"Unknown job", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/start",
+			scope:       ScopeWrite,
+			handler:     s.HandleStartContainers,
+			operationID: "startContainers",
+			summary:     "Start containers in dependency order",
+			params:      jobRequestParams,
+			request:     JobRequest{},
+			responses: map[int]response{
+				http.StatusOK:                 {"Job submitted", JobResponse{}},
+				http.StatusBadRequest:         {"Invalid request", ErrorResponse{}},
+				http.StatusServiceUnavailable: {"Operations are blocked or the job queue is full", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/stop",
+			scope:       ScopeWrite,
+			handler:     s.HandleStopContainers,
+			operationID: "stopContainers",
+			summary:     "Stop containers in reverse dependency order",
+			params:      jobRequestParams,
+			request:     JobRequest{},
+			responses: map[int]response{
+				http.StatusOK:                 {"Job submitted", JobResponse{}},
+				http.StatusBadRequest:         {"Invalid request", ErrorResponse{}},
+				http.StatusServiceUnavailable: {"Operations are blocked or the job queue is full", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/blocks",
+			scope:       ScopeRead,
+			handler:     s.HandleListBlocks,
+			operationID: "listBlocks",
+			summary:     "List active blocks",
+			responses: map[int]response{
+				http.StatusOK: {"Active blocks, oldest first", BlocksResponse{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/blocks",
+			scope:       ScopeWrite,
+			handler:     s.HandleCreateBlock,
+			operationID: "createBlock",
+			summary:     "Block operations globally, per operation or for named containers",
+			request:     CreateBlockRequest{},
+			responses: map[int]response{
+				http.StatusCreated:    {"Block created", blocks.Block{}},
+				http.StatusBadRequest: {"Invalid request", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodDelete,
+			path:        "/blocks/{block_id}",
+			scope:       ScopeWrite,
+			handler:     s.HandleDeleteBlock,
+			operationID: "deleteBlock",
+			summary:     "Remove a block",
+			params: []param{
+				{name: "block_id", in: "path", example: "", description: "Block ID"},
+			},
+			responses: map[int]response{
+				http.StatusOK:       {"Block removed", MessageResponse{}},
+				http.StatusNotFound: {"Unknown block", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/block/{duration}",
+			scope:       ScopeWrite,
+			handler:     s.HandleBlock,
+			operationID: "block",
+			summary:     "Replace the global block with one lasting the given minutes",
+			params: []param{
+				{name: "duration", in: "path", example: 0, description: "Block duration in minutes"},
+			},
+			responses: map[int]response{
+				http.StatusOK: {"Operations blocked", MessageResponse{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/unblock",
+			scope:       ScopeWrite,
+			handler:     s.HandleUnblock,
+			operationID: "unblock",
+			summary:     "Remove all global blocks",
+			responses: map[int]response{
+				http.StatusOK: {"Operations unblocked", MessageResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/schedules",
+			scope:       ScopeRead,
+			handler:     s.HandleListSchedules,
+			operationID: "listSchedules",
+			summary:     "List schedules with their next run and recent runs",
+			responses: map[int]response{
+				http.StatusOK:                 {"Schedules, oldest first", SchedulesResponse{}},
+				http.StatusServiceUnavailable: {"No scheduler configured", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/schedules",
+			scope:       ScopeWrite,
+			handler:     s.HandleCreateSchedule,
+			operationID: "createSchedule",
+			summary:     "Start, stop or restart containers on a cron schedule",
+			request:     CreateScheduleRequest{},
+			responses: map[int]response{
+				http.StatusCreated:            {"Schedule created", schedule.Schedule{}},
+				http.StatusBadRequest:         {"Invalid request", ErrorResponse{}},
+				http.StatusConflict:           {"Schedule name already in use", ErrorResponse{}},
+				http.StatusServiceUnavailable: {"No scheduler configured", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/schedules/{schedule_id}",
+			scope:       ScopeRead,
+			handler:     s.HandleGetSchedule,
+			operationID: "getSchedule",
+			summary:     "Get a schedule with its next run and recent runs",
+			params:      []param{scheduleIDParam},
+			responses: map[int]response{
+				http.StatusOK:                 {"Schedule", schedule.Schedule{}},
+				http.StatusNotFound:           {"Unknown schedule", ErrorResponse{}},
+				http.StatusServiceUnavailable: {"No scheduler configured", ErrorResponse{}},
+			},
+		},
+		{
+			method:      http.MethodDelete,
+			path:        "/schedules/{schedule_id}",
+			scope:       ScopeWrite,
+			handler:     s.HandleDeleteSchedule,
+			operationID: "deleteSchedule",
+			summary:     "Remove a schedule created through the API",
+			params:      []param{scheduleIDParam},
+			responses: map[int]response{
+				http.StatusOK:                 {"Schedule removed", MessageResponse{}},
+				http.StatusNotFound:           {"Unknown schedule", ErrorResponse{}},
+				http.StatusConflict:           {"Schedule is defined in the schedule file", ErrorResponse{}},
+				http.StatusServiceUnavailable: {"No scheduler configured", ErrorResponse{}},
+			},
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/saltyorg/sdc/internal/schedule"
+)
+
+// ScheduleProvider registers schedules that submit jobs when they fire
+type ScheduleProvider interface {
+	List() []schedule.Schedule
+	Get(id string) (*schedule.Schedule, bool)
+	Add(def schedule.Definition) (*schedule.Schedule, error)
+	Remove(id string) error
+}
+
+// SetScheduleProvider enables the schedule endpoints
+func (s *Server) SetScheduleProvider(provider ScheduleProvider) {
+	s.schedules = provider
+}
+
+// CreateScheduleRequest is the body of POST /schedules and an entry of the
+// schedule file
+type CreateScheduleRequest struct {
+	Name         string             `json:"name,omitempty"`
+	Cron         string             `json:"cron"`              // Five-field expression or @daily, @hourly, ...
+	Operation    schedule.Operation `json:"operation"`         // start, stop or restart
+	Targets      []string           `json:"targets,omitempty"` // Containers, patterns or "group=<name>" selectors (empty = all)
+	Ignore       []string           `json:"ignore,omitempty"`
+	Timeout      *int               `json:"timeout,omitempty"`      // Seconds per job; defaults per operation
+	Dependencies *bool              `json:"dependencies,omitempty"` // Defaults to true
+}
+
+// SchedulesResponse is returned by GET /schedules
+type SchedulesResponse struct {
+	Schedules []schedule.Schedule `json:"schedules"`
+}
+
+// Definition validates the request and converts it to a schedule definition.
+// A restart defaults to the start timeout for both of its jobs.
+func (req *CreateScheduleRequest) Definition(source schedule.Source) (schedule.Definition, error) {
+	if req.Name != "" && !containerNamePattern.MatchString(req.Name) {
+		return schedule.Definition{}, badRequest("invalid schedule name %q", req.Name)
+	}
+	if req.Cron == "" {
+		return schedule.Definition{}, badRequest("cron is required")
+	}
+	if _, err := schedule.ParseCron(req.Cron); err != nil {
+		return schedule.Definition{}, badRequest("%v", err)
+	}
+
+	timeout := DefaultStartTimeout
+	switch req.Operation {
+	case schedule.OperationStart, schedule.OperationRestart:
+	case schedule.OperationStop:
+		timeout = DefaultStopTimeout
+	default:
+		return schedule.Definition{}, badRequest("invalid operation %q: must be start, stop or restart", req.Operation)
+	}
+	if req.Timeout != nil {
+		timeout = *req.Timeout
+	}
+
+	job := JobRequest{Timeout: &timeout, Ignore: req.Ignore, Targets: req.Targets}
+	if err := job.Validate(); err != nil {
+		return schedule.Definition{}, err
+	}
+
+	return schedule.Definition{
+		Name:                req.Name,
+		Cron:                req.Cron,
+		Operation:           req.Operation,
+		Targets:             req.Targets,
+		Ignore:              req.Ignore,
+		Timeout:             timeout,
+		IncludeDependencies: JobOptions{Dependencies: req.Dependencies}.IncludeDependencies(),
+		Source:              source,
+	}, nil
+}
+
+// HandleListSchedules handles GET /schedules
+func (s *Server) HandleListSchedules(w http.ResponseWriter, r *http.Request) {
+	if s.schedules == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Schedules are not available")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, SchedulesResponse{Schedules: s.schedules.List()})
+}
+
+// HandleGetSchedule handles GET /schedules/{schedule_id}
+func (s *Server) HandleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	if s.schedules == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Schedules are not available")
+		return
+	}
+
+	id := chi.URLParam(r, "schedule_id")
+	sched, ok := s.schedules.Get(id)
+	if !ok {
+		s.writeError(w, http.StatusNotFound, ErrorCodeNotFound, "Schedule not found: "+id)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, sched)
+}
+
+// HandleCreateSchedule handles POST /schedules
+func (s *Server) HandleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	if s.schedules == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Schedules are not available")
+		return
+	}
+
+	var req CreateScheduleRequest
+	if err := decodeJSONBody(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
+		return
+	}
+
+	def, err := req.Definition(schedule.SourceAPI)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
+		return
+	}
+
+	sched, err := s.schedules.Add(def)
+	if err != nil {
+		if errors.Is(err, schedule.ErrDuplicateName) {
+			s.writeError(w, http.StatusConflict, ErrorCodeConflict, err.Error())
+			return
+		}
+		s.writeError(w, http.StatusBadRequest, ErrorCodeInvalidArgument, err.Error())
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, sched)
+}
+
+// HandleDeleteSchedule handles DELETE /schedules/{schedule_id}
+func (s *Server) HandleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if s.schedules == nil {
+		s.writeError(w, http.StatusServiceUnavailable, ErrorCodeUnavailable, "Schedules are not available")
+		return
+	}
+
+	id := chi.URLParam(r, "schedule_id")
+	if err := s.schedules.Remove(id); err != nil {
+		switch {
+		case errors.Is(err, schedule.ErrNotFound):
+			s.writeError(w, http.StatusNotFound, ErrorCodeNotFound, "Schedule not found: "+id)
+		case errors.Is(err, schedule.ErrReadOnly):
+			s.writeError(w, http.StatusConflict, ErrorCodeConflict, "Schedule "+id+" is defined in the schedule file and cannot be removed through the API")
+		default:
+			s.writeError(w, http.StatusInternalServerError, ErrorCodeInternal, err.Error())
+		}
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, MessageResponse{Message: "Schedule " + id + " removed"})
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/internal/schedule"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulesEndpoints(t *testing.T) {
+	log, _ := logger.New(false)
+	jobManager := jobs.NewManager(nil, log, 1)
+	defer jobManager.Shutdown(1 * time.Second)
+
+	server := NewServer(jobManager, log)
+	scheduler := schedule.NewScheduler(jobManager, server.Blocks(), log)
+	defer scheduler.Stop()
+	server.SetScheduleProvider(scheduler)
+
+	router := server.Router()
+	doc := server.OpenAPI()
+
+	do := func(method, path, template, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Every response matches its documented schema
+		op := doc.Paths[template][strings.ToLower(method)]
+		resp, ok := op.Responses[strconv.Itoa(w.Code)]
+		require.True(t, ok, "%s %s: status %d not documented", method, template, w.Code)
+		var decoded any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+		for _, problem := range validateSchema(doc, resp.Content["application/json"].Schema, decoded, "body") {
+			t.Error(problem)
+		}
+		return w
+	}
+
+	w := do("POST", "/schedules", "/schedules", `{"name": "nightly", "cron": "30 4 * * *", "operation": "restart", "targets": ["group=media"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created schedule.Schedule
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "nightly", created.Name)
+	assert.Equal(t, schedule.OperationRestart, created.Operation)
+	assert.Equal(t, []string{"group=media"}, created.Targets)
+	assert.Equal(t, DefaultStartTimeout, created.Timeout)
+	assert.True(t, created.IncludeDependencies)
+	assert.Equal(t, schedule.SourceAPI, created.Source)
+	assert.Equal(t, 4, created.NextRun.Hour())
+	assert.Equal(t, 30, created.NextRun.Minute())
+
+	w = do("POST", "/schedules", "/schedules", `{"cron": "@daily", "operation": "stop", "dependencies": false}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var stop schedule.Schedule
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&stop))
+	assert.Equal(t, DefaultStopTimeout, stop.Timeout)
+	assert.False(t, stop.IncludeDependencies)
+
+	invalid := []string{
+		`{"operation": "start"}`,
+		`{"cron": "61 * * * *", "operation": "start"}`,
+		`{"cron": "@daily", "operation": "reload"}`,
+		`{"cron": "@daily", "operation": "start", "timeout": 0}`,
+		`{"cron": "@daily", "operation": "start", "targets": ["bad name"]}`,
+		`{"cron": "@daily", "operation": "start", "name": "bad name"}`,
+		`{"cron": "@daily", "operation": "start", "bogus": 1}`,
+	}
+	for _, body := range invalid {
+		w = do("POST", "/schedules", "/schedules", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = do("POST", "/schedules", "/schedules", `{"name": "nightly", "cron": "@daily", "operation": "start"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = do("GET", "/schedules", "/schedules", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list SchedulesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	require.Len(t, list.Schedules, 2)
+	assert.Equal(t, created.ID, list.Schedules[0].ID)
+
+	w = do("GET", "/schedules/"+created.ID, "/schedules/{schedule_id}", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = do("GET", "/schedules/missing", "/schedules/{schedule_id}", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Schedules from the schedule file cannot be removed through the API
+	config, err := scheduler.Add(schedule.Definition{Cron: "@weekly", Operation: schedule.OperationStart, Timeout: 600, Source: schedule.SourceConfig})
+	require.NoError(t, err)
+	w = do("DELETE", "/schedules/"+config.ID, "/schedules/{schedule_id}", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = do("DELETE", "/schedules/"+created.ID, "/schedules/{schedule_id}", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = do("DELETE", "/schedules/"+created.ID, "/schedules/{schedule_id}", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSchedulesEndpoints_Unavailable(t *testing.T) {
+	server, _ := newOpenAPITestServer(t)
+	router := server.Router()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/schedules", nil),
+		httptest.NewRequest("POST", "/schedules", strings.NewReader(`{"cron": "@daily", "operation": "start"}`)),
+		httptest.NewRequest("GET", "/schedules/x", nil),
+		httptest.NewRequest("DELETE", "/schedules/x", nil),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
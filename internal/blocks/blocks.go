@@ -0,0 +1,269 @@
+package blocks
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saltyorg/sdc/pkg/logger"
+)
+
+const (
+	// DefaultDuration is how long a block lasts when no duration is given
+	DefaultDuration = 10 * time.Minute
+
+	// MaxDuration is the longest accepted block duration
+	MaxDuration = 7 * 24 * time.Hour
+)
+
+// Operation is a container operation that can be blocked
+type Operation string
+
+const (
+	OperationStart Operation = "start"
+	OperationStop  Operation = "stop"
+)
+
+// Scope determines which operations and containers a block applies to
+type Scope string
+
+const (
+	ScopeGlobal     Scope = "global"     // All operations on all containers
+	ScopeStart      Scope = "start"      // Start operations on all containers
+	ScopeStop       Scope = "stop"       // Stop operations on all containers
+	ScopeContainers Scope = "containers" // All operations on the named containers
+)
+
+// Scopes lists all block scopes
+var Scopes = []Scope{ScopeGlobal, ScopeStart, ScopeStop, ScopeContainers}
+
+// Block prevents operations on containers until it expires or is removed
+type Block struct {
+	ID         string    `json:"id"`
+	Scope      Scope     `json:"scope"`
+	Containers []string  `json:"containers,omitempty"` // Only for ScopeContainers
+	Reason     string    `json:"reason,omitempty"`
+	Creator    string    `json:"creator,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Validate checks the scope and container list
+func (b *Block) Validate() error {
+	if !slices.Contains(Scopes, b.Scope) {
+		return fmt.Errorf("unknown block scope: %q", b.Scope)
+	}
+	if b.Scope == ScopeContainers && len(b.Containers) == 0 {
+		return fmt.Errorf("scope %q requires at least one container", ScopeContainers)
+	}
+	if b.Scope != ScopeContainers && len(b.Containers) > 0 {
+		return fmt.Errorf("containers can only be given with scope %q", ScopeContainers)
+	}
+	return nil
+}
+
+// CoversOperation reports whether the block applies to every container for op
+func (b *Block) CoversOperation(op Operation) bool {
+	switch b.Scope {
+	case ScopeGlobal:
+		return true
+	case ScopeStart:
+		return op == OperationStart
+	case ScopeStop:
+		return op == OperationStop
+	default:
+		return false
+	}
+}
+
+// Applies reports whether the block prevents op on the container
+func (b *Block) Applies(op Operation, container string) bool {
+	if b.Scope == ScopeContainers {
+		return slices.Contains(b.Containers, container)
+	}
+	return b.CoversOperation(op)
+}
+
+// Expired reports whether the block has expired at the given time
+func (b *Block) Expired(now time.Time) bool {
+	return !now.Before(b.ExpiresAt)
+}
+
+// Registry holds the active blocks and removes them once they expire
+type Registry struct {
+	mu        sync.RWMutex
+	blocks    map[string]*Block
+	timers    map[string]*time.Timer
+	logger    *logger.Logger
+	stateFile string // Empty = blocks are kept in memory only
+}
+
+// NewRegistry creates an empty block registry
+func NewRegistry(logger *logger.Logger) *Registry {
+	return &Registry{
+		blocks: make(map[string]*Block),
+		timers: make(map[string]*time.Timer),
+		logger: logger,
+	}
+}
+
+// Add validates and registers a block lasting duration (DefaultDuration if zero).
+// The ID and timestamps are assigned by the registry.
+func (r *Registry) Add(block Block, duration time.Duration) (*Block, error) {
+	if duration == 0 {
+		duration = DefaultDuration
+	}
+	if duration < 0 || duration > MaxDuration {
+		return nil, fmt.Errorf("invalid block duration %s: must be positive and at most %s", duration, MaxDuration)
+	}
+	if err := block.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	block.ID = uuid.New().String()
+	block.CreatedAt = now
+	block.ExpiresAt = now.Add(duration)
+	block.Containers = slices.Clone(block.Containers)
+
+	r.mu.Lock()
+	r.blocks[block.ID] = &block
+	r.armTimer(block.ID, duration)
+	r.save()
+	r.mu.Unlock()
+
+	r.logger.Info("Block added",
+		"block_id", block.ID,
+		"scope", string(block.Scope),
+		"containers", block.Containers,
+		"reason", block.Reason,
+		"creator", block.Creator,
+		"expires_at", block.ExpiresAt.Format(time.RFC3339))
+
+	result := block
+	return &result, nil
+}
+
+// armTimer schedules removal of the block. Caller must hold r.mu.
+func (r *Registry) armTimer(id string, after time.Duration) {
+	r.timers[id] = time.AfterFunc(after, func() {
+		if r.remove(id) {
+			r.logger.Info("Block expired", "block_id", id)
+		}
+	})
+}
+
+// Remove deletes a block by ID and reports whether it existed
+func (r *Registry) Remove(id string) bool {
+	if !r.remove(id) {
+		return false
+	}
+	r.logger.Info("Block removed", "block_id", id)
+	return true
+}
+
+// RemoveScope deletes all blocks with the given scope and returns how many were removed
+func (r *Registry) RemoveScope(scope Scope) int {
+	r.mu.RLock()
+	var ids []string
+	for id, block := range r.blocks {
+		if block.Scope == scope {
+			ids = append(ids, id)
+		}
+	}
+	r.mu.RUnlock()
+
+	removed := 0
+	for _, id := range ids {
+		if r.Remove(id) {
+			removed++
+		}
+	}
+	return removed
+}
+
+func (r *Registry) remove(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.blocks[id]; !exists {
+		return false
+	}
+
+	if timer, ok := r.timers[id]; ok {
+		timer.Stop()
+		delete(r.timers, id)
+	}
+	delete(r.blocks, id)
+	r.save()
+	return true
+}
+
+// Get returns a copy of the block with the given ID
+func (r *Registry) Get(id string) (*Block, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	block, exists := r.blocks[id]
+	if !exists || block.Expired(time.Now()) {
+		return nil, false
+	}
+	result := *block
+	return &result, true
+}
+
+// List returns copies of all active blocks, oldest first
+func (r *Registry) List() []Block {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	result := make([]Block, 0, len(r.blocks))
+	for _, block := range r.blocks {
+		if !block.Expired(now) {
+			result = append(result, *block)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// BlockingOperation returns the oldest active block that covers op for all containers
+func (r *Registry) BlockingOperation(op Operation) (*Block, bool) {
+	for _, block := range r.List() {
+		if block.CoversOperation(op) {
+			return &block, true
+		}
+	}
+	return nil, false
+}
+
+// Blocking returns the oldest active block preventing op on the container
+func (r *Registry) Blocking(op Operation, container string) (*Block, bool) {
+	for _, block := range r.List() {
+		if block.Applies(op, container) {
+			return &block, true
+		}
+	}
+	return nil, false
+}
+
+// Close stops all expiry timers. Blocks remain readable.
+func (r *Registry) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, timer := range r.timers {
+		timer.Stop()
+		delete(r.timers, id)
+	}
+}
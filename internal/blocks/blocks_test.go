@@ -0,0 +1,113 @@
+package blocks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlock_Applies(t *testing.T) {
+	tests := []struct {
+		name      string
+		block     Block
+		op        Operation
+		container string
+		want      bool
+	}{
+		{"global blocks start", Block{Scope: ScopeGlobal}, OperationStart, "plex", true},
+		{"global blocks stop", Block{Scope: ScopeGlobal}, OperationStop, "plex", true},
+		{"start-only blocks start", Block{Scope: ScopeStart}, OperationStart, "plex", true},
+		{"start-only allows stop", Block{Scope: ScopeStart}, OperationStop, "plex", false},
+		{"stop-only blocks stop", Block{Scope: ScopeStop}, OperationStop, "plex", true},
+		{"stop-only allows start", Block{Scope: ScopeStop}, OperationStart, "plex", false},
+		{"containers blocks named", Block{Scope: ScopeContainers, Containers: []string{"plex"}}, OperationStart, "plex", true},
+		{"containers allows others", Block{Scope: ScopeContainers, Containers: []string{"plex"}}, OperationStop, "sonarr", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.block.Applies(tt.op, tt.container))
+		})
+	}
+
+	assert.False(t, (&Block{Scope: ScopeContainers, Containers: []string{"plex"}}).CoversOperation(OperationStart))
+}
+
+func TestBlock_Validate(t *testing.T) {
+	assert.NoError(t, (&Block{Scope: ScopeGlobal}).Validate())
+	assert.NoError(t, (&Block{Scope: ScopeContainers, Containers: []string{"plex"}}).Validate())
+	assert.Error(t, (&Block{Scope: "sometimes"}).Validate())
+	assert.Error(t, (&Block{Scope: ScopeContainers}).Validate())
+	assert.Error(t, (&Block{Scope: ScopeStop, Containers: []string{"plex"}}).Validate())
+}
+
+func TestRegistry(t *testing.T) {
+	log, _ := logger.New(false)
+	registry := NewRegistry(log)
+	defer registry.Close()
+
+	global, err := registry.Add(Block{Scope: ScopeGlobal, Reason: "maintenance", Creator: "admin"}, 0)
+	require.NoError(t, err)
+	assert.NotEmpty(t, global.ID)
+	assert.WithinDuration(t, time.Now().Add(DefaultDuration), global.ExpiresAt, time.Second)
+
+	plex, err := registry.Add(Block{Scope: ScopeContainers, Containers: []string{"plex"}}, time.Hour)
+	require.NoError(t, err)
+
+	_, err = registry.Add(Block{Scope: ScopeGlobal}, MaxDuration+time.Second)
+	assert.Error(t, err)
+
+	list := registry.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, global.ID, list[0].ID)
+	assert.Equal(t, plex.ID, list[1].ID)
+
+	// The oldest matching block is reported
+	block, ok := registry.Blocking(OperationStart, "plex")
+	require.True(t, ok)
+	assert.Equal(t, global.ID, block.ID)
+
+	assert.Equal(t, 1, registry.RemoveScope(ScopeGlobal))
+
+	block, ok = registry.Blocking(OperationStart, "plex")
+	require.True(t, ok)
+	assert.Equal(t, plex.ID, block.ID)
+
+	_, ok = registry.BlockingOperation(OperationStart)
+	assert.False(t, ok)
+
+	got, ok := registry.Get(plex.ID)
+	require.True(t, ok)
+	assert.Equal(t, []string{"plex"}, got.Containers)
+
+	assert.True(t, registry.Remove(plex.ID))
+	assert.False(t, registry.Remove(plex.ID))
+	assert.Empty(t, registry.List())
+}
+
+func TestRegistry_Expiry(t *testing.T) {
+	log, _ := logger.New(false)
+	registry := NewRegistry(log)
+	defer registry.Close()
+
+	_, err := registry.Add(Block{Scope: ScopeStart}, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	_, ok := registry.BlockingOperation(OperationStart)
+	assert.True(t, ok)
+
+	assert.Eventually(t, func() bool {
+		_, ok := registry.BlockingOperation(OperationStart)
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	// The expiry timer removes the block entirely
+	assert.Eventually(t, func() bool {
+		registry.mu.RLock()
+		defer registry.mu.RUnlock()
+		return len(registry.blocks) == 0 && len(registry.timers) == 0
+	}, time.Second, 10*time.Millisecond)
+}
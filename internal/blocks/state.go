@@ -0,0 +1,125 @@
+package blocks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/statefile"
+)
+
+// stateVersion is the format version of the state file
+const stateVersion = 1
+
+// state is the on-disk representation of the active blocks
+type state struct {
+	Version int     `json:"version"`
+	Blocks  []Block `json:"blocks"`
+}
+
+// Persist restores blocks from the state file and keeps the file up to date
+// from then on. Blocks that expired while the controller was down are logged
+// as lapsed and dropped; the others are re-armed with their original expiry.
+// A missing state file is not an error.
+func (r *Registry) Persist(path string) error {
+	restored, err := readState(path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stateFile = path
+
+	now := time.Now()
+	for _, block := range restored {
+		if block.Expired(now) {
+			r.logger.Info("Block lapsed while controller was down",
+				"block_id", block.ID,
+				"scope", string(block.Scope),
+				"containers", block.Containers,
+				"reason", block.Reason,
+				"expired_at", block.ExpiresAt.Format(time.RFC3339))
+			continue
+		}
+
+		if _, exists := r.blocks[block.ID]; exists {
+			continue
+		}
+
+		b := block
+		r.blocks[b.ID] = &b
+		r.armTimer(b.ID, b.ExpiresAt.Sub(now))
+
+		r.logger.Info("Block restored",
+			"block_id", b.ID,
+			"scope", string(b.Scope),
+			"containers", b.Containers,
+			"reason", b.Reason,
+			"expires_at", b.ExpiresAt.Format(time.RFC3339))
+	}
+
+	// Write back immediately so lapsed blocks are dropped and the file is known to be writable
+	if err := r.writeState(); err != nil {
+		r.stateFile = ""
+		return err
+	}
+
+	return nil
+}
+
+// readState loads blocks from the state file
+func readState(path string) ([]Block, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block state: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse block state %s: %w", path, err)
+	}
+	if st.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported block state version %d in %s", st.Version, path)
+	}
+
+	for _, block := range st.Blocks {
+		if err := block.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid block %s in %s: %w", block.ID, path, err)
+		}
+	}
+
+	return st.Blocks, nil
+}
+
+// save writes the state file, logging failures. Caller must hold r.mu.
+func (r *Registry) save() {
+	if err := r.writeState(); err != nil {
+		r.logger.Error("Failed to save block state",
+			"path", r.stateFile,
+			"error", err)
+	}
+}
+
+// writeState atomically replaces the state file. Caller must hold r.mu.
+func (r *Registry) writeState() error {
+	if r.stateFile == "" {
+		return nil
+	}
+
+	st := state{Version: stateVersion, Blocks: make([]Block, 0, len(r.blocks))}
+	for _, block := range r.blocks {
+		st.Blocks = append(st.Blocks, *block)
+	}
+
+	if err := statefile.WriteJSON(r.stateFile, st); err != nil {
+		return fmt.Errorf("failed to save block state: %w", err)
+	}
+	return nil
+}
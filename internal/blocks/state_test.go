@@ -0,0 +1,143 @@
+package blocks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersist_RoundTrip(t *testing.T) {
+	log, _ := logger.New(false)
+	path := filepath.Join(t.TempDir(), "state", "blocks.json")
+
+	registry := NewRegistry(log)
+	require.NoError(t, registry.Persist(path))
+
+	block, err := registry.Add(Block{Scope: ScopeContainers, Containers: []string{"plex"}, Reason: "backup"}, time.Hour)
+	require.NoError(t, err)
+	registry.Close()
+
+	// A new registry (controller restart) picks the block up with its original expiry
+	restarted := NewRegistry(log)
+	defer restarted.Close()
+	require.NoError(t, restarted.Persist(path))
+
+	restored, ok := restarted.Get(block.ID)
+	require.True(t, ok)
+	assert.Equal(t, "backup", restored.Reason)
+	assert.Equal(t, []string{"plex"}, restored.Containers)
+	assert.True(t, block.ExpiresAt.Equal(restored.ExpiresAt))
+
+	// Removals are persisted too
+	assert.True(t, restarted.Remove(block.ID))
+
+	st := readStateFile(t, path)
+	assert.Empty(t, st.Blocks)
+}
+
+func TestPersist_RearmsExpiry(t *testing.T) {
+	log, _ := logger.New(false)
+	path := filepath.Join(t.TempDir(), "blocks.json")
+
+	writeStateFile(t, path, state{Version: stateVersion, Blocks: []Block{{
+		ID:        "short",
+		Scope:     ScopeGlobal,
+		CreatedAt: time.Now().Add(-time.Minute),
+		ExpiresAt: time.Now().Add(100 * time.Millisecond),
+	}}})
+
+	registry := NewRegistry(log)
+	defer registry.Close()
+	require.NoError(t, registry.Persist(path))
+
+	_, ok := registry.BlockingOperation(OperationStart)
+	require.True(t, ok)
+
+	assert.Eventually(t, func() bool {
+		return len(readStateFile(t, path).Blocks) == 0
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestPersist_LapsedBlocks(t *testing.T) {
+	buf := logger.NewBuffer(10)
+	base, _ := logger.New(false)
+	log := base.WithCapture(buf)
+
+	path := filepath.Join(t.TempDir(), "blocks.json")
+	writeStateFile(t, path, state{Version: stateVersion, Blocks: []Block{
+		{
+			ID:        "lapsed",
+			Scope:     ScopeGlobal,
+			Reason:    "backup",
+			CreatedAt: time.Now().Add(-2 * time.Hour),
+			ExpiresAt: time.Now().Add(-time.Hour),
+		},
+		{
+			ID:        "active",
+			Scope:     ScopeStart,
+			CreatedAt: time.Now().Add(-time.Hour),
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}})
+
+	registry := NewRegistry(log)
+	defer registry.Close()
+	require.NoError(t, registry.Persist(path))
+
+	list := registry.List()
+	require.Len(t, list, 1)
+	assert.Equal(t, "active", list[0].ID)
+
+	var lapsed []string
+	for _, entry := range buf.Entries() {
+		if entry.Message == "Block lapsed while controller was down" {
+			lapsed = append(lapsed, entry.Fields["block_id"].(string))
+		}
+	}
+	assert.Equal(t, []string{"lapsed"}, lapsed)
+
+	// The lapsed block is dropped from the file
+	st := readStateFile(t, path)
+	require.Len(t, st.Blocks, 1)
+	assert.Equal(t, "active", st.Blocks[0].ID)
+}
+
+func TestPersist_InvalidState(t *testing.T) {
+	log, _ := logger.New(false)
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("{not json"), 0600))
+	assert.Error(t, NewRegistry(log).Persist(corrupt))
+
+	future := filepath.Join(dir, "future.json")
+	writeStateFile(t, future, state{Version: stateVersion + 1})
+	assert.Error(t, NewRegistry(log).Persist(future))
+
+	invalid := filepath.Join(dir, "invalid.json")
+	writeStateFile(t, invalid, state{Version: stateVersion, Blocks: []Block{{ID: "x", Scope: ScopeContainers}}})
+	assert.Error(t, NewRegistry(log).Persist(invalid))
+}
+
+func writeStateFile(t *testing.T, path string, st state) {
+	t.Helper()
+	data, err := json.Marshal(st)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func readStateFile(t *testing.T, path string) state {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var st state
+	require.NoError(t, json.Unmarshal(data, &st))
+	return st
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Block prevents operations on containers until it expires or is removed
+type Block struct {
+	ID         string    `json:"id"`
+	Scope      string    `json:"scope"` // global, start, stop or containers
+	Containers []string  `json:"containers,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Creator    string    `json:"creator,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// CreateBlockRequest describes a block to create. Empty fields use server defaults.
+type CreateBlockRequest struct {
+	Scope      string   `json:"scope,omitempty"`
+	Containers []string `json:"containers,omitempty"`
+	Reason     string   `json:"reason,omitempty"`
+	Creator    string   `json:"creator,omitempty"`
+	Duration   string   `json:"duration,omitempty"` // Go duration such as "90m"
+}
+
+// ListBlocks returns the active blocks, oldest first
+func (c *Client) ListBlocks(ctx context.Context) ([]Block, error) {
+	var resp struct {
+		Blocks []Block `json:"blocks"`
+	}
+	if err := c.get(ctx, "/blocks", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Blocks, nil
+}
+
+// CreateBlock creates a block and returns it as stored by the server
+func (c *Client) CreateBlock(ctx context.Context, req CreateBlockRequest) (*Block, error) {
+	var block Block
+	if err := c.post(ctx, "/blocks", req, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// DeleteBlock removes a block by ID
+func (c *Client) DeleteBlock(ctx context.Context, id string) error {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/blocks/%s", url.PathEscape(id)), nil, &resp, true)
+}
+
+// Unblock removes all global blocks
+func (c *Client) Unblock(ctx context.Context) error {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	return c.post(ctx, "/unblock", nil, &resp)
+}
@@ -3,33 +3,103 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/saltyorg/sdc/pkg/logger"
 )
 
 // Client represents an HTTP client for communicating with the controller server
 type Client struct {
-	baseURL   string
+	baseURL    string
 	httpClient *http.Client
-	logger    *logger.Logger
-	userAgent string
+	logger     *logger.Logger
+	userAgent  string
+	token      string
+	transport  *http.Transport
+	socketPath string // Set when talking to the controller over a Unix socket
+	retry      RetryPolicy
 }
 
-// NewClient creates a new controller client
+// unixSocketHost is the placeholder host used in request URLs for Unix socket connections
+const unixSocketHost = "http://unix"
+
+// idempotencyKeyHeader lets the server recognize retried requests
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how requests are retried after connection failures
+// and server errors. The delay doubles after every attempt, with jitter.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts per request; 1 disables retries
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the delay
+}
+
+// DefaultRetryPolicy is used unless SetRetryPolicy is called
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// backoff returns the jittered delay before the retry following attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxBackoff)
+	if delay <= 0 {
+		return 0
+	}
+
+	// Pick from [delay/2, delay] so clients retrying together spread out
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// NewClient creates a new controller client.
+// baseURL is either an http(s):// URL or unix:///path/to/socket.
 func NewClient(baseURL string, logger *logger.Logger) *Client {
-	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	c := &Client{
+		baseURL:   baseURL,
 		logger:    logger,
 		userAgent: "sdc-client/1.0",
+		transport: transport,
+		retry:     DefaultRetryPolicy,
+	}
+
+	if socketPath, ok := strings.CutPrefix(baseURL, "unix://"); ok {
+		c.baseURL = unixSocketHost
+		c.socketPath = socketPath
+
+		dialer := &net.Dialer{Timeout: 5 * time.Second}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	}
+
+	c.httpClient = &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
 	}
+
+	return c
 }
 
 // SetUserAgent sets a custom User-Agent header for requests
@@ -37,16 +107,76 @@ func (c *Client) SetUserAgent(userAgent string) {
 	c.userAgent = userAgent
 }
 
+// SetRetryPolicy sets how failed requests are retried
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
+// SetToken sets the bearer token sent with every request
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetTLSConfig sets the TLS configuration used for https:// controller URLs
+// (custom CA and/or client certificate for mTLS)
+func (c *Client) SetTLSConfig(tlsConfig *tls.Config) {
+	c.transport.TLSClientConfig = tlsConfig
+}
+
+// ReadTokenFile reads a bearer token from a file. The first line that is
+// neither blank nor a '#' comment is used; anything after the token on
+// that line (such as a server-side scope list) is ignored.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return strings.Fields(line)[0], nil
+	}
+
+	return "", fmt.Errorf("token file %s contains no token", path)
+}
+
+// Job types
+const (
+	JobTypeStart = "start"
+	JobTypeStop  = "stop"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
 // JobRequest represents a request to start or stop containers
 type JobRequest struct {
-	Timeout int      `json:"timeout"`
-	Ignore  []string `json:"ignore,omitempty"`
+	Timeout int         `json:"timeout,omitempty"` // 0 = server default
+	Ignore  []string    `json:"ignore,omitempty"`
+	Targets []string    `json:"targets,omitempty"`
+	Options *JobOptions `json:"options,omitempty"`
+}
+
+// JobOptions holds optional job behaviour
+type JobOptions struct {
+	Dependencies *bool `json:"dependencies,omitempty"` // nil = server default (true)
+	Supersede    bool  `json:"supersede,omitempty"`
 }
 
 // JobResponse represents a job creation response
 type JobResponse struct {
-	ID     string `json:"job_id"`
-	Status string `json:"status"`
+	ID        string `json:"job_id"`
+	Status    string `json:"status"`
+	Coalesced bool   `json:"coalesced,omitempty"` // An identical pending job was reused
 }
 
 // Job represents a job's full state
@@ -59,11 +189,62 @@ type Job struct {
 	EndedAt   time.Time `json:"ended_at"`
 	Timeout   int       `json:"timeout"`
 	Ignore    []string  `json:"ignore,omitempty"`
+	Targets   []string  `json:"targets,omitempty"`
 	Started   []string  `json:"started,omitempty"`
 	Stopped   []string  `json:"stopped,omitempty"`
 	Skipped   []string  `json:"skipped,omitempty"`
 	Failed    []string  `json:"failed,omitempty"`
 	Error     string    `json:"error,omitempty"`
+
+	SkipReasons  map[string]string `json:"skip_reasons,omitempty"`
+	FailReasons  map[string]string `json:"fail_reasons,omitempty"`
+	CriticalPath *CriticalPath     `json:"critical_path,omitempty"` // Dependency chain that bounded the job's duration
+	SupersededBy string            `json:"superseded_by,omitempty"` // Set when the job was cancelled
+
+	Hooks map[string][]HookResult `json:"hooks,omitempty"` // Lifecycle hook results by container
+}
+
+// HookResult is the outcome of a container's lifecycle hook
+type HookResult struct {
+	Phase      string `json:"phase"`
+	Action     string `json:"action"`
+	ExitCode   int    `json:"exit_code"` // Command exit code or HTTP status; -1 if the hook did not complete
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	OnFailure  string `json:"on_failure"`
+}
+
+// CriticalPath is the dependency chain that finished last, which bounds how
+// long a job took
+type CriticalPath struct {
+	Steps      []CriticalPathStep `json:"steps"`
+	DurationMs int64              `json:"duration_ms"`
+}
+
+// CriticalPathStep is one container on the critical path
+type CriticalPathStep struct {
+	Container  string `json:"container"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Finished reports whether the job has reached a terminal status
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusCompleted || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
+
+// JobFilter narrows the jobs returned by ListJobs. Zero values match everything.
+type JobFilter struct {
+	Status string
+	Type   string
+	Limit  int // 0 = server default
+}
+
+// JobLogs holds the log lines captured while a job ran
+type JobLogs struct {
+	JobID   string         `json:"job_id"`
+	Entries []logger.Entry `json:"entries"`
+	Dropped int            `json:"dropped"` // Oldest lines discarded by the server
 }
 
 // HealthResponse represents the health check response
@@ -71,59 +252,111 @@ type HealthResponse struct {
 	Status string `json:"status"`
 }
 
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status  string         `json:"status"`
+	Message string         `json:"message,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// ReadinessResponse represents the readiness check response
+type ReadinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
 // StartContainers submits a job to start containers
 func (c *Client) StartContainers(ctx context.Context, timeout int, ignore []string) (*JobResponse, error) {
-	req := JobRequest{
+	return c.SubmitJob(ctx, JobTypeStart, JobRequest{
 		Timeout: timeout,
 		Ignore:  ignore,
-	}
+	})
+}
 
+// StopContainers submits a job to stop containers
+func (c *Client) StopContainers(ctx context.Context, timeout int, ignore []string) (*JobResponse, error) {
+	return c.SubmitJob(ctx, JobTypeStop, JobRequest{
+		Timeout: timeout,
+		Ignore:  ignore,
+	})
+}
+
+// SubmitJob submits a start or stop job
+func (c *Client) SubmitJob(ctx context.Context, jobType string, req JobRequest) (*JobResponse, error) {
 	var resp JobResponse
-	if err := c.post(ctx, "/start", req, &resp); err != nil {
+	if err := c.post(ctx, "/"+jobType, req, &resp); err != nil {
 		return nil, err
 	}
 
-	c.logger.Info("Start job submitted",
+	c.logger.Info("Job submitted",
+		"type", jobType,
 		"job_id", resp.ID,
-		"status", resp.Status)
+		"coalesced", resp.Coalesced)
 
 	return &resp, nil
 }
 
-// StopContainers submits a job to stop containers
-func (c *Client) StopContainers(ctx context.Context, timeout int, ignore []string) (*JobResponse, error) {
-	req := JobRequest{
-		Timeout: timeout,
-		Ignore:  ignore,
+// ListJobs returns the jobs known to the server, newest first
+func (c *Client) ListJobs(ctx context.Context, filter JobFilter) ([]Job, error) {
+	query := url.Values{}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	if filter.Type != "" {
+		query.Set("type", filter.Type)
+	}
+	if filter.Limit != 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
 	}
 
-	var resp JobResponse
-	if err := c.post(ctx, "/stop", req, &resp); err != nil {
-		return nil, err
+	path := "/jobs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
 
-	c.logger.Info("Stop job submitted",
-		"job_id", resp.ID,
-		"status", resp.Status)
+	var resp struct {
+		Jobs []Job `json:"jobs"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Jobs, nil
+}
 
-	return &resp, nil
+// GetJobLogs retrieves the log lines captured while a job ran
+func (c *Client) GetJobLogs(ctx context.Context, jobID string) (*JobLogs, error) {
+	var logs JobLogs
+	if err := c.get(ctx, fmt.Sprintf("/job/%s/logs", url.PathEscape(jobID)), &logs); err != nil {
+		return nil, err
+	}
+	return &logs, nil
 }
 
 // GetJob retrieves job status and results
 func (c *Client) GetJob(ctx context.Context, jobID string) (*Job, error) {
 	var job Job
-	if err := c.get(ctx, fmt.Sprintf("/job_status/%s", jobID), &job); err != nil {
+	if err := c.get(ctx, fmt.Sprintf("/job_status/%s", url.PathEscape(jobID)), &job); err != nil {
 		return nil, err
 	}
 
 	return &job, nil
 }
 
-// WaitForJob waits for a job to complete (completed or failed status)
+// WaitForJob waits for a job to finish (completed, failed or cancelled status).
+// Connection failures and server errors while polling are logged and polled
+// through until ctx is done.
 func (c *Client) WaitForJob(ctx context.Context, jobID string, pollInterval time.Duration) (*Job, error) {
+	return c.FollowJob(ctx, jobID, pollInterval, nil)
+}
+
+// FollowJob waits for a job to finish like WaitForJob, passing each log line
+// the job captures to onLog (if not nil) as it appears
+func (c *Client) FollowJob(ctx context.Context, jobID string, pollInterval time.Duration, onLog func(logger.Entry)) (*Job, error) {
 	ticker := time.NewTicker(pollInterval)
 	defer ticker.Stop()
 
+	seen := 0 // Log lines delivered so far, including ones the server dropped
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -131,6 +364,13 @@ func (c *Client) WaitForJob(ctx context.Context, jobID string, pollInterval time
 		case <-ticker.C:
 			job, err := c.GetJob(ctx, jobID)
 			if err != nil {
+				// The job keeps running on the server; a missed poll doesn't matter
+				if retryable(err) && ctx.Err() == nil {
+					c.logger.Warn("Failed to get job status, will poll again",
+						"job_id", jobID,
+						"error", err)
+					continue
+				}
 				return nil, fmt.Errorf("failed to get job status: %w", err)
 			}
 
@@ -138,17 +378,39 @@ func (c *Client) WaitForJob(ctx context.Context, jobID string, pollInterval time
 				"job_id", jobID,
 				"status", job.Status)
 
-			if job.Status == "completed" || job.Status == "failed" {
+			// Fetched after the status so a finished job's last lines are included
+			if onLog != nil {
+				seen = c.deliverLogs(ctx, jobID, seen, onLog)
+			}
+
+			if job.Finished() {
 				return job, nil
 			}
 		}
 	}
 }
 
+// deliverLogs passes the job's log lines after the first seen to onLog and
+// returns the new count. Failures are logged; the lines are fetched next time.
+func (c *Client) deliverLogs(ctx context.Context, jobID string, seen int, onLog func(logger.Entry)) int {
+	logs, err := c.GetJobLogs(ctx, jobID)
+	if err != nil {
+		c.logger.Warn("Failed to get job logs", "job_id", jobID, "error", err)
+		return seen
+	}
+
+	total := logs.Dropped + len(logs.Entries)
+	fresh := min(total-seen, len(logs.Entries))
+	for _, entry := range logs.Entries[len(logs.Entries)-max(fresh, 0):] {
+		onLog(entry)
+	}
+	return max(total, seen)
+}
+
 // Health checks if the server is healthy
 func (c *Client) Health(ctx context.Context) error {
 	var resp HealthResponse
-	if err := c.get(ctx, "/ping", &resp); err != nil {
+	if err := c.probe(ctx, "/ping", &resp); err != nil {
 		return err
 	}
 
@@ -159,16 +421,71 @@ func (c *Client) Health(ctx context.Context) error {
 	return nil
 }
 
-// WaitForServerReady waits for the server to become ready
+// Ready checks if the server is ready to execute jobs (Docker reachable,
+// queue not saturated, workers running)
+func (c *Client) Ready(ctx context.Context) error {
+	var resp ReadinessResponse
+	if err := c.probe(ctx, "/readyz", &resp); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable {
+			if json.Unmarshal([]byte(apiErr.Body), &resp) == nil {
+				return fmt.Errorf("server is not ready: %s", failedChecks(resp))
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DockerReady checks if the server can reach Docker. Unlike Ready, it
+// ignores the other readiness checks, such as a saturated job queue.
+func (c *Client) DockerReady(ctx context.Context) error {
+	var resp ReadinessResponse
+	if err := c.probe(ctx, "/readyz", &resp); err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable ||
+			json.Unmarshal([]byte(apiErr.Body), &resp) != nil {
+			return err
+		}
+	}
+
+	check, ok := resp.Checks["docker"]
+	if !ok {
+		return errors.New("server reported no docker check")
+	}
+	if check.Status == "failed" {
+		return fmt.Errorf("docker is not reachable: %s", check.Message)
+	}
+	return nil
+}
+
+// failedChecks summarizes the failing checks of a readiness response
+func failedChecks(resp ReadinessResponse) string {
+	var failed []string
+	for name, check := range resp.Checks {
+		if check.Status == "failed" {
+			failed = append(failed, fmt.Sprintf("%s (%s)", name, check.Message))
+		}
+	}
+	slices.Sort(failed)
+	return strings.Join(failed, ", ")
+}
+
+// WaitForServerReady waits for the server to become ready.
+// Readiness is checked through /readyz; servers without that endpoint
+// fall back to the /ping health check.
 func (c *Client) WaitForServerReady(ctx context.Context, timeout time.Duration) error {
 	c.logger.Info("Waiting for server to become ready",
-		"url", c.baseURL,
+		"url", c.displayURL(),
 		"timeout", timeout)
 
 	deadline := time.Now().Add(timeout)
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
+	check := c.Ready
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -178,7 +495,14 @@ func (c *Client) WaitForServerReady(ctx context.Context, timeout time.Duration)
 				return fmt.Errorf("timeout waiting for server to become ready")
 			}
 
-			if err := c.Health(ctx); err != nil {
+			err := check(ctx)
+			if errors.Is(err, ErrNotFound) {
+				c.logger.Debug("Server has no readiness endpoint, using health check")
+				check = c.Health
+				err = check(ctx)
+			}
+
+			if err != nil {
 				c.logger.Debug("Server not ready yet",
 					"error", err,
 					"remaining", time.Until(deadline))
@@ -191,64 +515,117 @@ func (c *Client) WaitForServerReady(ctx context.Context, timeout time.Duration)
 	}
 }
 
-// post performs a POST request
+// displayURL returns the controller address for log messages
+func (c *Client) displayURL() string {
+	if c.socketPath != "" {
+		return "unix://" + c.socketPath
+	}
+	return c.baseURL
+}
+
+// post performs a POST request. Every attempt carries the same
+// Idempotency-Key, so a retry never runs the operation twice.
 func (c *Client) post(ctx context.Context, path string, body any, result any) error {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	return c.do(ctx, http.MethodPost, path, data, result, true)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", c.userAgent)
+// get performs a GET request
+func (c *Client) get(ctx context.Context, path string, result any) error {
+	return c.do(ctx, http.MethodGet, path, nil, result, true)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// probe performs a GET request without retries, for checks that are
+// polled anyway
+func (c *Client) probe(ctx context.Context, path string, result any) error {
+	return c.do(ctx, http.MethodGet, path, nil, result, false)
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+// do sends a request, retrying connection failures and server errors
+// according to the retry policy, and decodes the JSON response into result
+func (c *Client) do(ctx context.Context, method, path string, data []byte, result any, retry bool) error {
+	maxAttempts := 1
+	if retry {
+		maxAttempts = max(c.retry.MaxAttempts, 1)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	idempotencyKey := ""
+	if method != http.MethodGet {
+		idempotencyKey = uuid.New().String()
 	}
 
-	return nil
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, path, data, idempotencyKey)
+		if err == nil {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			return nil
+		}
+
+		if attempt >= maxAttempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := c.retry.backoff(attempt)
+		c.logger.Debug("Request failed, retrying",
+			"method", method,
+			"path", path,
+			"attempt", attempt,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
 }
 
-// get performs a GET request
-func (c *Client) get(ctx context.Context, path string, result any) error {
-	url := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// send performs a single attempt. Non-2xx responses are returned as *APIError.
+func (c *Client) send(ctx context.Context, method, path string, data []byte, idempotencyKey string) (*http.Response, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", c.userAgent)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, newAPIError(resp.StatusCode, bodyBytes)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
-	}
+	return resp, nil
+}
 
-	return nil
+// setHeaders adds the User-Agent and, if configured, Authorization headers
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
 }
@@ -3,13 +3,18 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/saltyorg/sdc/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewClient(t *testing.T) {
@@ -114,6 +119,21 @@ func TestClient_GetJob(t *testing.T) {
 	assert.Len(t, job.Started, 2)
 }
 
+func TestClient_GetJob_EscapesID(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/job_status/a%2F..%2Fb%3F", r.URL.EscapedPath())
+		assert.Empty(t, r.URL.RawQuery)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	_, err := client.GetJob(context.Background(), "a/../b?")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
 func TestClient_WaitForJob(t *testing.T) {
 	log, _ := logger.New(true)
 
@@ -284,3 +304,318 @@ func TestClient_Get_ErrorResponse(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "404")
 }
+
+func TestClient_Token(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(HealthResponse{Status: "healthy"})
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(tokenFile, []byte("# sdc helper\ns3cret read,write\n"), 0600)
+	assert.NoError(t, err)
+
+	token, err := ReadTokenFile(tokenFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", token)
+
+	client := NewClient(server.URL, log)
+	client.SetToken(token)
+
+	err = client.Health(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	log, _ := logger.New(true)
+
+	socketPath := filepath.Join(t.TempDir(), "sdc.sock")
+	l, err := net.Listen("unix", socketPath)
+	assert.NoError(t, err)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ping", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(HealthResponse{Status: "healthy"})
+	})}
+	go server.Serve(l)
+	defer server.Close()
+
+	client := NewClient("unix://"+socketPath, log)
+	assert.Equal(t, socketPath, client.socketPath)
+
+	err = client.Health(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestClient_Ready_NotReady(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/readyz", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ReadinessResponse{
+			Status: "not_ready",
+			Checks: map[string]CheckResult{
+				"docker":  {Status: "failed", Message: "Docker daemon unreachable"},
+				"workers": {Status: "ok"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+
+	err := client.Ready(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "docker (Docker daemon unreachable)")
+}
+
+func TestClient_DockerReady(t *testing.T) {
+	log, _ := logger.New(true)
+
+	docker := CheckResult{Status: "ok"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/readyz", r.URL.Path)
+
+		// The queue is saturated, so the server as a whole is not ready
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ReadinessResponse{
+			Status: "not_ready",
+			Checks: map[string]CheckResult{
+				"docker":    docker,
+				"job_queue": {Status: "failed", Message: "Job queue saturated"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	assert.NoError(t, client.DockerReady(context.Background()))
+
+	docker = CheckResult{Status: "failed", Message: "Docker daemon unreachable"}
+	err := client.DockerReady(context.Background())
+	assert.ErrorContains(t, err, "Docker daemon unreachable")
+}
+
+func TestClient_WaitForServerReady_FallbackToPing(t *testing.T) {
+	log, _ := logger.New(true)
+
+	// Older servers have no /readyz endpoint
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ping" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(HealthResponse{Status: "healthy"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+
+	err := client.WaitForServerReady(context.Background(), 5*time.Second)
+	assert.NoError(t, err)
+}
+
+func TestClient_Post_RetriesWithSameIdempotencyKey(t *testing.T) {
+	log, _ := logger.New(true)
+
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			// Drop the connection as if the network failed mid-request
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JobResponse{ID: "test-job-id", Status: "pending"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	resp, err := client.StartContainers(context.Background(), 600, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "test-job-id", resp.ID)
+
+	require.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+
+	// Each operation gets its own key
+	_, err = client.StartContainers(context.Background(), 600, nil)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.NotEqual(t, keys[0], keys[2])
+}
+
+func TestClient_ListJobs(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/jobs", r.URL.Path)
+		assert.Equal(t, "failed", r.URL.Query().Get("status"))
+		assert.Equal(t, "stop", r.URL.Query().Get("type"))
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"jobs": []Job{{ID: "job-2", Type: "stop", Status: "failed"}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	jobs, err := client.ListJobs(context.Background(), JobFilter{Status: "failed", Type: "stop", Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "job-2", jobs[0].ID)
+}
+
+func TestClient_FollowJob_DeliversEachLogLineOnce(t *testing.T) {
+	log, _ := logger.New(true)
+
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job_status/job-1":
+			polls++
+			status := JobStatusRunning
+			if polls >= 3 {
+				status = JobStatusCompleted
+			}
+			json.NewEncoder(w).Encode(Job{ID: "job-1", Status: status})
+		case "/job/job-1/logs":
+			// One more line per poll, with the oldest dropped from the third poll on
+			var entries []logger.Entry
+			for i := 1; i <= polls; i++ {
+				entries = append(entries, logger.Entry{Message: fmt.Sprintf("line %d", i)})
+			}
+			dropped := 0
+			if polls >= 3 {
+				dropped, entries = 1, entries[1:]
+			}
+			json.NewEncoder(w).Encode(JobLogs{JobID: "job-1", Entries: entries, Dropped: dropped})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+
+	var lines []string
+	job, err := client.FollowJob(context.Background(), "job-1", 10*time.Millisecond, func(entry logger.Entry) {
+		lines = append(lines, entry.Message)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+	assert.Equal(t, []string{"line 1", "line 2", "line 3"}, lines)
+}
+
+func TestClient_Blocks(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/blocks":
+			var req CreateBlockRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, []string{"plex"}, req.Containers)
+			assert.Equal(t, "2h", req.Duration)
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Block{ID: "block-1", Scope: "containers", Containers: req.Containers})
+		case r.Method == http.MethodGet && r.URL.Path == "/blocks":
+			json.NewEncoder(w).Encode(map[string]any{"blocks": []Block{{ID: "block-1", Scope: "containers"}}})
+		case r.Method == http.MethodDelete && r.URL.Path == "/blocks/block-1":
+			json.NewEncoder(w).Encode(map[string]string{"message": "Block removed"})
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": "not_found", "error": "Block not found"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	ctx := context.Background()
+
+	block, err := client.CreateBlock(ctx, CreateBlockRequest{Containers: []string{"plex"}, Duration: "2h"})
+	require.NoError(t, err)
+	assert.Equal(t, "block-1", block.ID)
+
+	blocks, err := client.ListBlocks(ctx)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+
+	require.NoError(t, client.DeleteBlock(ctx, "block-1"))
+	assert.ErrorIs(t, client.DeleteBlock(ctx, "block-2"), ErrNotFound)
+}
+
+func TestClient_GetGraph(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graph", r.URL.Path)
+		w.Write([]byte(`{"nodes": [{"name": "app", "running": true, "parents": ["db"], "children": []}, {"name": "db", "running": true, "parents": [], "children": ["app"]}], "batches": [["db"], ["app"]]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	g, err := client.GetGraph(context.Background())
+	require.NoError(t, err)
+	require.Len(t, g.Nodes, 2)
+	assert.Equal(t, []string{"db"}, g.Nodes[0].Parents)
+	assert.Equal(t, [][]string{{"db"}, {"app"}}, g.Batches)
+}
+
+func TestClient_ListContainers(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/containers", r.URL.Path)
+		assert.Equal(t, "running", r.URL.Query().Get("state"))
+		assert.Equal(t, "false", r.URL.Query().Get("managed"))
+		w.Write([]byte(`{"containers": [{"name": "redis", "state": "running", "running": true, "managed": false, "unmanaged_reason": "saltbox_controller label is false", "parents": [], "children": ["app"]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	managed := false
+	containers, err := client.ListContainers(context.Background(), ContainerFilter{State: "running", Managed: &managed})
+	require.NoError(t, err)
+	require.Len(t, containers, 1)
+	assert.Equal(t, "saltbox_controller label is false", containers[0].UnmanagedReason)
+	assert.Nil(t, containers[0].Depth)
+}
+
+func TestClient_Lint(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/lint", r.URL.Path)
+		w.Write([]byte(`{"findings": [{"severity": "error", "rule": "missing-dependency", "container": "app", "label": "com.github.saltbox.depends_on", "message": "dependency \"db\" is missing"}], "errors": 1, "warnings": 0, "infos": 0}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	report, err := client.Lint(context.Background())
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, "missing-dependency", report.Findings[0].Rule)
+	assert.Equal(t, 1, report.Errors)
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// ContainerLabels are the parsed Saltbox labels of a container
+type ContainerLabels struct {
+	Managed            bool     `json:"managed"`
+	ControllerEnabled  bool     `json:"controller_enabled"`
+	DependsOn          []string `json:"depends_on"`
+	OptionalDependsOn  []string `json:"optional_depends_on,omitempty"`
+	StartupDelay       int      `json:"startup_delay"`
+	WaitForHealthcheck bool     `json:"wait_for_healthcheck"`
+	Priority           int      `json:"priority"`
+	Group              string   `json:"group,omitempty"`
+	GroupConcurrency   int      `json:"group_concurrency,omitempty"`
+	Groups             []string `json:"groups,omitempty"` // Selection groups
+
+	Hooks map[string]ContainerHook `json:"hooks,omitempty"` // Lifecycle hooks by phase
+}
+
+// ContainerHook is a lifecycle hook from a container's labels
+type ContainerHook struct {
+	Action    string `json:"action"`
+	Timeout   int    `json:"timeout"`    // Seconds
+	OnFailure string `json:"on_failure"` // continue or fail
+}
+
+// ContainerInfo describes a container as the controller sees it
+type ContainerInfo struct {
+	Name                string           `json:"name"`
+	ID                  string           `json:"id,omitempty"`
+	Image               string           `json:"image,omitempty"`
+	State               string           `json:"state"` // Docker state, or "missing"
+	Status              string           `json:"status,omitempty"`
+	Health              string           `json:"health,omitempty"`
+	Running             bool             `json:"running"`
+	Managed             bool             `json:"managed"`
+	UnmanagedReason     string           `json:"unmanaged_reason,omitempty"`
+	Placeholder         bool             `json:"placeholder,omitempty"`
+	Labels              *ContainerLabels `json:"labels,omitempty"`
+	Parents             []string         `json:"parents"`
+	Children            []string         `json:"children"`
+	MissingDependencies []string         `json:"missing_dependencies,omitempty"`
+	Depth               *int             `json:"depth,omitempty"` // Startup batch index
+}
+
+// ContainerFilter narrows ListContainers. Empty fields match everything.
+type ContainerFilter struct {
+	State   string
+	Name    string // Substring of the container name
+	Managed *bool
+}
+
+// ListContainers returns the labelled containers, sorted by name
+func (c *Client) ListContainers(ctx context.Context, filter ContainerFilter) ([]ContainerInfo, error) {
+	query := url.Values{}
+	if filter.State != "" {
+		query.Set("state", filter.State)
+	}
+	if filter.Name != "" {
+		query.Set("name", filter.Name)
+	}
+	if filter.Managed != nil {
+		query.Set("managed", strconv.FormatBool(*filter.Managed))
+	}
+
+	path := "/containers"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp struct {
+		Containers []ContainerInfo `json:"containers"`
+	}
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Containers, nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+var (
+	// ErrBlocked matches errors for requests rejected because operations are blocked
+	ErrBlocked = errors.New("operations are blocked")
+
+	// ErrNotFound matches errors for unknown jobs, blocks or routes
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnavailable matches errors for requests the server could not take on
+	// right now (job queue full, shutting down)
+	ErrUnavailable = errors.New("server unavailable")
+)
+
+// APIError is returned for non-2xx responses
+type APIError struct {
+	StatusCode int
+	Code       string // Error code from the response envelope; empty for older servers
+	Message    string
+	Body       string // Raw response body
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("request failed with status %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Is matches the ErrBlocked, ErrNotFound and ErrUnavailable sentinels
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBlocked:
+		// Servers without error codes only answered 503 for blocked operations
+		return e.Code == "blocked" || (e.Code == "" && e.StatusCode == http.StatusServiceUnavailable)
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnavailable:
+		return e.Code == "unavailable"
+	default:
+		return false
+	}
+}
+
+// newAPIError builds an APIError from a response, decoding the error envelope if present
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Body: string(body)}
+
+	var envelope struct {
+		Code  string `json:"code"`
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.Code = envelope.Code
+		apiErr.Message = envelope.Error
+	}
+	return apiErr
+}
+
+// retryable reports whether a failed request may succeed if sent again:
+// transport failures and server errors, except for blocked operations
+// which last until someone unblocks them. Cancelled requests and local
+// errors, such as an invalid request, are not retried.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 && !errors.Is(apiErr, ErrBlocked)
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error // Includes *url.Error
+	return errors.As(err, &netErr)
+}
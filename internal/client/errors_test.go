@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetries keeps retry tests quick
+var fastRetries = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestAPIError_Sentinels(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		blocked     bool
+		notFound    bool
+		unavailable bool
+		code        string
+	}{
+		{
+			name:    "blocked",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"code": "blocked", "error": "Operation blocked: backup (block 1)"}`,
+			blocked: true,
+			code:    "blocked",
+		},
+		{
+			name:    "blocked on a server without error codes",
+			status:  http.StatusServiceUnavailable,
+			body:    `{"error": "Operation blocked"}`,
+			blocked: true,
+		},
+		{
+			name:        "queue full",
+			status:      http.StatusServiceUnavailable,
+			body:        `{"code": "unavailable", "error": "job queue is full"}`,
+			unavailable: true,
+			code:        "unavailable",
+		},
+		{
+			name:     "not found",
+			status:   http.StatusNotFound,
+			body:     `{"code": "not_found", "error": "Job not found: 1"}`,
+			notFound: true,
+			code:     "not_found",
+		},
+		{
+			name:   "invalid argument",
+			status: http.StatusBadRequest,
+			body:   `{"code": "invalid_argument", "error": "timeout must be positive"}`,
+			code:   "invalid_argument",
+		},
+		{
+			name:   "non-JSON body",
+			status: http.StatusBadGateway,
+			body:   `bad gateway`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error = newAPIError(tt.status, []byte(tt.body))
+
+			assert.Equal(t, tt.blocked, errors.Is(err, ErrBlocked))
+			assert.Equal(t, tt.notFound, errors.Is(err, ErrNotFound))
+			assert.Equal(t, tt.unavailable, errors.Is(err, ErrUnavailable))
+
+			var apiErr *APIError
+			require.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &apiErr))
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.code, apiErr.Code)
+		})
+	}
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	log, _ := logger.New(true)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(Job{ID: "job-1", Status: "running"})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	client.SetRetryPolicy(fastRetries)
+
+	job, err := client.GetJob(context.Background(), "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, "job-1", job.ID)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestClient_GivesUpAfterMaxAttempts(t *testing.T) {
+	log, _ := logger.New(true)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code": "unavailable", "error": "job queue is full"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	client.SetRetryPolicy(fastRetries)
+
+	_, err := client.StartContainers(context.Background(), 600, nil)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestClient_DoesNotRetryClientErrorsOrBlocks(t *testing.T) {
+	log, _ := logger.New(true)
+
+	for _, resp := range []struct {
+		status int
+		body   string
+	}{
+		{http.StatusBadRequest, `{"code": "invalid_argument", "error": "bad timeout"}`},
+		{http.StatusServiceUnavailable, `{"code": "blocked", "error": "Operation blocked"}`},
+	} {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(resp.status)
+			w.Write([]byte(resp.body))
+		}))
+
+		client := NewClient(server.URL, log)
+		client.SetRetryPolicy(fastRetries)
+
+		_, err := client.StopContainers(context.Background(), 300, nil)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), attempts.Load(), "status %d should not be retried", resp.status)
+		server.Close()
+	}
+}
+
+func TestClient_WaitForJob_ToleratesPollFailures(t *testing.T) {
+	log, _ := logger.New(true)
+
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch polls.Add(1) {
+		case 1:
+			json.NewEncoder(w).Encode(Job{ID: "job-1", Status: "running"})
+		case 2, 3, 4, 5:
+			w.WriteHeader(http.StatusInternalServerError) // Outlasts one GetJob's retries
+		default:
+			json.NewEncoder(w).Encode(Job{ID: "job-1", Status: "completed"})
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	job, err := client.WaitForJob(ctx, "job-1", 10*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", job.Status)
+}
+
+func TestClient_WaitForJob_UnknownJob(t *testing.T) {
+	log, _ := logger.New(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": "not_found", "error": "Job not found: job-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, log)
+	_, err := client.WaitForJob(context.Background(), "job-1", 10*time.Millisecond)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRetryable(t *testing.T) {
+	transport := &url.Error{Op: "Get", URL: "http://127.0.0.1:3377/ping", Err: syscall.ECONNREFUSED}
+	cancelled := &url.Error{Op: "Get", URL: "http://127.0.0.1:3377/ping", Err: context.Canceled}
+
+	assert.True(t, retryable(fmt.Errorf("request failed: %w", transport)))
+	assert.True(t, retryable(newAPIError(http.StatusBadGateway, nil)))
+	assert.False(t, retryable(fmt.Errorf("request failed: %w", cancelled)))
+	assert.False(t, retryable(newAPIError(http.StatusNotFound, nil)))
+	assert.False(t, retryable(fmt.Errorf("failed to create request: %w", errors.New("invalid method"))))
+	assert.False(t, retryable(nil))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for range 20 {
+		first := policy.backoff(1)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.LessOrEqual(t, first, 100*time.Millisecond)
+
+		second := policy.backoff(2)
+		assert.GreaterOrEqual(t, second, 100*time.Millisecond)
+		assert.LessOrEqual(t, second, 200*time.Millisecond)
+
+		capped := policy.backoff(10)
+		assert.GreaterOrEqual(t, capped, 150*time.Millisecond)
+		assert.LessOrEqual(t, capped, 300*time.Millisecond)
+	}
+}
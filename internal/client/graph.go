@@ -0,0 +1,36 @@
+package client
+
+import "context"
+
+// GraphNode is a container in the dependency graph
+type GraphNode struct {
+	Name               string              `json:"name"`
+	Running            bool                `json:"running"`
+	Placeholder        bool                `json:"placeholder,omitempty"`
+	Parents            []string            `json:"parents"`
+	OptionalParents    []string            `json:"optional_parents,omitempty"` // Parents that are optional dependencies
+	Patterns           map[string][]string `json:"patterns,omitempty"`         // What each dependency pattern expanded to
+	Children           []string            `json:"children"`
+	StartupDelay       int                 `json:"startup_delay,omitempty"`
+	WaitForHealthcheck bool                `json:"wait_for_healthcheck,omitempty"`
+	Priority           int                 `json:"priority,omitempty"`
+	Group              string              `json:"group,omitempty"`
+	GroupConcurrency   int                 `json:"group_concurrency,omitempty"`
+	Groups             []string            `json:"groups,omitempty"` // Selection groups
+}
+
+// Graph is the container dependency graph as seen by the server
+type Graph struct {
+	Nodes   []GraphNode `json:"nodes"`
+	Batches [][]string  `json:"batches,omitempty"` // Startup batches; empty if there are cycles
+	Cycles  [][]string  `json:"cycles,omitempty"`  // Every dependency cycle, as a path
+}
+
+// GetGraph retrieves the current dependency graph
+func (c *Client) GetGraph(ctx context.Context) (*Graph, error) {
+	var g Graph
+	if err := c.get(ctx, "/graph", &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
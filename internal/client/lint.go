@@ -0,0 +1,29 @@
+package client
+
+import "context"
+
+// LintFinding is a single problem found in the container labels
+type LintFinding struct {
+	Severity  string `json:"severity"` // error, warning or info
+	Rule      string `json:"rule"`
+	Container string `json:"container,omitempty"`
+	Label     string `json:"label,omitempty"`
+	Message   string `json:"message"`
+}
+
+// LintReport is the result of checking the container labels
+type LintReport struct {
+	Findings []LintFinding `json:"findings"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Infos    int           `json:"infos"`
+}
+
+// Lint checks the container labels and dependency graph for mistakes
+func (c *Client) Lint(ctx context.Context) (*LintReport, error) {
+	var report LintReport
+	if err := c.get(ctx, "/lint", &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Schedule starts, stops or restarts containers on a cron schedule
+type Schedule struct {
+	ID                  string        `json:"id"`
+	Name                string        `json:"name,omitempty"`
+	Cron                string        `json:"cron"`
+	Operation           string        `json:"operation"` // start, stop or restart
+	Targets             []string      `json:"targets,omitempty"`
+	Ignore              []string      `json:"ignore,omitempty"`
+	Timeout             int           `json:"timeout"`
+	IncludeDependencies bool          `json:"include_dependencies"`
+	Source              string        `json:"source"` // config or api
+	CreatedAt           time.Time     `json:"created_at"`
+	NextRun             time.Time     `json:"next_run"` // Zero if the expression never matches again
+	Runs                []ScheduleRun `json:"runs"`     // Newest first
+}
+
+// ScheduleRun records one firing of a schedule
+type ScheduleRun struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Status      string    `json:"status"` // running, completed, failed or skipped
+	JobIDs      []string  `json:"job_ids,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+}
+
+// CreateScheduleRequest describes a schedule to create. Empty fields use server defaults.
+type CreateScheduleRequest struct {
+	Name         string   `json:"name,omitempty"`
+	Cron         string   `json:"cron"`
+	Operation    string   `json:"operation"`
+	Targets      []string `json:"targets,omitempty"`
+	Ignore       []string `json:"ignore,omitempty"`
+	Timeout      *int     `json:"timeout,omitempty"`
+	Dependencies *bool    `json:"dependencies,omitempty"`
+}
+
+// ListSchedules returns all schedules, oldest first
+func (c *Client) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	var resp struct {
+		Schedules []Schedule `json:"schedules"`
+	}
+	if err := c.get(ctx, "/schedules", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Schedules, nil
+}
+
+// GetSchedule returns a schedule with its recent runs
+func (c *Client) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	var schedule Schedule
+	if err := c.get(ctx, fmt.Sprintf("/schedules/%s", url.PathEscape(id)), &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// CreateSchedule creates a schedule and returns it as stored by the server
+func (c *Client) CreateSchedule(ctx context.Context, req CreateScheduleRequest) (*Schedule, error) {
+	var schedule Schedule
+	if err := c.post(ctx, "/schedules", req, &schedule); err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// DeleteSchedule removes a schedule created through the API
+func (c *Client) DeleteSchedule(ctx context.Context, id string) error {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/schedules/%s", url.PathEscape(id)), nil, &resp, true)
+}
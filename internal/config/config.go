@@ -2,10 +2,55 @@ package config
 
 import "time"
 
+// LogConfig holds logging configuration shared by all commands
+type LogConfig struct {
+	Level  string
+	Format string
+}
+
 // ServerConfig holds configuration for server mode
 type ServerConfig struct {
 	Host string
-	Port int
+	Port int // 0 disables the TCP listener
+
+	// Unix domain socket listener (optional)
+	Socket      string // Socket path, e.g. /run/sdc/sdc.sock
+	SocketOwner string // User name or UID
+	SocketGroup string // Group name or GID
+	SocketMode  string // Octal permissions, e.g. 0660
+
+	// Authentication (all optional; the API is open when none are set)
+	Token            string // Static bearer token with all scopes
+	TokenFile        string // File with one "<token> [scopes]" entry per line
+	TLSCert          string // Server certificate (enables HTTPS)
+	TLSKey           string // Server private key
+	TLSClientCA      string // CA bundle used to verify client certificates (enables mTLS)
+	ClientCertScopes string // Scopes granted to verified client certificates
+
+	// Persistent state such as active blocks (empty disables persistence)
+	StateDir string
+
+	// What jobs do when dependency labels form a cycle: fail or quarantine
+	CyclePolicy string
+
+	// Concurrency limits for container starts and stops (0 = unlimited)
+	MaxConcurrentOperations int
+	GroupConcurrency        map[string]int // Per-group limits overriding the group_concurrency labels
+
+	// Scheduled operations
+	ScheduleFile    string // JSON file with schedules defined by the administrator (optional)
+	ScheduleHistory int    // Runs kept per schedule
+
+	// Outbound webhooks notified of job events
+	WebhookFile string // JSON file with webhook receivers (optional)
+}
+
+// ClientAuthConfig holds credentials used by API clients
+type ClientAuthConfig struct {
+	TokenFile string // File containing the bearer token
+	TLSCA     string // CA bundle used to verify the server certificate
+	TLSCert   string // Client certificate for mTLS
+	TLSKey    string // Client private key for mTLS
 }
 
 // HelperConfig holds configuration for helper mode
@@ -14,6 +59,8 @@ type HelperConfig struct {
 	StartupDelay  time.Duration
 	Timeout       int
 	PollInterval  time.Duration
+	Retries       int // Attempts per API request
+	ClientAuthConfig
 }
 
 // DockerConfig holds Docker client configuration
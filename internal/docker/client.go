@@ -1,10 +1,15 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/moby/moby/api/pkg/stdcopy"
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
 	"github.com/saltyorg/sdc/pkg/logger"
@@ -80,7 +85,7 @@ func (c *Client) StartContainer(ctx context.Context, containerID string) error {
 		return fmt.Errorf("failed to start container %s: %w", containerID, err)
 	}
 
-	c.logger.Debug("Container started", "container", containerID)
+	logger.FromContext(ctx, c.logger).Debug("Container started", "container", containerID)
 	return nil
 }
 
@@ -93,10 +98,23 @@ func (c *Client) StopContainer(ctx context.Context, containerID string, timeout
 		return fmt.Errorf("failed to stop container %s: %w", containerID, err)
 	}
 
-	c.logger.Debug("Container stopped", "container", containerID)
+	logger.FromContext(ctx, c.logger).Debug("Container stopped", "container", containerID)
 	return nil
 }
 
+// ContainerExists reports whether a container with the given name or ID
+// exists, whatever its labels
+func (c *Client) ContainerExists(ctx context.Context, containerNameOrID string) (bool, error) {
+	_, err := c.cli.ContainerInspect(ctx, containerNameOrID, client.ContainerInspectOptions{})
+	if cerrdefs.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect container %s: %w", containerNameOrID, err)
+	}
+	return true, nil
+}
+
 // HasHealthCheck checks if a container has a health check configured
 func (c *Client) HasHealthCheck(ctx context.Context, containerNameOrID string) (bool, error) {
 	info, err := c.GetContainer(ctx, containerNameOrID)
@@ -151,3 +169,84 @@ func (c *Client) GetContainerLogs(ctx context.Context, containerID string) (stri
 
 	return string(data), nil
 }
+
+// maxExecOutput caps the output kept from an exec command
+const maxExecOutput = 64 * 1024
+
+// Exec runs a command inside a running container and returns its combined
+// stdout and stderr with its exit code. When ctx ends first the command is
+// left running in the container and ctx's error is returned.
+func (c *Client) Exec(ctx context.Context, containerID string, cmd []string) (string, int, error) {
+	created, err := c.cli.ExecCreate(ctx, containerID, client.ExecCreateOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return "", -1, fmt.Errorf("failed to create exec in container %s: %w", containerID, err)
+	}
+
+	attached, err := c.cli.ExecAttach(ctx, created.ID, client.ExecAttachOptions{})
+	if err != nil {
+		return "", -1, fmt.Errorf("failed to attach to exec in container %s: %w", containerID, err)
+	}
+	defer attached.Close()
+
+	output := &cappedBuffer{limit: maxExecOutput}
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(output, output, attached.Reader)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return output.String(), -1, fmt.Errorf("failed to read exec output: %w", err)
+		}
+	case <-ctx.Done():
+		attached.Close()
+		<-done
+		return output.String(), -1, ctx.Err()
+	}
+
+	inspect, err := c.cli.ExecInspect(ctx, created.ID, client.ExecInspectOptions{})
+	if err != nil {
+		return output.String(), -1, fmt.Errorf("failed to inspect exec in container %s: %w", containerID, err)
+	}
+
+	logger.FromContext(ctx, c.logger).Debug("Exec finished", "container", containerID, "exit_code", inspect.ExitCode)
+	return output.String(), inspect.ExitCode, nil
+}
+
+// ContainerIP returns the IP address of a container on its first network,
+// by network name
+func (c *Client) ContainerIP(ctx context.Context, containerID string) (string, error) {
+	info, err := c.GetContainer(ctx, containerID)
+	if err != nil {
+		return "", err
+	}
+
+	if settings := info.Container.NetworkSettings; settings != nil {
+		for _, name := range slices.Sorted(maps.Keys(settings.Networks)) {
+			if endpoint := settings.Networks[name]; endpoint != nil && endpoint.IPAddress.IsValid() {
+				return endpoint.IPAddress.String(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("container %s has no IP address", containerID)
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Hook phases, each read from the label LabelHooks + phase
+const (
+	HookPreStart  = "pre_start"
+	HookPostStart = "post_start"
+	HookPreStop   = "pre_stop"
+	HookPostStop  = "post_stop"
+)
+
+// HookPhases lists the hook phases in the order they can run
+var HookPhases = []string{HookPreStart, HookPostStart, HookPreStop, HookPostStop}
+
+// Hook label keys: hooks.<phase> holds the action, with optional
+// hooks.<phase>.timeout and hooks.<phase>.on_failure settings
+const (
+	LabelHooks          = LabelPrefix + "hooks."
+	HookTimeoutSuffix   = ".timeout"
+	HookOnFailureSuffix = ".on_failure"
+)
+
+// DefaultHookTimeout is the hook timeout in seconds when none is set
+const DefaultHookTimeout = 30
+
+// HookPolicy decides what a failed hook does to the operation
+type HookPolicy string
+
+const (
+	HookPolicyContinue HookPolicy = "continue" // Log the failure and carry on
+	HookPolicyFail     HookPolicy = "fail"     // Fail the container's operation
+)
+
+// Hook kinds
+const (
+	HookKindExec = "exec"
+	HookKindHTTP = "http"
+)
+
+// Hook is a lifecycle action run around a container's start or stop
+type Hook struct {
+	Action    string     // "exec:<command>" (split on whitespace, no quoting) or "[METHOD ]http(s)://host/path"
+	Timeout   int        // Seconds
+	OnFailure HookPolicy // continue or fail
+}
+
+// HookAction is a parsed hook action
+type HookAction struct {
+	Kind    string   // exec or http
+	Command []string // exec only
+	Method  string   // http only
+	URL     *url.URL // http only; an empty host means the container itself
+}
+
+// HookLabel returns the label key holding the action of a hook phase
+func HookLabel(phase string) string {
+	return LabelHooks + phase
+}
+
+// IsHookPhase reports whether phase is a known hook phase
+func IsHookPhase(phase string) bool {
+	return slices.Contains(HookPhases, phase)
+}
+
+// ParseHookPolicy parses an on_failure value
+func ParseHookPolicy(value string) (HookPolicy, error) {
+	switch policy := HookPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case HookPolicyContinue, HookPolicyFail:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid failure policy %q: must be continue or fail", value)
+	}
+}
+
+// ParseHookTimeout parses a timeout value in seconds
+func ParseHookTimeout(value string) (int, error) {
+	timeout, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be a positive number of seconds", value)
+	}
+	return timeout, nil
+}
+
+// ParseHookAction parses "exec:<command>" or an HTTP(S) URL, optionally
+// prefixed with a method such as "POST http://:8080/warmup". Exec commands
+// are split on whitespace without a shell: quotes are not interpreted, so an
+// argument can't contain spaces.
+func ParseHookAction(action string) (*HookAction, error) {
+	action = strings.TrimSpace(action)
+	if command, ok := strings.CutPrefix(action, HookKindExec+":"); ok {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			return nil, fmt.Errorf("exec hook has no command")
+		}
+		return &HookAction{Kind: HookKindExec, Command: args}, nil
+	}
+
+	method := http.MethodGet
+	if before, after, ok := strings.Cut(action, " "); ok {
+		method = strings.ToUpper(before)
+		action = strings.TrimSpace(after)
+	}
+	u, err := url.Parse(action)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid hook action %q: must be exec:<command> or an http(s) URL", action)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid hook URL %q: missing host or port", action)
+	}
+	return &HookAction{Kind: HookKindHTTP, Method: method, URL: u}, nil
+}
+
+// TargetsContainer reports whether the action runs in or against the
+// container itself, which requires it to be running
+func (a *HookAction) TargetsContainer() bool {
+	return a.Kind == HookKindExec || a.URL.Hostname() == ""
+}
+
+// ValidateHook checks a hook's action for the phase it runs in. pre_start and
+// post_stop run while the container is stopped, so they must call an HTTP
+// endpoint elsewhere.
+func ValidateHook(phase string, hook Hook) (*HookAction, error) {
+	action, err := ParseHookAction(hook.Action)
+	if err != nil {
+		return nil, err
+	}
+	if (phase == HookPreStart || phase == HookPostStop) && action.TargetsContainer() {
+		return nil, fmt.Errorf("%s hook runs while the container is stopped: use an HTTP URL with an explicit host", phase)
+	}
+	return action, nil
+}
+
+// parseHooks reads the hook labels. Invalid timeouts and policies fall back
+// to the defaults; the action is validated when the hook runs.
+func parseHooks(labels map[string]string) map[string]Hook {
+	var hooks map[string]Hook
+	for _, phase := range HookPhases {
+		action := strings.TrimSpace(labels[HookLabel(phase)])
+		if action == "" {
+			continue
+		}
+
+		hook := Hook{Action: action, Timeout: DefaultHookTimeout, OnFailure: HookPolicyContinue}
+		if value, ok := labels[HookLabel(phase)+HookTimeoutSuffix]; ok {
+			if timeout, err := ParseHookTimeout(value); err == nil {
+				hook.Timeout = timeout
+			}
+		}
+		if value, ok := labels[HookLabel(phase)+HookOnFailureSuffix]; ok {
+			if policy, err := ParseHookPolicy(value); err == nil {
+				hook.OnFailure = policy
+			}
+		}
+
+		if hooks == nil {
+			hooks = make(map[string]Hook)
+		}
+		hooks[phase] = hook
+	}
+	return hooks
+}
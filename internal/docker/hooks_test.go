@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabels_Hooks(t *testing.T) {
+	parsed := ParseLabels(map[string]string{
+		"com.github.saltbox.hooks.pre_stop":             "exec:/app/flush.sh --all",
+		"com.github.saltbox.hooks.pre_stop.timeout":     "120",
+		"com.github.saltbox.hooks.pre_stop.on_failure":  "FAIL",
+		"com.github.saltbox.hooks.post_start":           "http://:8080/warmup",
+		"com.github.saltbox.hooks.post_start.timeout":   "-5",
+		"com.github.saltbox.hooks.post_stop.on_failure": "fail", // No action
+	})
+
+	assert.Equal(t, map[string]Hook{
+		HookPreStop:   {Action: "exec:/app/flush.sh --all", Timeout: 120, OnFailure: HookPolicyFail},
+		HookPostStart: {Action: "http://:8080/warmup", Timeout: DefaultHookTimeout, OnFailure: HookPolicyContinue},
+	}, parsed.Hooks)
+
+	assert.Nil(t, ParseLabels(map[string]string{}).Hooks)
+}
+
+func TestParseHookAction(t *testing.T) {
+	action, err := ParseHookAction("exec: /app/flush.sh  --all ")
+	require.NoError(t, err)
+	assert.Equal(t, HookKindExec, action.Kind)
+	assert.Equal(t, []string{"/app/flush.sh", "--all"}, action.Command)
+	assert.True(t, action.TargetsContainer())
+
+	// Quotes are not interpreted
+	action, err = ParseHookAction(`exec:sh -c "echo hi"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", `"echo`, `hi"`}, action.Command)
+
+	action, err = ParseHookAction("http://:8080/warmup")
+	require.NoError(t, err)
+	assert.Equal(t, HookKindHTTP, action.Kind)
+	assert.Equal(t, "GET", action.Method)
+	assert.Equal(t, "8080", action.URL.Port())
+	assert.True(t, action.TargetsContainer())
+
+	action, err = ParseHookAction("post https://backup.local/notify")
+	require.NoError(t, err)
+	assert.Equal(t, "POST", action.Method)
+	assert.Equal(t, "backup.local", action.URL.Hostname())
+	assert.False(t, action.TargetsContainer())
+
+	for _, invalid := range []string{"", "exec:", "/app/flush.sh", "ftp://host/file", "http:///path", "GET"} {
+		_, err := ParseHookAction(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestValidateHook(t *testing.T) {
+	_, err := ValidateHook(HookPreStop, Hook{Action: "exec:/app/flush.sh"})
+	assert.NoError(t, err)
+	_, err = ValidateHook(HookPreStart, Hook{Action: "http://vault:8200/unseal"})
+	assert.NoError(t, err)
+
+	// Containers that are not running cannot run commands or serve requests
+	_, err = ValidateHook(HookPreStart, Hook{Action: "exec:/app/prepare.sh"})
+	assert.ErrorContains(t, err, "explicit host")
+	_, err = ValidateHook(HookPostStop, Hook{Action: "http://:8080/done"})
+	assert.ErrorContains(t, err, "explicit host")
+}
+
+func TestParseHookSettings(t *testing.T) {
+	policy, err := ParseHookPolicy(" Continue")
+	require.NoError(t, err)
+	assert.Equal(t, HookPolicyContinue, policy)
+	_, err = ParseHookPolicy("retry")
+	assert.Error(t, err)
+
+	timeout, err := ParseHookTimeout("45")
+	require.NoError(t, err)
+	assert.Equal(t, 45, timeout)
+	for _, invalid := range []string{"0", "-1", "soon"} {
+		_, err := ParseHookTimeout(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -1,17 +1,42 @@
 package docker
 
 import (
+	"slices"
 	"strconv"
 	"strings"
 )
 
+// Label keys read by ParseLabels
+const (
+	LabelPrefix                = "com.github.saltbox."
+	LabelManaged               = LabelPrefix + "saltbox_managed"
+	LabelController            = LabelPrefix + "saltbox_controller"
+	LabelDependsOn             = LabelPrefix + "depends_on"
+	LabelDependsOnDelay        = LabelPrefix + "depends_on.delay"
+	LabelDependsOnHealthchecks = LabelPrefix + "depends_on.healthchecks"
+	LabelDependsOnOptional     = LabelPrefix + "depends_on.optional"
+	LabelPriority              = LabelPrefix + "priority"
+	LabelGroup                 = LabelPrefix + "group"             // Concurrency group, limited by group_concurrency; also selectable as a group
+	LabelGroupConcurrency      = LabelPrefix + "group_concurrency" // Limit of the concurrency group
+	LabelGroups                = LabelPrefix + "groups"            // Selection groups for group= targets and group: ignores; no limit
+)
+
+// OptionalMarker marks an optional entry in the depends_on label, e.g. "?authelia"
+const OptionalMarker = "?"
+
 // ContainerLabels represents parsed Saltbox labels
 type ContainerLabels struct {
 	Managed               bool
-	DependsOn             []string
+	DependsOn             []string // Required dependencies
+	OptionalDependsOn     []string // Optional dependencies: "?name" in depends_on, or listed in depends_on.optional
 	DependsOnDelay        int
 	DependsOnHealthchecks bool
 	ControllerEnabled     bool
+	Priority              int             // Higher is scheduled first within a batch
+	Group                 string          // Concurrency group
+	GroupConcurrency      int             // Maximum concurrent operations in Group (0 = unlimited)
+	Groups                []string        // Selection groups, e.g. media or downloaders
+	Hooks                 map[string]Hook // Lifecycle hooks by phase
 }
 
 // ParseLabels extracts and parses Saltbox-specific labels from a container
@@ -25,62 +50,130 @@ func ParseLabels(labels map[string]string) *ContainerLabels {
 	}
 
 	// Check if container is managed
-	if managed, ok := labels["com.github.saltbox.saltbox_managed"]; ok {
+	if managed, ok := labels[LabelManaged]; ok {
 		parsed.Managed = strings.ToLower(managed) == "true"
 	}
 
 	// Check if controller is enabled (opt-out mechanism)
-	if controller, ok := labels["com.github.saltbox.saltbox_controller"]; ok {
+	if controller, ok := labels[LabelController]; ok {
 		parsed.ControllerEnabled = strings.ToLower(controller) != "false"
 	}
 
 	// Parse dependencies
-	if dependsOn, ok := labels["com.github.saltbox.depends_on"]; ok && dependsOn != "" {
-		// Split by comma and trim whitespace
-		deps := strings.SplitSeq(dependsOn, ",")
-		for dep := range deps {
-			trimmed := strings.TrimSpace(dep)
-			if trimmed != "" {
-				parsed.DependsOn = append(parsed.DependsOn, trimmed)
-			}
+	var optional []string
+	for _, dep := range splitList(labels[LabelDependsOn]) {
+		if name, ok := strings.CutPrefix(dep, OptionalMarker); ok {
+			optional = append(optional, strings.TrimSpace(name))
+		} else {
+			parsed.DependsOn = append(parsed.DependsOn, dep)
+		}
+	}
+	for _, dep := range splitList(labels[LabelDependsOnOptional]) {
+		optional = append(optional, strings.TrimSpace(strings.TrimPrefix(dep, OptionalMarker)))
+	}
+
+	// A dependency that is also required is not optional
+	for _, name := range optional {
+		if name != "" && !slices.Contains(parsed.DependsOn, name) && !slices.Contains(parsed.OptionalDependsOn, name) {
+			parsed.OptionalDependsOn = append(parsed.OptionalDependsOn, name)
 		}
 	}
 
 	// Parse startup delay
-	if delay, ok := labels["com.github.saltbox.depends_on.delay"]; ok {
+	if delay, ok := labels[LabelDependsOnDelay]; ok {
 		if delayInt, err := strconv.Atoi(delay); err == nil && delayInt > 0 {
 			parsed.DependsOnDelay = delayInt
 		}
 	}
 
 	// Parse healthcheck flag
-	if healthchecks, ok := labels["com.github.saltbox.depends_on.healthchecks"]; ok {
+	if healthchecks, ok := labels[LabelDependsOnHealthchecks]; ok {
 		parsed.DependsOnHealthchecks = strings.ToLower(healthchecks) == "true"
 	}
 
+	// Parse scheduling priority
+	if priority, ok := labels[LabelPriority]; ok {
+		if priorityInt, err := strconv.Atoi(priority); err == nil {
+			parsed.Priority = priorityInt
+		}
+	}
+
+	// Parse concurrency group
+	parsed.Group = strings.TrimSpace(labels[LabelGroup])
+	if limit, ok := labels[LabelGroupConcurrency]; ok {
+		if limitInt, err := strconv.Atoi(limit); err == nil && limitInt > 0 {
+			parsed.GroupConcurrency = limitInt
+		}
+	}
+
+	// Parse selection groups
+	for _, group := range splitList(labels[LabelGroups]) {
+		if !slices.Contains(parsed.Groups, group) {
+			parsed.Groups = append(parsed.Groups, group)
+		}
+	}
+
+	// Parse lifecycle hooks
+	parsed.Hooks = parseHooks(labels)
+
 	return parsed
 }
 
+// splitList splits a comma-separated label value, trimming whitespace and
+// dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
 // IsManaged returns true if the container should be managed by the controller
 func (l *ContainerLabels) IsManaged() bool {
 	return l.Managed && l.ControllerEnabled
 }
 
-// HasDependencies returns true if the container has any dependencies
+// UnmanagedReason explains why IsManaged is false, or returns "" if it is true
+func (l *ContainerLabels) UnmanagedReason() string {
+	switch {
+	case !l.Managed:
+		return "saltbox_managed label is not true"
+	case !l.ControllerEnabled:
+		return "saltbox_controller label is false"
+	default:
+		return ""
+	}
+}
+
+// HasDependencies returns true if the container has any required or optional dependencies
 func (l *ContainerLabels) HasDependencies() bool {
-	return len(l.DependsOn) > 0
+	return len(l.DependsOn) > 0 || len(l.OptionalDependsOn) > 0
 }
 
-// GetDependencies returns the list of container names this container depends on
+// GetDependencies returns the names of the containers this container requires
 func (l *ContainerLabels) GetDependencies() []string {
 	return l.DependsOn
 }
 
+// GetOptionalDependencies returns the names of the containers this container
+// is ordered after when they exist, but doesn't require
+func (l *ContainerLabels) GetOptionalDependencies() []string {
+	return l.OptionalDependsOn
+}
+
 // GetStartupDelay returns the startup delay in seconds
 func (l *ContainerLabels) GetStartupDelay() int {
 	return l.DependsOnDelay
 }
 
+// GetPriority returns the scheduling priority (default 0)
+func (l *ContainerLabels) GetPriority() int {
+	return l.Priority
+}
+
 // ShouldWaitForHealthcheck returns true if we should wait for health checks
 func (l *ContainerLabels) ShouldWaitForHealthcheck() bool {
 	return l.DependsOnHealthchecks
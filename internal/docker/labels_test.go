@@ -108,6 +108,87 @@ func TestParseLabels(t *testing.T) {
 				ControllerEnabled:     true,
 			},
 		},
+		{
+			name: "priority",
+			labels: map[string]string{
+				"com.github.saltbox.saltbox_managed": "true",
+				"com.github.saltbox.priority":        "-5",
+			},
+			expected: &ContainerLabels{
+				Managed:           true,
+				DependsOn:         []string{},
+				ControllerEnabled: true,
+				Priority:          -5,
+			},
+		},
+		{
+			name: "invalid priority ignored",
+			labels: map[string]string{
+				"com.github.saltbox.saltbox_managed": "true",
+				"com.github.saltbox.priority":        "high",
+			},
+			expected: &ContainerLabels{
+				Managed:           true,
+				DependsOn:         []string{},
+				ControllerEnabled: true,
+			},
+		},
+		{
+			name: "optional dependencies",
+			labels: map[string]string{
+				"com.github.saltbox.saltbox_managed":     "true",
+				"com.github.saltbox.depends_on":          "postgres, ?authelia,? redis",
+				"com.github.saltbox.depends_on.optional": "authelia,postgres,?crowdsec",
+			},
+			expected: &ContainerLabels{
+				Managed:           true,
+				DependsOn:         []string{"postgres"},
+				OptionalDependsOn: []string{"authelia", "redis", "crowdsec"},
+				ControllerEnabled: true,
+			},
+		},
+		{
+			name: "selection groups",
+			labels: map[string]string{
+				"com.github.saltbox.saltbox_managed": "true",
+				"com.github.saltbox.groups":          "downloaders, torrents,,downloaders",
+			},
+			expected: &ContainerLabels{
+				Managed:           true,
+				DependsOn:         []string{},
+				ControllerEnabled: true,
+				Groups:            []string{"downloaders", "torrents"},
+			},
+		},
+		{
+			name: "concurrency group",
+			labels: map[string]string{
+				"com.github.saltbox.saltbox_managed":   "true",
+				"com.github.saltbox.group":             " media ",
+				"com.github.saltbox.group_concurrency": "2",
+			},
+			expected: &ContainerLabels{
+				Managed:           true,
+				DependsOn:         []string{},
+				ControllerEnabled: true,
+				Group:             "media",
+				GroupConcurrency:  2,
+			},
+		},
+		{
+			name: "non-positive group concurrency ignored",
+			labels: map[string]string{
+				"com.github.saltbox.saltbox_managed":   "true",
+				"com.github.saltbox.group":             "media",
+				"com.github.saltbox.group_concurrency": "0",
+			},
+			expected: &ContainerLabels{
+				Managed:           true,
+				DependsOn:         []string{},
+				ControllerEnabled: true,
+				Group:             "media",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -123,6 +204,7 @@ func TestContainerLabels_IsManaged(t *testing.T) {
 		name     string
 		labels   *ContainerLabels
 		expected bool
+		reason   string
 	}{
 		{
 			name: "managed and enabled",
@@ -139,6 +221,7 @@ func TestContainerLabels_IsManaged(t *testing.T) {
 				ControllerEnabled: false,
 			},
 			expected: false,
+			reason:   "saltbox_controller label is false",
 		},
 		{
 			name: "not managed",
@@ -147,6 +230,7 @@ func TestContainerLabels_IsManaged(t *testing.T) {
 				ControllerEnabled: true,
 			},
 			expected: false,
+			reason:   "saltbox_managed label is not true",
 		},
 	}
 
@@ -154,6 +238,7 @@ func TestContainerLabels_IsManaged(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result := tt.labels.IsManaged()
 			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.reason, tt.labels.UnmanagedReason())
 		})
 	}
 }
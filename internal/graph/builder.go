@@ -3,7 +3,9 @@ package graph
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
 
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
@@ -20,6 +22,7 @@ type Builder struct {
 // DockerClient interface for container operations needed by the builder
 type DockerClient interface {
 	GetContainer(ctx context.Context, containerID string) (*client.ContainerInspectResult, error)
+	ContainerExists(ctx context.Context, containerNameOrID string) (bool, error)
 }
 
 // NewBuilder creates a new graph builder
@@ -32,6 +35,8 @@ func NewBuilder(dockerClient DockerClient, logger *logger.Logger) *Builder {
 
 // Build creates a dependency graph from a list of containers
 func (b *Builder) Build(ctx context.Context, containers []container.Summary) (*Graph, error) {
+	log := logger.FromContext(ctx, b.logger)
+
 	graph := &Graph{
 		Nodes: make(map[string]*Node),
 	}
@@ -45,18 +50,23 @@ func (b *Builder) Build(ctx context.Context, containers []container.Summary) (*G
 
 		// Skip if not managed or controller disabled
 		if !labels.IsManaged() {
-			b.logger.Debug("Skipping unmanaged container",
+			log.Debug("Skipping unmanaged container",
 				"container", node.Name)
 			continue
 		}
 
 		node.StartupDelay = labels.GetStartupDelay()
 		node.WaitForHealthcheck = labels.ShouldWaitForHealthcheck()
+		node.Priority = labels.GetPriority()
+		node.Group = labels.Group
+		node.GroupConcurrency = labels.GroupConcurrency
+		node.Groups = labels.Groups
+		node.Hooks = labels.Hooks
 
 		// Fetch container details to get StopTimeout
 		inspectResult, err := b.docker.GetContainer(ctx, c.ID)
 		if err != nil {
-			b.logger.Warn("Failed to inspect container for timeout",
+			log.Warn("Failed to inspect container for timeout",
 				"container", node.Name,
 				"error", err)
 		} else if inspectResult.Container.Config != nil {
@@ -65,13 +75,20 @@ func (b *Builder) Build(ctx context.Context, containers []container.Summary) (*G
 
 		graph.Nodes[node.Name] = node
 
-		b.logger.Debug("Added container to graph",
+		log.Debug("Added container to graph",
 			"container", node.Name,
 			"startup_delay", node.StartupDelay,
 			"wait_healthcheck", node.WaitForHealthcheck,
 			"stop_timeout", node.StopTimeout)
 	}
 
+	// Containers that exist, whether or not the controller manages them
+	listed := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		listed[strings.TrimPrefix(c.Names[0], "/")] = true
+	}
+	listedNames := slices.Sorted(maps.Keys(listed))
+
 	// Second pass: Build dependency relationships
 	for _, c := range containers {
 		name := c.Names[0]
@@ -85,13 +102,17 @@ func (b *Builder) Build(ctx context.Context, containers []container.Summary) (*G
 		}
 
 		labels := docker.ParseLabels(c.Labels)
-		dependencies := labels.GetDependencies()
+		dependencies := expandDependencies(log, node, labels.GetDependencies(), listedNames)
 
 		for _, depName := range dependencies {
-			parent, exists := graph.Nodes[depName]
-			if !exists {
+			if !listed[depName] {
+				b.classifyUnlisted(ctx, node, depName)
+			}
+
+			parent, inGraph := graph.Nodes[depName]
+			if !inGraph {
 				// Create placeholder node for missing dependency
-				b.logger.Warn("Dependency not found, creating placeholder",
+				log.Warn("Dependency not found, creating placeholder",
 					"container", node.Name,
 					"dependency", depName)
 
@@ -101,20 +122,90 @@ func (b *Builder) Build(ctx context.Context, containers []container.Summary) (*G
 
 			node.AddParent(parent)
 
-			b.logger.Debug("Added dependency",
+			log.Debug("Added dependency",
 				"container", node.Name,
 				"depends_on", parent.Name,
 				"placeholder", parent.IsPlaceholder)
 		}
+
+		// Optional dependencies only order containers that exist
+		for _, depName := range expandDependencies(log, node, labels.GetOptionalDependencies(), listedNames) {
+			parent, inGraph := graph.Nodes[depName]
+			if slices.Contains(dependencies, depName) {
+				continue // Also required
+			}
+			if !inGraph || parent.IsPlaceholder {
+				log.Debug("Optional dependency not managed, ignoring",
+					"container", node.Name,
+					"dependency", depName)
+				continue
+			}
+
+			node.AddOptionalParent(parent)
+
+			log.Debug("Added optional dependency",
+				"container", node.Name,
+				"depends_on", parent.Name)
+		}
 	}
 
-	b.logger.Info("Dependency graph built",
+	log.Info("Dependency graph built",
 		"total_nodes", len(graph.Nodes),
 		"managed_containers", b.countRealNodes(graph))
 
 	return graph, nil
 }
 
+// expandDependencies replaces dependency patterns with the names of the listed
+// containers they match, recording each expansion on node, and drops
+// duplicates. A container never matches its own patterns. Invalid patterns
+// are logged and dropped.
+func expandDependencies(log *logger.Logger, node *Node, dependencies []string, names []string) []string {
+	var expanded []string
+	add := func(name string) {
+		if !slices.Contains(expanded, name) {
+			expanded = append(expanded, name)
+		}
+	}
+
+	for _, dep := range dependencies {
+		if !IsPattern(dep) {
+			add(dep)
+			continue
+		}
+
+		matches, err := ExpandPattern(dep, names)
+		if err != nil {
+			log.Warn("Invalid dependency pattern, ignoring",
+				"container", node.Name,
+				"pattern", dep,
+				"error", err)
+			continue
+		}
+		matches = slices.DeleteFunc(append([]string{}, matches...), func(name string) bool { return name == node.Name })
+
+		if node.DependencyPatterns == nil {
+			node.DependencyPatterns = make(map[string][]string)
+		}
+		node.DependencyPatterns[dep] = matches
+
+		if len(matches) == 0 {
+			log.Warn("Dependency pattern matches no containers",
+				"container", node.Name,
+				"pattern", dep)
+		} else {
+			log.Debug("Expanded dependency pattern",
+				"container", node.Name,
+				"pattern", dep,
+				"matches", matches)
+		}
+		for _, name := range matches {
+			add(name)
+		}
+	}
+	return expanded
+}
+
 // countRealNodes counts non-placeholder nodes
 func (b *Builder) countRealNodes(g *Graph) int {
 	count := 0
@@ -154,76 +245,47 @@ func (g *Graph) GetLeafNodes() []*Node {
 	return leaves
 }
 
-// HasCycles checks if the graph contains circular dependencies
+// HasCycles checks if the graph contains circular dependencies, returning
+// the path of the first cycle found. Use Cycles to get all of them.
 func (g *Graph) HasCycles() (bool, []string) {
-	// Reset visited flags
-	for _, node := range g.Nodes {
-		node.visited = false
-		node.inStack = false
-	}
-
-	var cycle []string
-
-	// DFS to detect cycles
-	var dfs func(*Node) bool
-	dfs = func(node *Node) bool {
-		if node.inStack {
-			// Found a cycle
-			cycle = append(cycle, node.Name)
-			return true
-		}
-
-		if node.visited {
-			return false
-		}
-
-		node.visited = true
-		node.inStack = true
-
-		if slices.ContainsFunc(node.Children, dfs) {
-			cycle = append(cycle, node.Name)
-			return true
-		}
-
-		node.inStack = false
-		return false
-	}
-
-	for _, node := range g.Nodes {
-		if !node.visited {
-			if dfs(node) {
-				// Reverse cycle to show proper order
-				for i := 0; i < len(cycle)/2; i++ {
-					cycle[i], cycle[len(cycle)-1-i] = cycle[len(cycle)-1-i], cycle[i]
-				}
-				return true, cycle
-			}
-		}
+	cycles := g.Cycles()
+	if len(cycles) == 0 {
+		return false, nil
 	}
-
-	return false, nil
+	return true, cycles[0].Path
 }
 
-// Validate checks the graph for errors (cycles, invalid dependencies, etc.)
+// Validate checks the graph for errors. Every dependency cycle is reported;
+// missing dependencies (placeholders) are allowed.
 func (g *Graph) Validate() error {
-	// Check for circular dependencies
-	hasCycle, cycle := g.HasCycles()
-	if hasCycle {
-		return fmt.Errorf("circular dependency detected: %v", cycle)
+	cycles := g.Cycles()
+	if len(cycles) == 0 {
+		return nil
 	}
 
-	// Count placeholder nodes
-	placeholders := 0
-	for _, node := range g.Nodes {
-		if node.IsPlaceholder {
-			placeholders++
-		}
+	paths := make([]string, len(cycles))
+	for i, cycle := range cycles {
+		paths[i] = cycle.String()
 	}
+	return fmt.Errorf("circular dependency detected: %s", strings.Join(paths, "; "))
+}
 
-	if placeholders > 0 {
-		// This is a warning, not an error - we allow missing dependencies
-		return nil
-	}
+// classifyUnlisted records a required dependency that isn't a managed
+// container as missing, if no such container exists, or as unmanaged. A
+// dependency whose lookup fails is neither, so it never fails the dependent.
+func (b *Builder) classifyUnlisted(ctx context.Context, node *Node, depName string) {
+	log := logger.FromContext(ctx, b.logger)
 
-	return nil
+	exists, err := b.docker.ContainerExists(ctx, depName)
+	switch {
+	case err != nil:
+		log.Warn("Failed to look up dependency",
+			"container", node.Name,
+			"dependency", depName,
+			"error", err)
+	case exists:
+		node.UnmanagedDependencies = append(node.UnmanagedDependencies, depName)
+	default:
+		node.MissingDependencies = append(node.MissingDependencies, depName)
+	}
 }
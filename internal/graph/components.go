@@ -1,8 +1,15 @@
 package graph
 
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
 // ComponentBatches represents a single connected component with its batches
 type ComponentBatches struct {
-	Batches [][]*Node // Batches of nodes that can run in parallel within this component
+	Batches  [][]*Node // Batches of nodes that can run in parallel within this component
+	Priority int       // Highest priority of the component's containers
 }
 
 // GetConnectedComponents identifies independent subgraphs in the dependency graph.
@@ -12,6 +19,7 @@ type ComponentBatches struct {
 //
 // Returns a slice of components, where each component contains batches of nodes.
 // Nodes within the same batch have no dependencies on each other and can run in parallel.
+// Components are ordered by descending priority, then by their first container's name.
 func (g *Graph) GetConnectedComponents() ([]*ComponentBatches, error) {
 	// Reset visited flags
 	for _, node := range g.Nodes {
@@ -21,7 +29,7 @@ func (g *Graph) GetConnectedComponents() ([]*ComponentBatches, error) {
 	var components []*ComponentBatches
 
 	// Find all components using DFS
-	for _, node := range g.Nodes {
+	for _, node := range sortedNodes(g.nodeList()) {
 		if !node.visited && !node.IsPlaceholder {
 			component := g.findComponent(node)
 			if len(component) > 0 {
@@ -30,14 +38,29 @@ func (g *Graph) GetConnectedComponents() ([]*ComponentBatches, error) {
 				if err != nil {
 					return nil, err
 				}
-				components = append(components, &ComponentBatches{Batches: batches})
+				components = append(components, newComponentBatches(batches))
 			}
 		}
 	}
 
+	slices.SortStableFunc(components, func(a, b *ComponentBatches) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+
 	return components, nil
 }
 
+// newComponentBatches wraps a component's batches, recording its priority
+func newComponentBatches(batches [][]*Node) *ComponentBatches {
+	component := &ComponentBatches{Batches: batches, Priority: math.MinInt}
+	for _, batch := range batches {
+		for _, node := range batch {
+			component.Priority = max(component.Priority, node.Priority)
+		}
+	}
+	return component
+}
+
 // findComponent performs DFS to find all nodes connected to the starting node
 func (g *Graph) findComponent(start *Node) []*Node {
 	var component []*Node
@@ -108,7 +131,7 @@ func (g *Graph) GetConnectedComponentsForShutdown() ([]*ComponentBatches, error)
 		for j, batch := range comp.Batches {
 			reversedBatches[len(comp.Batches)-1-j] = batch
 		}
-		shutdownComponents[i] = &ComponentBatches{Batches: reversedBatches}
+		shutdownComponents[i] = &ComponentBatches{Batches: reversedBatches, Priority: comp.Priority}
 	}
 
 	return shutdownComponents, nil
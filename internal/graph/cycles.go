@@ -0,0 +1,195 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+)
+
+// Cycle is a set of containers that all depend on each other, directly or
+// indirectly (a strongly connected component of the graph)
+type Cycle struct {
+	Members []string // Sorted names
+	Path    []string // One dependency loop: each container depends on the next, and the last is the first
+}
+
+// String formats the cycle's path as "a -> b -> a"
+func (c Cycle) String() string {
+	return strings.Join(c.Path, " -> ")
+}
+
+// Cycles finds every dependency cycle in the graph using Tarjan's strongly
+// connected components algorithm. Each component of two or more containers,
+// and each container depending on itself, is reported once, ordered by the
+// first member's name.
+func (g *Graph) Cycles() []Cycle {
+	index := make(map[*Node]int)
+	lowlink := make(map[*Node]int)
+	onStack := make(map[*Node]bool)
+	var stack []*Node
+	var cycles []Cycle
+
+	var connect func(*Node)
+	connect = func(node *Node) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, parent := range g.parentsOf(node) {
+			if _, visited := index[parent]; !visited {
+				connect(parent)
+				lowlink[node] = min(lowlink[node], lowlink[parent])
+			} else if onStack[parent] {
+				lowlink[node] = min(lowlink[node], index[parent])
+			}
+		}
+
+		if lowlink[node] != index[node] {
+			return
+		}
+
+		// node is the root of a component; pop it off the stack
+		var component []*Node
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[member] = false
+			component = append(component, member)
+			if member == node {
+				break
+			}
+		}
+
+		if len(component) > 1 || slices.Contains(g.parentsOf(node), node) {
+			cycles = append(cycles, g.newCycle(component))
+		}
+	}
+
+	for _, name := range g.sortedNames() {
+		if _, visited := index[g.Nodes[name]]; !visited {
+			connect(g.Nodes[name])
+		}
+	}
+
+	slices.SortFunc(cycles, func(a, b Cycle) int {
+		return strings.Compare(a.Members[0], b.Members[0])
+	})
+	return cycles
+}
+
+// newCycle describes a strongly connected component, tracing the shortest
+// dependency loop through its alphabetically first member
+func (g *Graph) newCycle(component []*Node) Cycle {
+	members := make(map[*Node]bool, len(component))
+	names := make([]string, 0, len(component))
+	for _, node := range component {
+		members[node] = true
+		names = append(names, node.Name)
+	}
+	slices.Sort(names)
+
+	// Breadth-first search along dependencies back to the start
+	start := g.Nodes[names[0]]
+	previous := map[*Node]*Node{}
+	queue := []*Node{start}
+	var last *Node
+	for len(queue) > 0 && last == nil {
+		node := queue[0]
+		queue = queue[1:]
+		for _, parent := range g.parentsOf(node) {
+			if parent == start {
+				last = node
+				break
+			}
+			if _, seen := previous[parent]; !seen && members[parent] {
+				previous[parent] = node
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	path := []string{start.Name}
+	for node := last; node != start; node = previous[node] {
+		path = append(path, node.Name)
+	}
+	slices.Reverse(path[1:])
+	path = append(path, start.Name)
+
+	return Cycle{Members: names, Path: path}
+}
+
+// QuarantineCycles separates the connected components that contain a
+// dependency cycle from the rest of the graph. It returns a copy of the graph
+// without them, and the cycle responsible for each quarantined container.
+// If there are no cycles, the graph itself is returned.
+func (g *Graph) QuarantineCycles() (*Graph, map[string]Cycle) {
+	cycles := g.Cycles()
+	if len(cycles) == 0 {
+		return g, nil
+	}
+
+	quarantined := make(map[string]Cycle)
+	for _, cycle := range cycles {
+		// Containers in a cycle are blamed on their own cycle
+		for _, name := range cycle.Members {
+			quarantined[name] = cycle
+		}
+	}
+	for _, cycle := range cycles {
+		for _, node := range g.componentOf(g.Nodes[cycle.Members[0]]) {
+			if _, ok := quarantined[node.Name]; !ok {
+				quarantined[node.Name] = cycle
+			}
+		}
+	}
+
+	keep := make(map[string]bool, len(g.Nodes))
+	for name := range g.Nodes {
+		if _, ok := quarantined[name]; !ok {
+			keep[name] = true
+		}
+	}
+	return g.subgraph(keep), quarantined
+}
+
+// componentOf returns the containers connected to start through dependencies
+// in either direction, not counting placeholders
+func (g *Graph) componentOf(start *Node) []*Node {
+	seen := map[*Node]bool{start: true}
+	component := []*Node{start}
+
+	for i := 0; i < len(component); i++ {
+		node := component[i]
+		for _, related := range slices.Concat(g.parentsOf(node), g.childrenOf(node)) {
+			if !seen[related] && !related.IsPlaceholder {
+				seen[related] = true
+				component = append(component, related)
+			}
+		}
+	}
+	return component
+}
+
+// parentsOf returns the parents of node that belong to this graph
+func (g *Graph) parentsOf(node *Node) []*Node {
+	return slices.DeleteFunc(slices.Clone(node.Parents), func(parent *Node) bool {
+		return g.Nodes[parent.Name] != parent
+	})
+}
+
+// childrenOf returns the children of node that belong to this graph
+func (g *Graph) childrenOf(node *Node) []*Node {
+	return slices.DeleteFunc(slices.Clone(node.Children), func(child *Node) bool {
+		return g.Nodes[child.Name] != child
+	})
+}
+
+// sortedNames returns the names of all nodes in alphabetical order
+func (g *Graph) sortedNames() []string {
+	names := make([]string, 0, len(g.Nodes))
+	for name := range g.Nodes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
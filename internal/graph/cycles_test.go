@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTestGraph(t *testing.T, containers ...container.Summary) *Graph {
+	t.Helper()
+
+	log, _ := logger.New(true)
+	g, err := NewBuilder(&mockDockerClient{}, log).Build(context.Background(), containers)
+	require.NoError(t, err)
+	return g
+}
+
+func TestGraph_Cycles(t *testing.T) {
+	g := buildTestGraph(t,
+		// a -> b -> c -> a, with d hanging off the cycle
+		createTestContainer("a", true, []string{"b"}, 0, false),
+		createTestContainer("b", true, []string{"c"}, 0, false),
+		createTestContainer("c", true, []string{"a"}, 0, false),
+		createTestContainer("d", true, []string{"a"}, 0, false),
+		// x depends on itself
+		createTestContainer("x", true, []string{"x"}, 0, false),
+		// y <-> z
+		createTestContainer("y", true, []string{"z"}, 0, false),
+		createTestContainer("z", true, []string{"y", "postgres"}, 0, false),
+		// Acyclic
+		createTestContainer("postgres", true, nil, 0, false),
+		createTestContainer("app", true, []string{"postgres"}, 0, false),
+	)
+
+	cycles := g.Cycles()
+	require.Len(t, cycles, 3)
+
+	assert.Equal(t, []string{"a", "b", "c"}, cycles[0].Members)
+	assert.Equal(t, []string{"a", "b", "c", "a"}, cycles[0].Path)
+	assert.Equal(t, "a -> b -> c -> a", cycles[0].String())
+
+	assert.Equal(t, []string{"x"}, cycles[1].Members)
+	assert.Equal(t, []string{"x", "x"}, cycles[1].Path)
+
+	assert.Equal(t, []string{"y", "z"}, cycles[2].Members)
+	assert.Equal(t, []string{"y", "z", "y"}, cycles[2].Path)
+
+	hasCycle, path := g.HasCycles()
+	assert.True(t, hasCycle)
+	assert.Equal(t, cycles[0].Path, path)
+
+	err := g.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a -> b -> c -> a; x -> x; y -> z -> y")
+}
+
+func TestGraph_Cycles_Acyclic(t *testing.T) {
+	g := buildTestGraph(t,
+		createTestContainer("a", true, nil, 0, false),
+		createTestContainer("b", true, []string{"a", "missing"}, 0, false),
+	)
+
+	assert.Empty(t, g.Cycles())
+	assert.NoError(t, g.Validate())
+}
+
+func TestGraph_QuarantineCycles(t *testing.T) {
+	g := buildTestGraph(t,
+		createTestContainer("a", true, []string{"b"}, 0, false),
+		createTestContainer("b", true, []string{"a", "redis"}, 0, false),
+		createTestContainer("c", true, []string{"a"}, 0, false),
+		createTestContainer("redis", true, nil, 0, false),
+		// Sharing the missing vpn doesn't connect these to each other
+		createTestContainer("app", true, []string{"postgres", "vpn"}, 0, false),
+		createTestContainer("postgres", true, nil, 0, false),
+		createTestContainer("worker", true, []string{"vpn"}, 0, false),
+		createTestContainer("a2", true, []string{"vpn"}, 0, false),
+	)
+
+	acyclic, quarantined := g.QuarantineCycles()
+
+	assert.Len(t, quarantined, 4)
+	for _, name := range []string{"a", "b", "c", "redis"} {
+		assert.Equal(t, []string{"a", "b", "a"}, quarantined[name].Path, name)
+	}
+
+	assert.ElementsMatch(t, []string{"app", "postgres", "worker", "a2", "vpn"}, GetNodeNames(nodes(acyclic)))
+	assert.Empty(t, acyclic.Cycles())
+
+	batches, err := acyclic.GetStartupBatches()
+	require.NoError(t, err)
+	assert.NotEmpty(t, batches)
+
+	// The original graph is left untouched
+	assert.Len(t, g.Nodes, 9)
+}
+
+func TestGraph_QuarantineCycles_NoCycles(t *testing.T) {
+	g := buildTestGraph(t, createTestContainer("a", true, nil, 0, false))
+
+	acyclic, quarantined := g.QuarantineCycles()
+	assert.Same(t, g, acyclic)
+	assert.Empty(t, quarantined)
+}
+
+// nodes returns the nodes of a graph in no particular order
+func nodes(g *Graph) []*Node {
+	result := make([]*Node, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		result = append(result, node)
+	}
+	return result
+}
@@ -23,6 +23,10 @@ func (m *mockDockerClientBench) GetContainer(ctx context.Context, containerID st
 }
 
 // BenchmarkBuildGraph benchmarks dependency graph building
+func (m *mockDockerClientBench) ContainerExists(ctx context.Context, containerNameOrID string) (bool, error) {
+	return false, nil
+}
+
 func BenchmarkBuildGraph(b *testing.B) {
 	log, _ := logger.New(true)
 	mockDocker := &mockDockerClientBench{}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/moby/moby/api/types/container"
@@ -12,7 +13,9 @@ import (
 )
 
 // mockDockerClient is a mock implementation for testing
-type mockDockerClient struct{}
+type mockDockerClient struct {
+	existing []string // Containers that exist beyond the listed ones
+}
 
 func (m *mockDockerClient) GetContainer(ctx context.Context, containerID string) (*client.ContainerInspectResult, error) {
 	// Return a mock result with no StopTimeout set
@@ -25,6 +28,10 @@ func (m *mockDockerClient) GetContainer(ctx context.Context, containerID string)
 	}, nil
 }
 
+func (m *mockDockerClient) ContainerExists(ctx context.Context, containerNameOrID string) (bool, error) {
+	return slices.Contains(m.existing, containerNameOrID), nil
+}
+
 // Helper function to create test containers
 func createTestContainer(name string, managed bool, dependencies []string, delay int, healthcheck bool) container.Summary {
 	labels := map[string]string{}
@@ -148,6 +155,62 @@ func TestBuilder_Build_MissingDependency(t *testing.T) {
 	assert.Equal(t, app, redis.Children[0])
 }
 
+func TestBuilder_Build_OptionalDependencies(t *testing.T) {
+	log, _ := logger.New(true)
+	mockDocker := &mockDockerClient{}
+	builder := NewBuilder(mockDocker, log)
+
+	containers := []container.Summary{
+		createTestContainer("app", true, []string{"postgres", "?authelia", "?redis"}, 0, false),
+		createTestContainer("postgres", true, nil, 0, false),
+		createTestContainer("authelia", true, nil, 0, false),
+	}
+
+	graph, err := builder.Build(context.Background(), containers)
+	require.NoError(t, err)
+
+	assert.Len(t, graph.Nodes, 3, "missing optional dependencies get no placeholder")
+
+	app, _ := graph.GetNode("app")
+	assert.Equal(t, []string{"postgres", "authelia"}, GetNodeNames(app.Parents))
+	assert.True(t, app.IsOptionalParent(graph.Nodes["authelia"]))
+	assert.False(t, app.IsOptionalParent(graph.Nodes["postgres"]))
+	assert.Empty(t, app.MissingDependencies)
+
+	// Optional edges survive selection
+	selected, _ := graph.Select([]string{"app"}, ClosureAncestors)
+	assert.True(t, selected.Nodes["app"].IsOptionalParent(selected.Nodes["authelia"]))
+}
+
+func TestBuilder_Build_MissingDependencies(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{existing: []string{"postgres"}}, log)
+
+	traefik := createTestContainer("traefik", true, nil, 0, false)
+	traefik.Labels["com.github.saltbox.saltbox_controller"] = "false"
+	containers := []container.Summary{
+		createTestContainer("app", true, []string{"redis", "traefik", "postgres"}, 0, false),
+		createTestContainer("worker", true, []string{"redis", "app"}, 0, false),
+		traefik,
+	}
+
+	graph, err := builder.Build(context.Background(), containers)
+	require.NoError(t, err)
+
+	// traefik exists but isn't managed by the controller, so it isn't missing
+	assert.True(t, graph.Nodes["traefik"].IsPlaceholder)
+	assert.Equal(t, []string{"redis"}, graph.Nodes["app"].MissingDependencies)
+	assert.Equal(t, []string{"redis"}, graph.Nodes["worker"].MissingDependencies)
+
+	// postgres exists without the saltbox_managed label, so it isn't missing either
+	assert.True(t, graph.Nodes["postgres"].IsPlaceholder)
+	assert.Equal(t, []string{"postgres"}, graph.Nodes["app"].UnmanagedDependencies)
+
+	// Selection without the placeholder still knows the dependency is missing
+	selected, _ := graph.Select([]string{"worker"}, ClosureNone)
+	assert.Equal(t, []string{"redis"}, selected.Nodes["worker"].MissingDependencies)
+}
+
 func TestBuilder_Build_SkipUnmanaged(t *testing.T) {
 	log, _ := logger.New(true)
 	mockDocker := &mockDockerClient{}
@@ -423,3 +486,66 @@ func TestGetNodeNames(t *testing.T) {
 	names := GetNodeNames(nodes)
 	assert.Equal(t, []string{"a", "b", "c"}, names)
 }
+
+func withPriority(c container.Summary, priority string) container.Summary {
+	c.Labels["com.github.saltbox.priority"] = priority
+	return c
+}
+
+func TestGraph_GetStartupBatches_PriorityOrder(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	containers := []container.Summary{
+		createTestContainer("sonarr", true, []string{"postgres"}, 0, false),
+		withPriority(createTestContainer("traefik", true, nil, 0, false), "100"),
+		createTestContainer("postgres", true, nil, 0, false),
+		createTestContainer("authelia", true, nil, 0, false),
+		withPriority(createTestContainer("radarr", true, []string{"postgres"}, 0, false), "-1"),
+		createTestContainer("lidarr", true, []string{"postgres"}, 0, false),
+	}
+
+	// Map iteration order varies, so build repeatedly
+	for range 10 {
+		graph, err := builder.Build(context.Background(), containers)
+		require.NoError(t, err)
+
+		batches, err := graph.GetStartupBatches()
+		require.NoError(t, err)
+		require.Len(t, batches, 2)
+		assert.Equal(t, []string{"traefik", "authelia", "postgres"}, GetNodeNames(batches[0]))
+		assert.Equal(t, []string{"lidarr", "sonarr", "radarr"}, GetNodeNames(batches[1]))
+
+		sorted, err := graph.TopologicalSort()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"traefik", "authelia", "postgres", "lidarr", "sonarr", "radarr"}, GetNodeNames(sorted.StartupOrder))
+	}
+}
+
+func TestGraph_GetConnectedComponents_PriorityOrder(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	containers := []container.Summary{
+		createTestContainer("app", true, []string{"db"}, 0, false),
+		createTestContainer("db", true, nil, 0, false),
+		createTestContainer("plex", true, nil, 0, false),
+		withPriority(createTestContainer("worker", true, []string{"redis"}, 0, false), "10"),
+		createTestContainer("redis", true, nil, 0, false),
+	}
+
+	for range 10 {
+		graph, err := builder.Build(context.Background(), containers)
+		require.NoError(t, err)
+
+		components, err := graph.GetConnectedComponents()
+		require.NoError(t, err)
+		require.Len(t, components, 3)
+
+		// worker's component first, then ties by name
+		assert.Equal(t, 10, components[0].Priority)
+		assert.Equal(t, "redis", components[0].Batches[0][0].Name)
+		assert.Equal(t, "db", components[1].Batches[0][0].Name)
+		assert.Equal(t, "plex", components[2].Batches[0][0].Name)
+	}
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IsPattern reports whether s is a name pattern rather than a container name:
+// a glob using *, ? or [...], or a regular expression written as /regexp/.
+// Container names can contain none of these characters.
+func IsPattern(s string) bool {
+	return isRegexp(s) || strings.ContainsAny(s, "*?[")
+}
+
+// isRegexp reports whether s is written as /regexp/
+func isRegexp(s string) bool {
+	return len(s) >= 2 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/")
+}
+
+// ValidatePattern reports whether pattern is a valid glob or regular expression
+func ValidatePattern(pattern string) error {
+	_, err := MatchPattern(pattern, "")
+	return err
+}
+
+// MatchPattern reports whether name matches a glob or a /regexp/ pattern.
+// Regular expressions must match the whole name.
+func MatchPattern(pattern, name string) (bool, error) {
+	if isRegexp(pattern) {
+		re, err := regexp.Compile("^(?:" + pattern[1:len(pattern)-1] + ")$")
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+		}
+		return re.MatchString(name), nil
+	}
+
+	matched, err := path.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return matched, nil
+}
+
+// ExpandPattern returns the names that match pattern, sorted
+func ExpandPattern(pattern string, names []string) ([]string, error) {
+	var matches []string
+	for _, name := range names {
+		matched, err := MatchPattern(pattern, name)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, name)
+		}
+	}
+	slices.Sort(matches)
+	return matches, nil
+}
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPattern(t *testing.T) {
+	assert.True(t, IsPattern("postgres-*"))
+	assert.True(t, IsPattern("radarr?"))
+	assert.True(t, IsPattern("sonarr[0-9]"))
+	assert.True(t, IsPattern("/^radarr(4k)?$/"))
+	assert.False(t, IsPattern("postgres"))
+	assert.False(t, IsPattern("/"))
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"postgres-*", "postgres-main", true},
+		{"postgres-*", "postgres", false},
+		{"sonarr*", "sonarr4k", true},
+		{"radarr-?????", "radarr-anime", true},
+		{"/radarr(4k|-anime)?/", "radarr-anime", true},
+		{"/radarr/", "radarr4k", false}, // Regular expressions match the whole name
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			matched, err := MatchPattern(tt.pattern, tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, matched)
+		})
+	}
+
+	assert.Error(t, ValidatePattern("sonarr["))
+	assert.Error(t, ValidatePattern("/radarr(/"))
+	assert.NoError(t, ValidatePattern("sonarr*"))
+}
+
+func TestBuilder_Build_DependencyPatterns(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	containers := []container.Summary{
+		createTestContainer("postgres-main", true, nil, 0, false),
+		createTestContainer("postgres-logs", true, nil, 0, false),
+		createTestContainer("app", true, []string{"postgres-*", "postgres-main", "?redis*", "/mongo.*/"}, 0, false),
+		createTestContainer("postgres-backup", true, []string{"postgres-*"}, 0, false),
+	}
+
+	g, err := builder.Build(context.Background(), containers)
+	require.NoError(t, err)
+
+	app := g.Nodes["app"]
+	assert.Equal(t, []string{"postgres-backup", "postgres-logs", "postgres-main"}, GetNodeNames(app.Parents))
+	assert.Empty(t, app.MissingDependencies, "patterns that match nothing are not missing")
+	assert.Equal(t, map[string][]string{
+		"postgres-*": {"postgres-backup", "postgres-logs", "postgres-main"},
+		"redis*":     {},
+		"/mongo.*/":  {},
+	}, app.DependencyPatterns)
+
+	// A container doesn't match its own pattern
+	backup := g.Nodes["postgres-backup"]
+	assert.Equal(t, []string{"postgres-logs", "postgres-main"}, GetNodeNames(backup.Parents))
+}
+
+func TestGraph_Resolve_Patterns(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	containers := []container.Summary{
+		createTestContainer("sonarr", true, nil, 0, false),
+		createTestContainer("sonarr4k", true, nil, 0, false),
+		createTestContainer("radarr-anime", true, []string{"missing"}, 0, false),
+	}
+
+	g, err := builder.Build(context.Background(), containers)
+	require.NoError(t, err)
+
+	names, unmatched := g.Resolve([]string{"sonarr*", "/radarr-.*/", "missing*", "lidarr*"})
+	assert.Equal(t, []string{"sonarr", "sonarr4k", "radarr-anime"}, names)
+	assert.Equal(t, []string{"missing*", "lidarr*"}, unmatched, "placeholders are not matched")
+}
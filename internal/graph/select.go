@@ -0,0 +1,179 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+)
+
+// Closure selects which related containers are pulled into a selection
+type Closure int
+
+const (
+	// ClosureNone selects only the named containers
+	ClosureNone Closure = iota
+	// ClosureAncestors also selects everything the named containers depend on (for start)
+	ClosureAncestors
+	// ClosureDescendants also selects everything that depends on the named containers (for stop)
+	ClosureDescendants
+)
+
+// groupSelectorPrefixes introduce a group selector in target and ignore lists
+var groupSelectorPrefixes = []string{"group=", "group:"}
+
+// ParseGroupSelector returns the group named by a "group=<name>" or
+// "group:<name>" selector
+func ParseGroupSelector(selector string) (string, bool) {
+	for _, prefix := range groupSelectorPrefixes {
+		if group, ok := strings.CutPrefix(selector, prefix); ok {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+// Resolve expands group selectors and name patterns (globs or /regexp/) into
+// the names of the containers they match, sorted by name. Container names are
+// passed through unchanged, and each name is returned once. Selectors that
+// match no container, or are invalid patterns, are returned as unmatched.
+func (g *Graph) Resolve(selectors []string) (names []string, unmatched []string) {
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	for _, selector := range selectors {
+		var matches []string
+		if group, ok := ParseGroupSelector(selector); ok {
+			matches = g.GroupMembers(group)
+		} else if IsPattern(selector) {
+			matches, _ = ExpandPattern(selector, g.containerNames())
+		} else {
+			add(selector)
+			continue
+		}
+
+		if len(matches) == 0 {
+			unmatched = append(unmatched, selector)
+		}
+		for _, name := range matches {
+			add(name)
+		}
+	}
+	return names, unmatched
+}
+
+// containerNames returns the names of all non-placeholder nodes
+func (g *Graph) containerNames() []string {
+	var names []string
+	for name, node := range g.Nodes {
+		if !node.IsPlaceholder {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GroupMembers returns the names of the containers in a group, sorted. A
+// container's concurrency group counts as one of its selection groups.
+func (g *Graph) GroupMembers(group string) []string {
+	var members []string
+	for name, node := range g.Nodes {
+		if !node.IsPlaceholder && (slices.Contains(node.Groups, group) || node.Group == group) {
+			members = append(members, name)
+		}
+	}
+	slices.Sort(members)
+	return members
+}
+
+// Select returns a new graph containing only the named containers and, depending
+// on closure, their ancestors or descendants. Nodes are copied so the original
+// graph is left untouched; only edges between selected nodes are kept.
+// Names not present in the graph are returned as missing.
+func (g *Graph) Select(names []string, closure Closure) (*Graph, []string) {
+	selected := make(map[string]bool)
+	var missing []string
+
+	var include func(*Node)
+	include = func(node *Node) {
+		if selected[node.Name] {
+			return
+		}
+		selected[node.Name] = true
+
+		switch closure {
+		case ClosureAncestors:
+			for _, parent := range node.Parents {
+				include(parent)
+			}
+		case ClosureDescendants:
+			for _, child := range node.Children {
+				include(child)
+			}
+		}
+	}
+
+	for _, name := range names {
+		node, exists := g.Nodes[name]
+		if !exists || node.IsPlaceholder {
+			missing = append(missing, name)
+			continue
+		}
+		include(node)
+	}
+
+	return g.subgraph(selected), missing
+}
+
+// subgraph returns a copy of the graph with only the named nodes and the
+// edges between them
+func (g *Graph) subgraph(names map[string]bool) *Graph {
+	subgraph := &Graph{
+		Nodes: make(map[string]*Node, len(names)),
+	}
+
+	for name := range names {
+		subgraph.Nodes[name] = g.Nodes[name].copyWithoutEdges()
+	}
+
+	for name := range names {
+		for _, parent := range g.Nodes[name].Parents {
+			if !names[parent.Name] {
+				continue
+			}
+			if g.Nodes[name].IsOptionalParent(parent) {
+				subgraph.Nodes[name].AddOptionalParent(subgraph.Nodes[parent.Name])
+			} else {
+				subgraph.Nodes[name].AddParent(subgraph.Nodes[parent.Name])
+			}
+		}
+	}
+
+	return subgraph
+}
+
+// copyWithoutEdges returns a copy of the node with no parents or children
+func (n *Node) copyWithoutEdges() *Node {
+	return &Node{
+		ID:                    n.ID,
+		Name:                  n.Name,
+		Labels:                n.Labels,
+		IsRunning:             n.IsRunning,
+		IsPlaceholder:         n.IsPlaceholder,
+		Parents:               []*Node{},
+		Children:              []*Node{},
+		MissingDependencies:   n.MissingDependencies,
+		UnmanagedDependencies: n.UnmanagedDependencies,
+		DependencyPatterns:    n.DependencyPatterns,
+		StartupDelay:          n.StartupDelay,
+		WaitForHealthcheck:    n.WaitForHealthcheck,
+		Priority:              n.Priority,
+		Group:                 n.Group,
+		GroupConcurrency:      n.GroupConcurrency,
+		Groups:                n.Groups,
+		Hooks:                 n.Hooks,
+		StopTimeout:           n.StopTimeout,
+		sortIndex:             -1,
+	}
+}
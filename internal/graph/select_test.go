@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Select(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	// postgres <- app <- frontend, redis (independent)
+	containers := []container.Summary{
+		createTestContainer("postgres", true, nil, 0, false),
+		createTestContainer("app", true, []string{"postgres"}, 0, false),
+		createTestContainer("frontend", true, []string{"app"}, 0, false),
+		createTestContainer("redis", true, nil, 0, false),
+	}
+
+	g, err := builder.Build(context.Background(), containers)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		targets     []string
+		closure     Closure
+		wantNodes   []string
+		wantMissing []string
+	}{
+		{"only target", []string{"app"}, ClosureNone, []string{"app"}, nil},
+		{"ancestors", []string{"app"}, ClosureAncestors, []string{"app", "postgres"}, nil},
+		{"descendants", []string{"app"}, ClosureDescendants, []string{"app", "frontend"}, nil},
+		{"missing target", []string{"redis", "nope"}, ClosureAncestors, []string{"redis"}, []string{"nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, missing := g.Select(tt.targets, tt.closure)
+
+			var names []string
+			for name := range selected.Nodes {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tt.wantNodes, names)
+			assert.Equal(t, tt.wantMissing, missing)
+		})
+	}
+
+	// Edges outside the selection are dropped and the original graph is untouched
+	selected, _ := g.Select([]string{"app"}, ClosureAncestors)
+	app, _ := selected.GetNode("app")
+	assert.Len(t, app.Parents, 1)
+	assert.Empty(t, app.Children)
+
+	original, _ := g.GetNode("app")
+	assert.Len(t, original.Children, 1)
+}
+
+func TestGraph_Resolve(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	withGroups := func(c container.Summary, groups string) container.Summary {
+		c.Labels["com.github.saltbox.groups"] = groups
+		return c
+	}
+	containers := []container.Summary{
+		createTestContainer("postgres", true, nil, 0, false),
+		withGroups(createTestContainer("sabnzbd", true, nil, 0, false), "downloaders"),
+		withGroups(createTestContainer("qbittorrent", true, []string{"postgres"}, 0, false), "downloaders, torrents"),
+		withGroups(createTestContainer("plex", true, nil, 0, false), "media"),
+	}
+
+	g, err := builder.Build(context.Background(), containers)
+	require.NoError(t, err)
+
+	names, unmatched := g.Resolve([]string{"group=downloaders", "plex", "group:torrents", "group=nope"})
+	assert.Equal(t, []string{"qbittorrent", "sabnzbd", "plex"}, names)
+	assert.Equal(t, []string{"group=nope"}, unmatched)
+
+	// Closure crosses groups
+	selected, missing := g.Select(names, ClosureAncestors)
+	assert.Empty(t, missing)
+	assert.Contains(t, selected.Nodes, "postgres")
+	assert.Equal(t, []string{"downloaders", "torrents"}, selected.Nodes["qbittorrent"].Groups)
+}
+
+func TestGraph_GroupMembers_ConcurrencyGroup(t *testing.T) {
+	log, _ := logger.New(true)
+	builder := NewBuilder(&mockDockerClient{}, log)
+
+	// A concurrency group selects its containers like a selection group
+	emby := createTestContainer("emby", true, nil, 0, false)
+	emby.Labels["com.github.saltbox.group"] = "media"
+	plex := createTestContainer("plex", true, nil, 0, false)
+	plex.Labels["com.github.saltbox.groups"] = "media"
+	plex.Labels["com.github.saltbox.group"] = "media"
+
+	g, err := builder.Build(context.Background(), []container.Summary{emby, plex, createTestContainer("sonarr", true, nil, 0, false)})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"emby", "plex"}, g.GroupMembers("media"))
+}
+
+func TestParseGroupSelector(t *testing.T) {
+	group, ok := ParseGroupSelector("group=media")
+	assert.True(t, ok)
+	assert.Equal(t, "media", group)
+
+	group, ok = ParseGroupSelector("group:media")
+	assert.True(t, ok)
+	assert.Equal(t, "media", group)
+
+	_, ok = ParseGroupSelector("plex")
+	assert.False(t, ok)
+}
@@ -1,11 +1,15 @@
 package graph
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 // TopologicalSort performs a topological sort on the dependency graph
-// Returns containers in startup order (dependencies first)
+// Returns containers in startup order (dependencies first). The order is
+// deterministic: containers and their dependencies are visited in SortNodes order.
 func (g *Graph) TopologicalSort() (*SortedContainers, error) {
 	// Validate graph first
 	if err := g.Validate(); err != nil {
@@ -31,7 +35,7 @@ func (g *Graph) TopologicalSort() (*SortedContainers, error) {
 		node.visited = true
 
 		// Visit all parents first (dependencies)
-		for _, parent := range node.Parents {
+		for _, parent := range sortedNodes(node.Parents) {
 			if err := visit(parent); err != nil {
 				return err
 			}
@@ -48,7 +52,7 @@ func (g *Graph) TopologicalSort() (*SortedContainers, error) {
 	}
 
 	// Visit all nodes
-	for _, node := range g.Nodes {
+	for _, node := range sortedNodes(g.nodeList()) {
 		if !node.visited && !node.IsPlaceholder {
 			if err := visit(node); err != nil {
 				return nil, err
@@ -73,7 +77,8 @@ func (g *Graph) TopologicalSort() (*SortedContainers, error) {
 }
 
 // GetStartupBatches groups containers into batches that can be started in parallel
-// Containers in the same batch have no dependencies on each other
+// Containers in the same batch have no dependencies on each other. Each batch
+// is in SortNodes order.
 func (g *Graph) GetStartupBatches() ([][]*Node, error) {
 	sorted, err := g.TopologicalSort()
 	if err != nil {
@@ -125,6 +130,9 @@ func (g *Graph) GetStartupBatches() ([][]*Node, error) {
 		depth := depths[node.Name]
 		batches[depth] = append(batches[depth], node)
 	}
+	for _, batch := range batches {
+		SortNodes(batch)
+	}
 
 	return batches, nil
 }
@@ -146,6 +154,32 @@ func (g *Graph) GetShutdownBatches() ([][]*Node, error) {
 	return shutdownBatches, nil
 }
 
+// SortNodes sorts nodes into scheduling order: higher priority first, then by name
+func SortNodes(nodes []*Node) {
+	slices.SortFunc(nodes, CompareNodes)
+}
+
+// CompareNodes orders nodes by descending priority, then by name
+func CompareNodes(a, b *Node) int {
+	return cmp.Or(cmp.Compare(b.Priority, a.Priority), strings.Compare(a.Name, b.Name))
+}
+
+// sortedNodes returns a copy of nodes in SortNodes order
+func sortedNodes(nodes []*Node) []*Node {
+	sorted := slices.Clone(nodes)
+	SortNodes(sorted)
+	return sorted
+}
+
+// nodeList returns the nodes of the graph in no particular order
+func (g *Graph) nodeList() []*Node {
+	nodes := make([]*Node, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
 // FilterByState returns nodes filtered by running state
 func FilterByState(nodes []*Node, running bool) []*Node {
 	var filtered []*Node
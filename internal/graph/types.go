@@ -2,6 +2,7 @@ package graph
 
 import (
 	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/docker"
 )
 
 // Node represents a container in the dependency graph
@@ -17,16 +18,31 @@ type Node struct {
 	Parents  []*Node // Containers this one depends on (must start first)
 	Children []*Node // Containers that depend on this one (start after)
 
+	OptionalParents       map[string]bool     // Names of parents that are optional dependencies
+	MissingDependencies   []string            // Required dependencies that don't exist in Docker
+	UnmanagedDependencies []string            // Required dependencies that exist without the saltbox_managed label
+	DependencyPatterns    map[string][]string // Names each dependency pattern (glob or /regexp/) expanded to
+
 	// Startup configuration from labels
 	StartupDelay       int  // Delay in seconds after dependencies are ready
 	WaitForHealthcheck bool // Wait for health check to pass
+	Priority           int  // Higher is scheduled first among containers that are ready
+
+	// Concurrency group from labels
+	Group            string // Empty = no group
+	GroupConcurrency int    // Maximum concurrent operations in Group (0 = unlimited)
+
+	// Selection groups from labels, matched by "group=<name>" selectors
+	Groups []string
+
+	// Lifecycle hooks from labels, by phase
+	Hooks map[string]docker.Hook
 
 	// Container configuration
 	StopTimeout *int // Container's configured stop timeout in seconds (nil = Docker default of 10s)
 
 	// Internal state for topological sort
 	visited   bool
-	inStack   bool
 	sortIndex int
 }
 
@@ -59,7 +75,6 @@ func NewNode(summary container.Summary) *Node {
 		StartupDelay:       0,
 		WaitForHealthcheck: false,
 		visited:            false,
-		inStack:            false,
 		sortIndex:          -1,
 	}
 }
@@ -77,7 +92,6 @@ func NewPlaceholderNode(name string) *Node {
 		StartupDelay:       0,
 		WaitForHealthcheck: false,
 		visited:            false,
-		inStack:            false,
 		sortIndex:          -1,
 	}
 }
@@ -88,6 +102,21 @@ func (n *Node) AddParent(parent *Node) {
 	parent.Children = append(parent.Children, n)
 }
 
+// AddOptionalParent adds an optional dependency: it orders this node after
+// parent, but a missing or failed parent doesn't affect this node
+func (n *Node) AddOptionalParent(parent *Node) {
+	n.AddParent(parent)
+	if n.OptionalParents == nil {
+		n.OptionalParents = make(map[string]bool)
+	}
+	n.OptionalParents[parent.Name] = true
+}
+
+// IsOptionalParent reports whether parent is an optional dependency of this node
+func (n *Node) IsOptionalParent(parent *Node) bool {
+	return n.OptionalParents[parent.Name]
+}
+
 // HasParents returns true if the node has any parent dependencies
 func (n *Node) HasParents() bool {
 	return len(n.Parents) > 0
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EventType identifies a job lifecycle event
+type EventType string
+
+const (
+	EventSubmitted        EventType = "job.submitted"
+	EventStarted          EventType = "job.started"
+	EventCompleted        EventType = "job.completed"
+	EventFailed           EventType = "job.failed"
+	EventContainersFailed EventType = "job.containers_failed" // A completed job in which some containers failed
+)
+
+// EventTypes lists every event type in lifecycle order
+var EventTypes = []EventType{EventSubmitted, EventStarted, EventCompleted, EventFailed, EventContainersFailed}
+
+// ParseEventType parses an event type name
+func ParseEventType(s string) (EventType, error) {
+	for _, eventType := range EventTypes {
+		if string(eventType) == s {
+			return eventType, nil
+		}
+	}
+	return "", fmt.Errorf("invalid event type %q", s)
+}
+
+// Event is a change in a job's lifecycle
+type Event struct {
+	Type EventType
+	Time time.Time
+	Job  *Job // Snapshot of the job when the event fired
+}
+
+// Subscribe registers fn to receive every job event. fn is called from the
+// goroutine that changed the job. Submit and Shutdown emit their events with
+// the queue lock held, so fn must return quickly and must not call back into
+// the manager.
+func (m *Manager) Subscribe(fn func(Event)) {
+	m.subsMu.Lock()
+	defer m.subsMu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// emit sends an event about job to every subscriber. Subscribers are called
+// after releasing subsMu, so a slow one never holds up Subscribe.
+func (m *Manager) emit(eventType EventType, job *Job) {
+	m.subsMu.RLock()
+	subscribers := slices.Clone(m.subscribers)
+	m.subsMu.RUnlock()
+	if len(subscribers) == 0 {
+		return
+	}
+
+	event := Event{Type: eventType, Time: time.Now(), Job: job.Clone()}
+	for _, fn := range subscribers {
+		fn(event)
+	}
+}
+
+// emitFinished sends the events for a job a worker has finished
+func (m *Manager) emitFinished(job *Job) {
+	switch job.GetStatus() {
+	case JobStatusCompleted:
+		m.emit(EventCompleted, job)
+		if len(job.Clone().Failed) > 0 {
+			m.emit(EventContainersFailed, job)
+		}
+	case JobStatusFailed:
+		m.emit(EventFailed, job)
+	}
+}
@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder collects the events a manager emits
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// types returns the recorded event types of a job
+func (r *eventRecorder) types(jobID string) []EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var types []EventType
+	for _, event := range r.events {
+		if event.Job.ID == jobID {
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+func newEventManager(t *testing.T, execute func(*Job)) (*Manager, *eventRecorder) {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	mgr := NewManager(nil, log, 1)
+	mgr.execute = execute
+	t.Cleanup(func() { mgr.Shutdown(5 * time.Second) })
+
+	recorder := &eventRecorder{}
+	mgr.Subscribe(recorder.record)
+	return mgr, recorder
+}
+
+func TestEvents_Completed(t *testing.T) {
+	mgr, recorder := newEventManager(t, func(job *Job) {
+		job.SetResults([]string{"app"}, nil, nil, nil)
+		job.SetStatus(JobStatusCompleted)
+	})
+
+	id, err := mgr.Submit(NewJob(JobTypeStart, 60, nil))
+	require.NoError(t, err)
+	waitFinished(t, mgr, id)
+
+	require.Eventually(t, func() bool { return len(recorder.types(id)) == 3 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []EventType{EventSubmitted, EventStarted, EventCompleted}, recorder.types(id))
+
+	// Each event carries a snapshot of the job at that point
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.Equal(t, JobStatusPending, recorder.events[0].Job.Status)
+	assert.Equal(t, JobStatusRunning, recorder.events[1].Job.Status)
+	assert.Equal(t, []string{"app"}, recorder.events[2].Job.Started)
+	assert.False(t, recorder.events[2].Time.IsZero())
+}
+
+func TestEvents_ContainersFailed(t *testing.T) {
+	mgr, recorder := newEventManager(t, func(job *Job) {
+		job.SetResults([]string{"db"}, nil, nil, []string{"app"})
+		job.SetStatus(JobStatusCompleted)
+	})
+
+	id, err := mgr.Submit(NewJob(JobTypeStart, 60, nil))
+	require.NoError(t, err)
+	waitFinished(t, mgr, id)
+
+	require.Eventually(t, func() bool { return len(recorder.types(id)) == 4 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []EventType{EventSubmitted, EventStarted, EventCompleted, EventContainersFailed}, recorder.types(id))
+}
+
+func TestEvents_Failed(t *testing.T) {
+	mgr, recorder := newEventManager(t, func(job *Job) {
+		job.SetError(errors.New("docker unavailable"))
+	})
+
+	id, err := mgr.Submit(NewJob(JobTypeStop, 60, nil))
+	require.NoError(t, err)
+	waitFinished(t, mgr, id)
+
+	require.Eventually(t, func() bool { return len(recorder.types(id)) == 3 }, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []EventType{EventSubmitted, EventStarted, EventFailed}, recorder.types(id))
+}
+
+func TestParseEventType(t *testing.T) {
+	eventType, err := ParseEventType("job.containers_failed")
+	require.NoError(t, err)
+	assert.Equal(t, EventContainersFailed, eventType)
+
+	_, err = ParseEventType("job.exploded")
+	assert.Error(t, err)
+}
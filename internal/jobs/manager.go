@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/saltyorg/sdc/internal/graph"
 	"github.com/saltyorg/sdc/internal/orchestrator"
 	"github.com/saltyorg/sdc/pkg/logger"
 )
@@ -22,6 +24,9 @@ const (
 
 	// CleanupInterval is how often to run job cleanup
 	CleanupInterval = 5 * time.Minute
+
+	// QueueCapacity is the number of jobs that can wait to start
+	QueueCapacity = 100
 )
 
 // Manager manages job lifecycle and execution
@@ -31,12 +36,27 @@ type Manager struct {
 
 	jobs      map[string]*Job
 	jobsMu    sync.RWMutex
-	jobQueue  chan *Job
+	jobQueue  chan *Job // Jobs cleared to run, consumed by workers
 	workers   int
+	alive     atomic.Int32 // Number of worker goroutines currently running
 	ctx       context.Context
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
 	cleanupWg sync.WaitGroup
+
+	// Scheduling (see queue.go)
+	queue   []*Job // Unfinished jobs in submission order
+	closed  bool   // No more dispatching after Shutdown
+	schedMu sync.Mutex
+
+	execute func(*Job) // Runs a claimed job; replaced in tests
+
+	// Resolves job targets to the containers they may touch; replaced in tests
+	affected func(ctx context.Context, targets []string, closure graph.Closure) ([]string, error)
+
+	// Event subscribers (see events.go)
+	subscribers []func(Event)
+	subsMu      sync.RWMutex
 }
 
 // NewManager creates a new job manager
@@ -51,15 +71,20 @@ func NewManager(orch *orchestrator.Orchestrator, logger *logger.Logger, workers
 		orchestrator: orch,
 		logger:       logger,
 		jobs:         make(map[string]*Job),
-		jobQueue:     make(chan *Job, 100), // Buffered channel
+		jobQueue:     make(chan *Job, QueueCapacity), // Buffered channel
 		workers:      workers,
 		ctx:          ctx,
 		cancel:       cancel,
 	}
+	m.execute = m.processJob
+	if orch != nil {
+		m.affected = orch.AffectedContainers
+	}
 
 	// Start worker pool
 	for i := 0; i < workers; i++ {
 		m.wg.Add(1)
+		m.alive.Add(1)
 		go m.worker(i)
 	}
 
@@ -78,8 +103,26 @@ func NewManager(orch *orchestrator.Orchestrator, logger *logger.Logger, workers
 func (m *Manager) Shutdown(timeout time.Duration) error {
 	m.logger.Info("Shutting down job manager")
 
-	// Stop accepting new jobs
+	// Stop accepting new jobs. Jobs already handed to workers still run;
+	// jobs waiting on a conflicting job never will.
+	m.schedMu.Lock()
+	if m.closed {
+		m.schedMu.Unlock()
+		return nil
+	}
+	m.closed = true
+	remaining := m.queue[:0]
+	for _, job := range m.queue {
+		if job.dispatched {
+			remaining = append(remaining, job)
+			continue
+		}
+		job.SetError(fmt.Errorf("job manager shut down before the job could start"))
+		m.emit(EventFailed, job)
+	}
+	m.queue = remaining
 	close(m.jobQueue)
+	m.schedMu.Unlock()
 
 	// Cancel context to stop cleanup loop
 	m.cancel()
@@ -104,29 +147,59 @@ func (m *Manager) Shutdown(timeout time.Duration) error {
 	return nil
 }
 
-// Submit submits a new job for execution
-func (m *Manager) Submit(job *Job) error {
+// Submit queues a job and returns the ID of the job that will carry it out.
+// That is the ID of an existing job when an identical one is still pending
+// (the new job is discarded). Jobs touching overlapping containers run one
+// at a time in submission order; with job.Supersede set, pending jobs of the
+// opposite type on overlapping containers are cancelled first.
+func (m *Manager) Submit(job *Job) (string, error) {
 	// Check if shutting down first
 	select {
 	case <-m.ctx.Done():
-		return fmt.Errorf("job manager is shutting down")
+		return "", ErrShuttingDown
 	default:
 	}
 
+	// Resolved outside the lock as it may query Docker
+	containers := m.resolveContainers(job)
+
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+
+	if m.closed {
+		return "", ErrShuttingDown
+	}
+
+	job.containers = containers
+
+	if job.Supersede {
+		m.supersedeLocked(job)
+	}
+
+	if existing, ok := m.coalesceLocked(job); ok {
+		m.logger.Info("Job coalesced into pending job",
+			"job_id", existing.ID,
+			"type", string(job.Type))
+		return existing.ID, nil
+	}
+
+	if m.queuedLocked() >= QueueCapacity {
+		return "", ErrQueueFull
+	}
+
 	m.jobsMu.Lock()
 	m.jobs[job.ID] = job
 	m.jobsMu.Unlock()
 
+	m.queue = append(m.queue, job)
+
 	m.logger.Info("Job submitted",
 		"job_id", job.ID,
 		"type", string(job.Type))
+	m.emit(EventSubmitted, job)
 
-	select {
-	case m.jobQueue <- job:
-		return nil
-	case <-m.ctx.Done():
-		return fmt.Errorf("job manager is shutting down")
-	}
+	m.dispatchLocked()
+	return job.ID, nil
 }
 
 // Get retrieves a job by ID
@@ -155,6 +228,24 @@ func (m *Manager) List() []*Job {
 	return result
 }
 
+// GetLogs returns the log lines captured for a job and how many were dropped
+func (m *Manager) GetLogs(id string) ([]logger.Entry, int, error) {
+	m.jobsMu.RLock()
+	job, exists := m.jobs[id]
+	m.jobsMu.RUnlock()
+
+	if !exists {
+		return nil, 0, fmt.Errorf("job not found: %s", id)
+	}
+
+	logs := job.Logs()
+	if logs == nil {
+		return []logger.Entry{}, 0, nil
+	}
+
+	return logs.Entries(), logs.Dropped(), nil
+}
+
 // Delete removes a job by ID
 func (m *Manager) Delete(id string) error {
 	m.jobsMu.Lock()
@@ -169,99 +260,150 @@ func (m *Manager) Delete(id string) error {
 	return nil
 }
 
+// Stats describes the current load of the job manager
+type Stats struct {
+	Queued       int `json:"queued"`        // Jobs waiting to start
+	Capacity     int `json:"capacity"`      // Maximum number of queued jobs
+	Workers      int `json:"workers"`       // Configured worker count
+	WorkersAlive int `json:"workers_alive"` // Worker goroutines currently running
+}
+
+// Stats returns queue and worker statistics
+func (m *Manager) Stats() Stats {
+	m.schedMu.Lock()
+	queued := m.queuedLocked()
+	m.schedMu.Unlock()
+
+	return Stats{
+		Queued:       queued,
+		Capacity:     cap(m.jobQueue),
+		Workers:      m.workers,
+		WorkersAlive: int(m.alive.Load()),
+	}
+}
+
 // worker processes jobs from the queue
 func (m *Manager) worker(id int) {
 	defer m.wg.Done()
 
+	defer m.alive.Add(-1)
+
 	m.logger.Debug("Worker started", "worker_id", id)
 
 	for job := range m.jobQueue {
-		m.processJob(job)
+		if m.claim(job) {
+			m.emit(EventStarted, job)
+			m.runJob(job)
+			m.emitFinished(job)
+		}
+		m.finish(job)
 	}
 
 	m.logger.Debug("Worker stopped", "worker_id", id)
 }
 
-// processJob executes a single job
+// runJob processes a job, failing it instead of losing the worker if it panics
+func (m *Manager) runJob(job *Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.logger.Error("Job panicked",
+				"job_id", job.ID,
+				"panic", r)
+			job.SetError(fmt.Errorf("internal error: %v", r))
+		}
+	}()
+
+	m.execute(job)
+}
+
+// processJob executes a single claimed job
 func (m *Manager) processJob(job *Job) {
-	job.SetStatus(JobStatusRunning)
+	// Every line logged while processing the job carries its ID and is captured
+	log := m.logger.With("job_id", job.ID)
+	if logs := job.Logs(); logs != nil {
+		log = log.WithCapture(logs)
+	}
 
-	m.logger.Info("Processing job",
-		"job_id", job.ID,
+	log.Info("Processing job",
 		"type", string(job.Type))
 
-	ctx := context.Background()
+	ctx := logger.NewContext(context.Background(), log)
 
 	switch job.Type {
 	case JobTypeStart:
-		m.processStartJob(ctx, job)
+		m.processStartJob(ctx, log, job)
 	case JobTypeStop:
-		m.processStopJob(ctx, job)
+		m.processStopJob(ctx, log, job)
 	default:
 		job.SetError(fmt.Errorf("unknown job type: %s", job.Type))
 	}
 
-	m.logger.Info("Job completed",
-		"job_id", job.ID,
+	log.Info("Job completed",
 		"status", string(job.GetStatus()),
 		"duration", job.Duration())
 }
 
 // processStartJob handles container start operations
-func (m *Manager) processStartJob(ctx context.Context, job *Job) {
-	m.logger.Info("Processing start job",
-		"job_id", job.ID,
+func (m *Manager) processStartJob(ctx context.Context, log *logger.Logger, job *Job) {
+	log.Info("Processing start job",
 		"timeout", job.Timeout)
 
 	opts := orchestrator.StartContainersOptions{
-		Timeout: job.Timeout,
-		Ignore:  job.Ignore,
+		Timeout:             job.Timeout,
+		Ignore:              job.Ignore,
+		Targets:             job.Targets,
+		IncludeDependencies: job.IncludeDependencies,
 	}
 
 	result, err := m.orchestrator.StartContainers(ctx, opts)
 	if err != nil {
 		job.SetError(err)
-		m.logger.Error("Start job failed",
-			"job_id", job.ID,
+		log.Error("Start job failed",
 			"error", err)
 		return
 	}
 
 	job.SetResults(result.Started, nil, result.Skipped, result.Failed)
+	job.SetSkipReasons(result.SkipReasons)
+	job.SetFailReasons(result.FailReasons)
+	job.SetCriticalPath(result.CriticalPath)
+	job.SetHooks(result.Hooks)
 	job.SetStatus(JobStatusCompleted)
 
-	m.logger.Info("Start job completed",
-		"job_id", job.ID,
+	log.Info("Start job completed",
 		"started", len(result.Started),
 		"skipped", len(result.Skipped),
 		"failed", len(result.Failed))
 }
 
 // processStopJob handles container stop operations
-func (m *Manager) processStopJob(ctx context.Context, job *Job) {
-	m.logger.Info("Processing stop job",
-		"job_id", job.ID,
+func (m *Manager) processStopJob(ctx context.Context, log *logger.Logger, job *Job) {
+	log.Info("Processing stop job",
 		"timeout", job.Timeout)
 
 	opts := orchestrator.StopContainersOptions{
-		Timeout: job.Timeout,
-		Ignore:  job.Ignore,
+		Timeout:             job.Timeout,
+		Ignore:              job.Ignore,
+		Targets:             job.Targets,
+		IncludeDependencies: job.IncludeDependencies,
 	}
 
 	result, err := m.orchestrator.StopContainers(ctx, opts)
 	if err != nil {
 		job.SetError(err)
-		m.logger.Error("Stop job failed",
-			"job_id", job.ID,
+		log.Error("Stop job failed",
 			"error", err)
 		return
 	}
 
 	job.SetResults(nil, result.Stopped, result.Skipped, result.Failed)
+	job.SetSkipReasons(result.SkipReasons)
+	job.SetFailReasons(result.FailReasons)
+	job.SetCriticalPath(result.CriticalPath)
+	job.SetHooks(result.Hooks)
 	job.SetStatus(JobStatusCompleted)
 
-	m.logger.Info("Stop job completed",
-		"job_id", job.ID,
+	log.Info("Stop job completed",
 		"stopped", len(result.Stopped),
 		"skipped", len(result.Skipped),
 		"failed", len(result.Failed))
@@ -297,7 +439,7 @@ func (m *Manager) cleanup() {
 		return
 	}
 
-	// Collect jobs eligible for cleanup (completed/failed and older than MinJobRetention)
+	// Collect jobs eligible for cleanup (finished and older than MinJobRetention)
 	type jobAge struct {
 		id  string
 		age time.Duration
@@ -305,8 +447,7 @@ func (m *Manager) cleanup() {
 
 	var eligible []jobAge
 	for id, job := range m.jobs {
-		status := job.GetStatus()
-		if status == JobStatusCompleted || status == JobStatusFailed {
+		if job.IsFinished() {
 			age := now.Sub(job.CreatedAt)
 			if age > MinJobRetention {
 				eligible = append(eligible, jobAge{id: id, age: age})
@@ -137,8 +137,8 @@ func TestManager_Shutdown(t *testing.T) {
 
 	// Should not accept new jobs after shutdown
 	job := NewJob(JobTypeStart, 600, nil)
-	err = mgr.Submit(job)
-	assert.Error(t, err)
+	_, err = mgr.Submit(job)
+	assert.ErrorIs(t, err, ErrShuttingDown)
 }
 
 // Note: Integration tests for processStartJob and processStopJob require:
@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+// resolveTimeout bounds the Docker lookup used to find the containers a job may touch
+const resolveTimeout = 10 * time.Second
+
+var (
+	// ErrQueueFull is returned by Submit when QueueCapacity jobs are already waiting
+	ErrQueueFull = errors.New("job queue is full")
+
+	// ErrShuttingDown is returned by Submit once Shutdown has been called
+	ErrShuttingDown = errors.New("job manager is shutting down")
+)
+
+// The manager keeps every unfinished job in m.queue, in submission order.
+// A job is handed to the workers only once no earlier unfinished job touches
+// any of the same containers, so conflicting jobs run one after another in
+// the order they were submitted while unrelated jobs run in parallel.
+
+// resolveContainers returns the set of containers a job may touch, or nil
+// if it may touch any managed container. Targets are always resolved, since
+// group selectors and patterns never equal a container name.
+func (m *Manager) resolveContainers(job *Job) map[string]bool {
+	if len(job.Targets) == 0 || m.affected == nil {
+		return nil
+	}
+
+	closure := graph.ClosureNone
+	if job.IncludeDependencies {
+		closure = graph.ClosureAncestors
+		if job.Type == JobTypeStop {
+			closure = graph.ClosureDescendants
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, resolveTimeout)
+	defer cancel()
+
+	names, err := m.affected(ctx, job.Targets, closure)
+	if err != nil {
+		// Treat the job as touching everything rather than risk a conflict
+		m.logger.Warn("Failed to resolve job containers, serializing with all jobs",
+			"job_id", job.ID,
+			"error", err)
+		return nil
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// overlaps reports whether two container sets share a container (nil = all)
+func overlaps(a, b map[string]bool) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	for name := range a {
+		if b[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRequest reports whether two jobs describe the same operation
+func sameRequest(a, b *Job) bool {
+	return a.Type == b.Type &&
+		a.Timeout == b.Timeout &&
+		a.IncludeDependencies == b.IncludeDependencies &&
+		sameSet(a.Targets, b.Targets) &&
+		sameSet(a.Ignore, b.Ignore)
+}
+
+func sameSet(a, b []string) bool {
+	a = slices.Compact(slices.Sorted(slices.Values(a)))
+	b = slices.Compact(slices.Sorted(slices.Values(b)))
+	return slices.Equal(a, b)
+}
+
+// supersedeLocked cancels queued jobs of the other type that overlap job and
+// have not started yet. Caller must hold m.schedMu.
+func (m *Manager) supersedeLocked(job *Job) {
+	remaining := m.queue[:0]
+	for _, queued := range m.queue {
+		if queued.Type != job.Type &&
+			queued.GetStatus() == JobStatusPending &&
+			overlaps(queued.containers, job.containers) {
+			queued.Cancel(job.ID)
+			m.logger.Info("Job superseded",
+				"job_id", queued.ID,
+				"type", string(queued.Type),
+				"superseded_by", job.ID)
+
+			// Dispatched jobs stay queued until a worker discards them
+			if !queued.dispatched {
+				continue
+			}
+		}
+		remaining = append(remaining, queued)
+	}
+	clear(m.queue[len(remaining):])
+	m.queue = remaining
+}
+
+// coalesceLocked returns the queued job an identical new job can be merged
+// into. Only the most recent overlapping job qualifies, and only while it
+// hasn't started, so merging never reorders conflicting operations.
+// Caller must hold m.schedMu.
+func (m *Manager) coalesceLocked(job *Job) (*Job, bool) {
+	for i := len(m.queue) - 1; i >= 0; i-- {
+		queued := m.queue[i]
+		if queued.GetStatus() == JobStatusCancelled || !overlaps(queued.containers, job.containers) {
+			continue
+		}
+		if queued.GetStatus() == JobStatusPending && sameRequest(queued, job) {
+			return queued, true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// dispatchLocked hands every queued job that no longer conflicts with an
+// earlier job to the workers. Caller must hold m.schedMu.
+func (m *Manager) dispatchLocked() {
+	if m.closed {
+		return
+	}
+
+	for i, job := range m.queue {
+		if job.dispatched {
+			continue
+		}
+
+		blocked := false
+		for _, earlier := range m.queue[:i] {
+			if overlaps(earlier.containers, job.containers) {
+				blocked = true
+				break
+			}
+		}
+		if blocked {
+			continue
+		}
+
+		job.dispatched = true
+		m.jobQueue <- job // Never blocks: at most QueueCapacity jobs are unstarted
+	}
+}
+
+// claim marks a dispatched job as running unless it was cancelled meanwhile
+func (m *Manager) claim(job *Job) bool {
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+
+	if job.GetStatus() != JobStatusPending {
+		return false
+	}
+	job.SetStatus(JobStatusRunning)
+	return true
+}
+
+// finish removes a job from the queue and dispatches jobs waiting on it
+func (m *Manager) finish(job *Job) {
+	m.schedMu.Lock()
+	defer m.schedMu.Unlock()
+
+	if i := slices.Index(m.queue, job); i >= 0 {
+		m.queue = slices.Delete(m.queue, i, i+1)
+	}
+	m.dispatchLocked()
+}
+
+// queuedLocked counts jobs that have not started yet, including cancelled
+// jobs still occupying a worker queue slot. Caller must hold m.schedMu.
+func (m *Manager) queuedLocked() int {
+	queued := 0
+	for _, job := range m.queue {
+		status := job.GetStatus()
+		if status == JobStatusPending || (status == JobStatusCancelled && job.dispatched) {
+			queued++
+		}
+	}
+	return queued
+}
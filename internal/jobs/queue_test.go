@@ -0,0 +1,385 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedExecutor reports the jobs a manager starts and holds each one until released
+type gatedExecutor struct {
+	mu      sync.Mutex
+	gates   map[string]chan struct{}
+	open    bool // Set once the test ends; later jobs are not held
+	startCh chan string
+}
+
+func newGatedManager(t *testing.T, workers int) (*Manager, *gatedExecutor) {
+	t.Helper()
+
+	log, _ := logger.New(true)
+	mgr := NewManager(nil, log, workers)
+
+	exec := &gatedExecutor{
+		gates:   make(map[string]chan struct{}),
+		startCh: make(chan string, QueueCapacity),
+	}
+	mgr.affected = func(ctx context.Context, targets []string, closure graph.Closure) ([]string, error) {
+		return targets, nil // Targets are container names
+	}
+	mgr.execute = func(job *Job) {
+		gate := exec.gate(job.ID)
+		exec.startCh <- job.ID
+		<-gate
+		job.SetStatus(JobStatusCompleted)
+	}
+
+	t.Cleanup(func() {
+		exec.releaseAll()
+		mgr.Shutdown(5 * time.Second)
+	})
+	return mgr, exec
+}
+
+func (e *gatedExecutor) gate(id string) chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.gates[id]; !ok {
+		e.gates[id] = make(chan struct{})
+		if e.open {
+			close(e.gates[id])
+		}
+	}
+	return e.gates[id]
+}
+
+func (e *gatedExecutor) release(id string) {
+	close(e.gate(id))
+}
+
+func (e *gatedExecutor) releaseAll() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.open = true
+	for _, gate := range e.gates {
+		select {
+		case <-gate:
+		default:
+			close(gate)
+		}
+	}
+}
+
+// waitStarted waits for the next job to start and returns its ID
+func (e *gatedExecutor) waitStarted(t *testing.T) string {
+	t.Helper()
+	select {
+	case id := <-e.startCh:
+		return id
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a job to start")
+		return ""
+	}
+}
+
+// assertNoStart checks that no further job starts for a short while
+func (e *gatedExecutor) assertNoStart(t *testing.T) {
+	t.Helper()
+	select {
+	case id := <-e.startCh:
+		t.Fatalf("job %s started unexpectedly", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func targetedJob(jobType JobType, targets ...string) *Job {
+	job := NewJob(jobType, 60, nil)
+	job.Targets = targets
+	return job
+}
+
+func waitFinished(t *testing.T, mgr *Manager, id string) *Job {
+	t.Helper()
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = mgr.Get(id)
+		return err == nil && job.IsFinished()
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestQueue_OverlappingJobsRunInOrder(t *testing.T) {
+	mgr, exec := newGatedManager(t, 3)
+
+	first := targetedJob(JobTypeStop, "app")
+	second := targetedJob(JobTypeStart, "app")
+	third := targetedJob(JobTypeStop, "app", "db")
+
+	for _, job := range []*Job{first, second, third} {
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, first.ID, exec.waitStarted(t))
+	exec.assertNoStart(t)
+	assert.Equal(t, 2, mgr.Stats().Queued)
+
+	exec.release(first.ID)
+	assert.Equal(t, second.ID, exec.waitStarted(t))
+	exec.assertNoStart(t)
+
+	exec.release(second.ID)
+	assert.Equal(t, third.ID, exec.waitStarted(t))
+	exec.release(third.ID)
+
+	waitFinished(t, mgr, third.ID)
+}
+
+func TestQueue_DisjointJobsRunInParallel(t *testing.T) {
+	mgr, exec := newGatedManager(t, 2)
+
+	app := targetedJob(JobTypeStart, "app")
+	db := targetedJob(JobTypeStop, "db")
+
+	for _, job := range []*Job{app, db} {
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+
+	started := []string{exec.waitStarted(t), exec.waitStarted(t)}
+	assert.ElementsMatch(t, []string{app.ID, db.ID}, started)
+}
+
+func TestQueue_ResolvesSelectorsWithoutDependencies(t *testing.T) {
+	mgr, exec := newGatedManager(t, 3)
+	mgr.affected = func(ctx context.Context, targets []string, closure graph.Closure) ([]string, error) {
+		assert.Equal(t, graph.ClosureNone, closure)
+		members := map[string][]string{"group=media": {"plex", "sonarr"}, "rad*": {"radarr"}}
+		var names []string
+		for _, target := range targets {
+			if found, ok := members[target]; ok {
+				names = append(names, found...)
+			} else {
+				names = append(names, target)
+			}
+		}
+		return names, nil
+	}
+
+	group := targetedJob(JobTypeStop, "group=media")
+	pattern := targetedJob(JobTypeStop, "rad*")
+	sonarr := targetedJob(JobTypeStart, "sonarr")
+	radarr := targetedJob(JobTypeStart, "radarr")
+	for _, job := range []*Job{group, pattern, sonarr, radarr} {
+		job.IncludeDependencies = false
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+
+	// The start jobs wait for the stop jobs whose selectors cover them
+	started := []string{exec.waitStarted(t), exec.waitStarted(t)}
+	assert.ElementsMatch(t, []string{group.ID, pattern.ID}, started)
+	exec.assertNoStart(t)
+
+	exec.release(group.ID)
+	assert.Equal(t, sonarr.ID, exec.waitStarted(t))
+	exec.release(pattern.ID)
+	assert.Equal(t, radarr.ID, exec.waitStarted(t))
+}
+
+func TestQueue_UnresolvedJobConflictsWithAll(t *testing.T) {
+	mgr, exec := newGatedManager(t, 2)
+	mgr.affected = func(ctx context.Context, targets []string, closure graph.Closure) ([]string, error) {
+		if targets[0] == "group=media" {
+			return nil, errors.New("docker unavailable")
+		}
+		return targets, nil
+	}
+
+	media := targetedJob(JobTypeStop, "group=media")
+	db := targetedJob(JobTypeStart, "db")
+	for _, job := range []*Job{media, db} {
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, media.ID, exec.waitStarted(t))
+	exec.assertNoStart(t)
+	exec.release(media.ID)
+	assert.Equal(t, db.ID, exec.waitStarted(t))
+}
+
+func TestQueue_UntargetedJobConflictsWithAll(t *testing.T) {
+	mgr, exec := newGatedManager(t, 2)
+
+	app := targetedJob(JobTypeStart, "app")
+	all := NewJob(JobTypeStop, 60, nil)
+	db := targetedJob(JobTypeStart, "db")
+
+	for _, job := range []*Job{app, all, db} {
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, app.ID, exec.waitStarted(t))
+	exec.assertNoStart(t)
+
+	exec.release(app.ID)
+	assert.Equal(t, all.ID, exec.waitStarted(t))
+	exec.assertNoStart(t)
+
+	exec.release(all.ID)
+	assert.Equal(t, db.ID, exec.waitStarted(t))
+}
+
+func TestQueue_CoalescesIdenticalPendingJob(t *testing.T) {
+	mgr, exec := newGatedManager(t, 1)
+
+	running := targetedJob(JobTypeStart, "app")
+	_, err := mgr.Submit(running)
+	require.NoError(t, err)
+	exec.waitStarted(t)
+
+	pending := targetedJob(JobTypeStop, "app", "db")
+	id, err := mgr.Submit(pending)
+	require.NoError(t, err)
+	assert.Equal(t, pending.ID, id)
+
+	duplicate := targetedJob(JobTypeStop, "db", "app")
+	id, err = mgr.Submit(duplicate)
+	require.NoError(t, err)
+	assert.Equal(t, pending.ID, id, "identical pending job should be reused")
+
+	_, err = mgr.Get(duplicate.ID)
+	assert.Error(t, err, "coalesced job should not be registered")
+}
+
+func TestQueue_DoesNotCoalesceRunningJob(t *testing.T) {
+	mgr, exec := newGatedManager(t, 1)
+
+	running := targetedJob(JobTypeStart, "app")
+	_, err := mgr.Submit(running)
+	require.NoError(t, err)
+	exec.waitStarted(t)
+
+	again := targetedJob(JobTypeStart, "app")
+	id, err := mgr.Submit(again)
+	require.NoError(t, err)
+	assert.Equal(t, again.ID, id)
+}
+
+func TestQueue_DoesNotCoalesceAcrossConflictingJob(t *testing.T) {
+	mgr, exec := newGatedManager(t, 1)
+
+	running := targetedJob(JobTypeStart, "db")
+	_, err := mgr.Submit(running)
+	require.NoError(t, err)
+	exec.waitStarted(t)
+
+	start := targetedJob(JobTypeStart, "app")
+	stop := targetedJob(JobTypeStop, "app")
+	startAgain := targetedJob(JobTypeStart, "app")
+
+	for _, job := range []*Job{start, stop, startAgain} {
+		id, err := mgr.Submit(job)
+		require.NoError(t, err)
+		assert.Equal(t, job.ID, id, "a stop queued in between must not be skipped")
+	}
+}
+
+func TestQueue_SupersedeCancelsPendingOppositeJobs(t *testing.T) {
+	mgr, exec := newGatedManager(t, 2)
+
+	running := targetedJob(JobTypeStop, "app")
+	_, err := mgr.Submit(running)
+	require.NoError(t, err)
+	exec.waitStarted(t)
+
+	pendingStart := targetedJob(JobTypeStart, "app")
+	unrelated := targetedJob(JobTypeStart, "db")
+	for _, job := range []*Job{pendingStart, unrelated} {
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+	// The unrelated job is free to run
+	assert.Equal(t, unrelated.ID, waitNextAfterRelease(t, exec, unrelated.ID))
+
+	stop := targetedJob(JobTypeStop, "app")
+	stop.Supersede = true
+	_, err = mgr.Submit(stop)
+	require.NoError(t, err)
+
+	cancelled, err := mgr.Get(pendingStart.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCancelled, cancelled.Status)
+	assert.Equal(t, stop.ID, cancelled.SupersededBy)
+	assert.False(t, cancelled.EndedAt.IsZero())
+
+	running2, err := mgr.Get(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusRunning, running2.Status, "running jobs are never superseded")
+
+	exec.release(running.ID)
+	assert.Equal(t, stop.ID, exec.waitStarted(t))
+}
+
+// waitNextAfterRelease waits for the next job start, releasing id once it does
+func waitNextAfterRelease(t *testing.T, exec *gatedExecutor, id string) string {
+	t.Helper()
+	started := exec.waitStarted(t)
+	exec.release(id)
+	return started
+}
+
+func TestQueue_Full(t *testing.T) {
+	mgr, exec := newGatedManager(t, 1)
+
+	running := targetedJob(JobTypeStart, "app")
+	_, err := mgr.Submit(running)
+	require.NoError(t, err)
+	exec.waitStarted(t)
+
+	for i := 0; i < QueueCapacity; i++ {
+		job := NewJob(JobTypeStart, i, nil) // Distinct timeouts prevent coalescing
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+
+	_, err = mgr.Submit(NewJob(JobTypeStop, 0, nil))
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestQueue_ShutdownFailsWaitingJobs(t *testing.T) {
+	mgr, exec := newGatedManager(t, 1)
+
+	running := targetedJob(JobTypeStart, "app")
+	waiting := targetedJob(JobTypeStop, "app")
+	for _, job := range []*Job{running, waiting} {
+		_, err := mgr.Submit(job)
+		require.NoError(t, err)
+	}
+	exec.waitStarted(t)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		exec.release(running.ID)
+	}()
+	require.NoError(t, mgr.Shutdown(2*time.Second))
+
+	job, err := mgr.Get(waiting.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusFailed, job.Status)
+	assert.Contains(t, job.Error, "shut down")
+
+	job, err = mgr.Get(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, JobStatusCompleted, job.Status)
+}
@@ -1,12 +1,19 @@
 package jobs
 
 import (
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/saltyorg/sdc/internal/orchestrator"
+	"github.com/saltyorg/sdc/pkg/logger"
 )
 
+// MaxJobLogEntries is the number of log lines captured per job
+const MaxJobLogEntries = 500
+
 // JobType represents the type of operation being performed
 type JobType string
 
@@ -23,8 +30,72 @@ const (
 	JobStatusRunning   JobStatus = "running"
 	JobStatusCompleted JobStatus = "completed"
 	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled" // Superseded or dropped before it started
 )
 
+// CriticalPath is the dependency chain that finished last, which bounds how
+// long a job took
+type CriticalPath struct {
+	Steps      []CriticalPathStep `json:"steps"`       // In execution order
+	DurationMs int64              `json:"duration_ms"` // From the first operation until the last step finished
+}
+
+// CriticalPathStep is one container on the critical path
+type CriticalPathStep struct {
+	Container  string `json:"container"`
+	DurationMs int64  `json:"duration_ms"` // Including health waits and startup delays
+}
+
+// newCriticalPath converts the orchestrator's critical path for the job result
+func newCriticalPath(path *orchestrator.CriticalPath) *CriticalPath {
+	if path == nil {
+		return nil
+	}
+
+	result := &CriticalPath{
+		Steps:      make([]CriticalPathStep, len(path.Steps)),
+		DurationMs: path.Duration.Milliseconds(),
+	}
+	for i, step := range path.Steps {
+		result.Steps[i] = CriticalPathStep{Container: step.Container, DurationMs: step.Duration.Milliseconds()}
+	}
+	return result
+}
+
+// HookResult is the outcome of a container's lifecycle hook
+type HookResult struct {
+	Phase      string `json:"phase"`     // pre_start, post_start, pre_stop or post_stop
+	Action     string `json:"action"`    // As written in the label
+	ExitCode   int    `json:"exit_code"` // Command exit code or HTTP status; -1 if the hook did not complete
+	Output     string `json:"output,omitempty"`
+	Error      string `json:"error,omitempty"` // Empty if the hook succeeded
+	DurationMs int64  `json:"duration_ms"`
+	OnFailure  string `json:"on_failure"` // continue or fail
+}
+
+// newHookResults converts the orchestrator's hook results for the job result
+func newHookResults(hooks map[string][]orchestrator.HookResult) map[string][]HookResult {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	results := make(map[string][]HookResult, len(hooks))
+	for container, runs := range hooks {
+		for _, run := range runs {
+			results[container] = append(results[container], HookResult{
+				Phase:      run.Phase,
+				Action:     run.Action,
+				ExitCode:   run.ExitCode,
+				Output:     run.Output,
+				Error:      run.Error,
+				DurationMs: run.Duration.Milliseconds(),
+				OnFailure:  string(run.OnFailure),
+			})
+		}
+	}
+	return results
+}
+
 // Job represents a container orchestration operation
 type Job struct {
 	ID        string    `json:"id"`
@@ -35,8 +106,11 @@ type Job struct {
 	EndedAt   time.Time `json:"ended_at"`
 
 	// Operation parameters
-	Timeout int      `json:"timeout"`
-	Ignore  []string `json:"ignore"`
+	Timeout             int      `json:"timeout"`
+	Ignore              []string `json:"ignore"`
+	Targets             []string `json:"targets,omitempty"`    // Empty = all managed containers
+	IncludeDependencies bool     `json:"include_dependencies"` // Pull in dependencies (start) or dependents (stop) of targets
+	Supersede           bool     `json:"supersede,omitempty"`  // Cancel pending jobs of the opposite type on overlapping containers
 
 	// Results
 	Started []string `json:"started,omitempty"` // For start operations
@@ -44,8 +118,23 @@ type Job struct {
 	Skipped []string `json:"skipped,omitempty"`
 	Failed  []string `json:"failed,omitempty"`
 
+	SkipReasons map[string]string `json:"skip_reasons,omitempty"` // Why each skipped container was skipped
+	FailReasons map[string]string `json:"fail_reasons,omitempty"` // Why containers failed without being tried
+
+	CriticalPath *CriticalPath `json:"critical_path,omitempty"` // Dependency chain that bounded the job's duration
+
+	Hooks map[string][]HookResult `json:"hooks,omitempty"` // Lifecycle hook results by container
+
 	// Error information
-	Error string `json:"error,omitempty"`
+	Error        string `json:"error,omitempty"`
+	SupersededBy string `json:"superseded_by,omitempty"` // Job that cancelled this one
+
+	// Scheduling state, guarded by the manager
+	containers map[string]bool // Containers the job may touch; nil = all
+	dispatched bool            // Handed to the worker queue
+
+	// Log lines emitted while the job was processed
+	logs *logger.Buffer
 
 	mu sync.RWMutex
 }
@@ -63,6 +152,7 @@ func NewJob(jobType JobType, timeout int, ignore []string) *Job {
 		Stopped:   []string{},
 		Skipped:   []string{},
 		Failed:    []string{},
+		logs:      logger.NewBuffer(MaxJobLogEntries),
 	}
 }
 
@@ -105,6 +195,29 @@ func (j *Job) SetError(err error) {
 	}
 }
 
+// Cancel marks a job that has not started as cancelled by another job
+func (j *Job) Cancel(supersededBy string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Status = JobStatusCancelled
+	j.SupersededBy = supersededBy
+	j.Error = "superseded by job " + supersededBy
+	if j.EndedAt.IsZero() {
+		j.EndedAt = time.Now()
+	}
+}
+
+// IsFinished reports whether the job has reached a terminal status
+func (j *Job) IsFinished() bool {
+	switch j.GetStatus() {
+	case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
 // SetResults updates the job results (thread-safe)
 func (j *Job) SetResults(started, stopped, skipped, failed []string) {
 	j.mu.Lock()
@@ -124,28 +237,72 @@ func (j *Job) SetResults(started, stopped, skipped, failed []string) {
 	}
 }
 
+// SetSkipReasons records why containers were skipped (thread-safe)
+func (j *Job) SetSkipReasons(reasons map[string]string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.SkipReasons = maps.Clone(reasons)
+}
+
+// SetFailReasons records why containers failed without being tried (thread-safe)
+func (j *Job) SetFailReasons(reasons map[string]string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.FailReasons = maps.Clone(reasons)
+}
+
+// SetCriticalPath records the dependency chain that bounded the job (thread-safe)
+func (j *Job) SetCriticalPath(path *orchestrator.CriticalPath) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.CriticalPath = newCriticalPath(path)
+}
+
+// SetHooks records the lifecycle hooks that ran (thread-safe)
+func (j *Job) SetHooks(hooks map[string][]orchestrator.HookResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Hooks = newHookResults(hooks)
+}
+
 // Clone creates a deep copy of the job (thread-safe)
 func (j *Job) Clone() *Job {
 	j.mu.RLock()
 	defer j.mu.RUnlock()
 
 	return &Job{
-		ID:        j.ID,
-		Type:      j.Type,
-		Status:    j.Status,
-		CreatedAt: j.CreatedAt,
-		StartedAt: j.StartedAt,
-		EndedAt:   j.EndedAt,
-		Timeout:   j.Timeout,
-		Ignore:    append([]string{}, j.Ignore...),
-		Started:   append([]string{}, j.Started...),
-		Stopped:   append([]string{}, j.Stopped...),
-		Skipped:   append([]string{}, j.Skipped...),
-		Failed:    append([]string{}, j.Failed...),
-		Error:     j.Error,
+		ID:                  j.ID,
+		Type:                j.Type,
+		Status:              j.Status,
+		CreatedAt:           j.CreatedAt,
+		StartedAt:           j.StartedAt,
+		EndedAt:             j.EndedAt,
+		Timeout:             j.Timeout,
+		Ignore:              append([]string{}, j.Ignore...),
+		Targets:             append([]string{}, j.Targets...),
+		IncludeDependencies: j.IncludeDependencies,
+		Supersede:           j.Supersede,
+		Started:             append([]string{}, j.Started...),
+		Stopped:             append([]string{}, j.Stopped...),
+		Skipped:             append([]string{}, j.Skipped...),
+		Failed:              append([]string{}, j.Failed...),
+		SkipReasons:         maps.Clone(j.SkipReasons),
+		FailReasons:         maps.Clone(j.FailReasons),
+		CriticalPath:        j.CriticalPath, // Replaced, never modified
+		Hooks:               j.Hooks,        // Replaced, never modified
+		Error:               j.Error,
+		SupersededBy:        j.SupersededBy,
+		logs:                j.logs,
 	}
 }
 
+// Logs returns the buffer holding log lines captured for this job
+func (j *Job) Logs() *logger.Buffer {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.logs
+}
+
 // Duration returns how long the job took to complete
 func (j *Job) Duration() time.Duration {
 	j.mu.RLock()
@@ -166,3 +323,13 @@ func (j *Job) Age() time.Duration {
 	defer j.mu.RUnlock()
 	return time.Since(j.CreatedAt)
 }
+
+// RestartTargets returns the targets of the start job of a restart: the
+// targets of the stop job plus the dependents stopped along with them. No
+// targets (all containers) stays no targets.
+func RestartTargets(targets, stopped []string) []string {
+	if len(targets) == 0 {
+		return targets
+	}
+	return slices.Compact(slices.Sorted(slices.Values(append(slices.Clone(targets), stopped...))))
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/orchestrator"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -76,6 +78,35 @@ func TestJob_SetResults(t *testing.T) {
 	assert.Equal(t, failed, job.Failed)
 }
 
+func TestJob_SetHooks(t *testing.T) {
+	job := NewJob(JobTypeStop, 300, nil)
+
+	job.SetHooks(map[string][]orchestrator.HookResult{})
+	assert.Nil(t, job.Hooks)
+
+	job.SetHooks(map[string][]orchestrator.HookResult{
+		"postgres": {{
+			Phase:     docker.HookPreStop,
+			Action:    "exec:/app/flush.sh",
+			ExitCode:  0,
+			Output:    "flushed",
+			Duration:  1500 * time.Millisecond,
+			OnFailure: docker.HookPolicyFail,
+		}},
+	})
+
+	assert.Equal(t, map[string][]HookResult{
+		"postgres": {{
+			Phase:      "pre_stop",
+			Action:     "exec:/app/flush.sh",
+			ExitCode:   0,
+			Output:     "flushed",
+			DurationMs: 1500,
+			OnFailure:  "fail",
+		}},
+	}, job.Clone().Hooks)
+}
+
 func TestJob_Clone(t *testing.T) {
 	original := NewJob(JobTypeStart, 600, []string{"traefik"})
 	original.SetStatus(JobStatusRunning)
@@ -168,3 +199,8 @@ func TestJob_SetStatus_ThreadSafe(t *testing.T) {
 
 	assert.Equal(t, JobStatusRunning, job.Status)
 }
+
+func TestRestartTargets(t *testing.T) {
+	assert.Equal(t, []string{"plex", "tautulli"}, RestartTargets([]string{"plex"}, []string{"tautulli", "plex"}))
+	assert.Empty(t, RestartTargets(nil, []string{"plex"}))
+}
@@ -0,0 +1,434 @@
+package lint
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+// Severity ranks how much a finding affects orchestration
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // SDC will not do what the labels intend
+	SeverityWarning Severity = "warning" // Probably a mistake; SDC works around it
+	SeverityInfo    Severity = "info"    // Has no effect, but is harmless
+)
+
+// Rules reported by Check
+const (
+	RuleUnknownLabel        = "unknown-label"
+	RuleInvalidValue        = "invalid-value"
+	RuleSelfDependency      = "self-dependency"
+	RuleUnmanagedDependency = "unmanaged-dependency"
+	RuleMissingDependency   = "missing-dependency"
+	RuleMissingHealthcheck  = "missing-healthcheck"
+	RuleUnusedLabel         = "unused-label"
+	RuleCycle               = "cycle"
+	RuleGroupConflict       = "group-conflict"
+	RulePattern             = "pattern"
+	RuleUnmatchedPattern    = "unmatched-pattern"
+)
+
+// knownLabels are the Saltbox label keys SDC reads
+var knownLabels = append([]string{
+	docker.LabelManaged,
+	docker.LabelController,
+	docker.LabelDependsOn,
+	docker.LabelDependsOnDelay,
+	docker.LabelDependsOnHealthchecks,
+	docker.LabelDependsOnOptional,
+	docker.LabelPriority,
+	docker.LabelGroup,
+	docker.LabelGroupConcurrency,
+	docker.LabelGroups,
+}, hookLabels()...)
+
+// hookLabels returns the hook label keys of every phase
+func hookLabels() []string {
+	var keys []string
+	for _, phase := range docker.HookPhases {
+		key := docker.HookLabel(phase)
+		keys = append(keys, key, key+docker.HookTimeoutSuffix, key+docker.HookOnFailureSuffix)
+	}
+	return keys
+}
+
+// Finding is a single problem found in the container labels
+type Finding struct {
+	Severity  Severity `json:"severity"`
+	Rule      string   `json:"rule"`
+	Container string   `json:"container,omitempty"` // Empty for findings about several containers
+	Label     string   `json:"label,omitempty"`
+	Message   string   `json:"message"`
+}
+
+// Report is the result of a lint run
+type Report struct {
+	Findings []Finding `json:"findings"` // Errors first, then by container
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+	Infos    int       `json:"infos"`
+}
+
+// HealthChecker reports whether a container has a healthcheck configured
+type HealthChecker interface {
+	HasHealthCheck(ctx context.Context, containerNameOrID string) (bool, error)
+}
+
+// Check lints the labels of containers and the dependency graph built from them.
+// Parents whose healthcheck can't be inspected are not reported.
+func Check(ctx context.Context, containers []container.Summary, g *graph.Graph, health HealthChecker) *Report {
+	report := &Report{Findings: []Finding{}}
+
+	labelled := make(map[string]*docker.ContainerLabels)
+	for _, c := range containers {
+		name := containerName(c)
+		labelled[name] = docker.ParseLabels(c.Labels)
+		report.checkLabels(name, c.Labels)
+	}
+	names := slices.Sorted(maps.Keys(labelled))
+
+	for _, c := range containers {
+		name := containerName(c)
+		labels := labelled[name]
+		if !labels.IsManaged() {
+			continue
+		}
+
+		if labels.GroupConcurrency > 0 && labels.Group == "" {
+			report.add(SeverityInfo, RuleUnusedLabel, name, docker.LabelGroupConcurrency,
+				"group concurrency has no effect without a group")
+		}
+
+		if labels.ShouldWaitForHealthcheck() && !labels.HasDependencies() {
+			report.add(SeverityInfo, RuleUnusedLabel, name, docker.LabelDependsOnHealthchecks,
+				"healthcheck waits have no effect without dependencies")
+		}
+
+		for _, dep := range report.expandPatterns(name, labels.GetDependencies(), names) {
+			parentLabels, exists := labelled[dep]
+			switch {
+			case dep == name:
+				report.add(SeverityError, RuleSelfDependency, name, docker.LabelDependsOn,
+					"container depends on itself")
+			case !exists:
+				report.checkUnlisted(g.Nodes[name], dep)
+			case !parentLabels.IsManaged():
+				report.add(SeverityWarning, RuleUnmanagedDependency, name, docker.LabelDependsOn,
+					fmt.Sprintf("dependency %q is not managed (%s); it is never started or awaited", dep, parentLabels.UnmanagedReason()))
+			case labels.ShouldWaitForHealthcheck():
+				if hasHealthCheck, err := health.HasHealthCheck(ctx, dep); err == nil && !hasHealthCheck {
+					report.add(SeverityWarning, RuleMissingHealthcheck, name, docker.LabelDependsOnHealthchecks,
+						fmt.Sprintf("dependency %q has no healthcheck, so there is nothing to wait for", dep))
+				}
+			}
+		}
+
+		for _, dep := range report.expandPatterns(name, labels.GetOptionalDependencies(), names) {
+			parentLabels, exists := labelled[dep]
+			switch {
+			case dep == name:
+				report.add(SeverityError, RuleSelfDependency, name, docker.LabelDependsOn,
+					"container depends on itself")
+			case !exists:
+				report.add(SeverityInfo, RuleMissingDependency, name, docker.LabelDependsOn,
+					fmt.Sprintf("optional dependency %q is not a container with the saltbox_managed label; it is ignored", dep))
+			case !parentLabels.IsManaged():
+				report.add(SeverityInfo, RuleUnmanagedDependency, name, docker.LabelDependsOn,
+					fmt.Sprintf("optional dependency %q is not managed (%s); it is ignored", dep, parentLabels.UnmanagedReason()))
+			}
+		}
+	}
+
+	report.checkGroupLimits(containers, labelled)
+
+	for _, cycle := range g.Cycles() {
+		// Self-dependencies are already reported
+		if len(cycle.Members) > 1 {
+			report.add(SeverityError, RuleCycle, "", docker.LabelDependsOn, "dependency cycle: "+cycle.String())
+		}
+	}
+
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(severityRank(a.Severity), severityRank(b.Severity)),
+			strings.Compare(a.Container, b.Container),
+			strings.Compare(a.Rule, b.Rule),
+		)
+	})
+	return report
+}
+
+// checkLabels reports unknown Saltbox label keys and values ParseLabels ignores
+func (r *Report) checkLabels(name string, labels map[string]string) {
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		value := labels[key]
+		if !strings.HasPrefix(key, docker.LabelPrefix) {
+			continue
+		}
+		if phase, setting, ok := parseHookLabel(key); ok {
+			r.checkHookLabel(name, key, phase, setting, value, labels)
+			continue
+		}
+
+		switch key {
+		case docker.LabelManaged, docker.LabelDependsOnHealthchecks:
+			if !isBool(value) {
+				r.add(SeverityError, RuleInvalidValue, name, key,
+					fmt.Sprintf("value %q is not true or false; treated as false", value))
+			}
+		case docker.LabelController:
+			if !isBool(value) {
+				r.add(SeverityError, RuleInvalidValue, name, key,
+					fmt.Sprintf("value %q is not true or false; treated as true", value))
+			}
+		case docker.LabelDependsOnDelay:
+			if delay, err := strconv.Atoi(value); err != nil || delay < 0 {
+				r.add(SeverityError, RuleInvalidValue, name, key,
+					fmt.Sprintf("value %q is not a non-negative number of seconds; no delay is applied", value))
+			}
+		case docker.LabelPriority:
+			if _, err := strconv.Atoi(value); err != nil {
+				r.add(SeverityError, RuleInvalidValue, name, key,
+					fmt.Sprintf("value %q is not an integer; priority 0 is used", value))
+			}
+		case docker.LabelGroupConcurrency:
+			if limit, err := strconv.Atoi(value); err != nil || limit < 1 {
+				r.add(SeverityError, RuleInvalidValue, name, key,
+					fmt.Sprintf("value %q is not a positive integer; the group is not limited by this container", value))
+			}
+		case docker.LabelGroup:
+			if strings.TrimSpace(value) == "" {
+				r.add(SeverityInfo, RuleInvalidValue, name, key, "empty group is ignored")
+			}
+		case docker.LabelDependsOn, docker.LabelDependsOnOptional:
+			for dep := range strings.SplitSeq(value, ",") {
+				if strings.TrimSpace(dep) == "" {
+					r.add(SeverityInfo, RuleInvalidValue, name, key, "empty entries in the dependency list are ignored")
+					break
+				}
+			}
+		case docker.LabelGroups:
+			for group := range strings.SplitSeq(value, ",") {
+				if strings.TrimSpace(group) == "" {
+					r.add(SeverityInfo, RuleInvalidValue, name, key, "empty entries in the group list are ignored")
+					break
+				}
+			}
+		default:
+			message := "unknown Saltbox label; it is ignored"
+			if suggestion := closestLabel(key); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			r.add(SeverityWarning, RuleUnknownLabel, name, key, message)
+		}
+	}
+}
+
+// parseHookLabel splits a hook label key into its phase and setting (empty
+// for the action, or timeout or on_failure)
+func parseHookLabel(key string) (phase, setting string, ok bool) {
+	rest, ok := strings.CutPrefix(key, docker.LabelHooks)
+	if !ok {
+		return "", "", false
+	}
+	phase, setting, _ = strings.Cut(rest, ".")
+	if !docker.IsHookPhase(phase) {
+		return "", "", false
+	}
+	switch "." + setting {
+	case ".", docker.HookTimeoutSuffix, docker.HookOnFailureSuffix:
+		return phase, setting, true
+	default:
+		return "", "", false
+	}
+}
+
+// checkHookLabel reports hook actions that cannot run in their phase and
+// settings ParseLabels ignores
+func (r *Report) checkHookLabel(name, key, phase, setting, value string, labels map[string]string) {
+	action := docker.HookLabel(phase)
+	if setting == "" {
+		if strings.TrimSpace(value) == "" {
+			r.add(SeverityInfo, RuleInvalidValue, name, key, "empty hook is ignored")
+			return
+		}
+		if _, err := docker.ValidateHook(phase, docker.Hook{Action: value}); err != nil {
+			r.add(SeverityError, RuleInvalidValue, name, key, err.Error()+"; the hook fails every time it runs")
+		}
+		return
+	}
+
+	if strings.TrimSpace(labels[action]) == "" {
+		r.add(SeverityInfo, RuleUnusedLabel, name, key,
+			fmt.Sprintf("hook %s has no effect without %s", setting, action))
+		return
+	}
+
+	if "."+setting == docker.HookTimeoutSuffix {
+		if _, err := docker.ParseHookTimeout(value); err != nil {
+			r.add(SeverityError, RuleInvalidValue, name, key,
+				fmt.Sprintf("%v; the default of %ds is used", err, docker.DefaultHookTimeout))
+		}
+		return
+	}
+	if _, err := docker.ParseHookPolicy(value); err != nil {
+		r.add(SeverityError, RuleInvalidValue, name, key,
+			fmt.Sprintf("%v; %s is used", err, docker.HookPolicyContinue))
+	}
+}
+
+// expandPatterns replaces the dependency patterns of a container with the
+// containers they match, reporting what each pattern expanded to
+func (r *Report) expandPatterns(name string, dependencies []string, names []string) []string {
+	var expanded []string
+	for _, dep := range dependencies {
+		if !graph.IsPattern(dep) {
+			expanded = append(expanded, dep)
+			continue
+		}
+
+		matches, err := graph.ExpandPattern(dep, names)
+		if err != nil {
+			r.add(SeverityError, RuleInvalidValue, name, docker.LabelDependsOn, err.Error()+"; it is ignored")
+			continue
+		}
+		matches = slices.DeleteFunc(matches, func(match string) bool { return match == name })
+
+		if len(matches) == 0 {
+			r.add(SeverityWarning, RuleUnmatchedPattern, name, docker.LabelDependsOn,
+				fmt.Sprintf("pattern %q matches no containers", dep))
+			continue
+		}
+		r.add(SeverityInfo, RulePattern, name, docker.LabelDependsOn,
+			fmt.Sprintf("pattern %q matches %s", dep, strings.Join(matches, ", ")))
+		for _, match := range matches {
+			if !slices.Contains(expanded, match) {
+				expanded = append(expanded, match)
+			}
+		}
+	}
+	return expanded
+}
+
+// checkGroupLimits reports concurrency groups whose members declare different limits
+func (r *Report) checkGroupLimits(containers []container.Summary, labelled map[string]*docker.ContainerLabels) {
+	limits := make(map[string]map[int]bool)
+	for _, c := range containers {
+		labels := labelled[containerName(c)]
+		if !labels.IsManaged() || labels.Group == "" || labels.GroupConcurrency == 0 {
+			continue
+		}
+		if limits[labels.Group] == nil {
+			limits[labels.Group] = make(map[int]bool)
+		}
+		limits[labels.Group][labels.GroupConcurrency] = true
+	}
+
+	for _, group := range slices.Sorted(maps.Keys(limits)) {
+		if len(limits[group]) > 1 {
+			values := slices.Sorted(maps.Keys(limits[group]))
+			r.add(SeverityWarning, RuleGroupConflict, "", docker.LabelGroupConcurrency,
+				fmt.Sprintf("group %q declares different limits %v; each container is held to its own", group, values))
+		}
+	}
+}
+
+// add records a finding and counts it by severity
+func (r *Report) add(severity Severity, rule, container, label, message string) {
+	r.Findings = append(r.Findings, Finding{
+		Severity:  severity,
+		Rule:      rule,
+		Container: container,
+		Label:     label,
+		Message:   message,
+	})
+
+	switch severity {
+	case SeverityError:
+		r.Errors++
+	case SeverityWarning:
+		r.Warnings++
+	case SeverityInfo:
+		r.Infos++
+	}
+}
+
+// severityRank orders findings with errors first
+func severityRank(severity Severity) int {
+	switch severity {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// isBool reports whether ParseLabels reads value as an explicit true or false
+func isBool(value string) bool {
+	lower := strings.ToLower(value)
+	return lower == "true" || lower == "false"
+}
+
+// closestLabel returns the known label key a misspelled key was probably meant to be
+func closestLabel(key string) string {
+	best, bestDistance := "", 3 // Suggest only keys within two edits
+	for _, known := range knownLabels {
+		if distance := editDistance(key, known); distance < bestDistance {
+			best, bestDistance = known, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// containerName returns the name of a container without the leading slash
+func containerName(c container.Summary) string {
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// checkUnlisted reports a required dependency that isn't a labelled container,
+// telling a missing container apart from one without the saltbox_managed
+// label, as the graph builder found them
+func (r *Report) checkUnlisted(node *graph.Node, dep string) {
+	switch {
+	case node != nil && slices.Contains(node.MissingDependencies, dep):
+		r.add(SeverityError, RuleMissingDependency, node.Name, docker.LabelDependsOn,
+			fmt.Sprintf("required dependency %q does not exist, so this container fails to start (use %q if it is optional)", dep, docker.OptionalMarker+dep))
+	case node != nil && slices.Contains(node.UnmanagedDependencies, dep):
+		r.add(SeverityWarning, RuleUnmanagedDependency, node.Name, docker.LabelDependsOn,
+			fmt.Sprintf("dependency %q exists but has no saltbox_managed label; it is never started or awaited", dep))
+	}
+}
@@ -0,0 +1,266 @@
+package lint
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDocker reports healthchecks for the named containers and fails inspects.
+// Containers in unlisted exist without being passed to Check.
+type fakeDocker struct {
+	healthchecks map[string]bool
+	unlisted     []string
+}
+
+func (f *fakeDocker) HasHealthCheck(ctx context.Context, name string) (bool, error) {
+	hasHealthCheck, ok := f.healthchecks[name]
+	if !ok {
+		return false, errors.New("no such container")
+	}
+	return hasHealthCheck, nil
+}
+
+func (f *fakeDocker) GetContainer(ctx context.Context, containerID string) (*client.ContainerInspectResult, error) {
+	return nil, errors.New("not available")
+}
+
+func (f *fakeDocker) ContainerExists(ctx context.Context, name string) (bool, error) {
+	return slices.Contains(f.unlisted, name), nil
+}
+
+func summary(name string, labels map[string]string) container.Summary {
+	return container.Summary{ID: name + "-id", Names: []string{"/" + name}, State: container.StateRunning, Labels: labels}
+}
+
+func check(t *testing.T, docker *fakeDocker, containers ...container.Summary) *Report {
+	t.Helper()
+
+	log, _ := logger.New(false)
+	g, err := graph.NewBuilder(docker, log).Build(context.Background(), containers)
+	require.NoError(t, err)
+	return Check(context.Background(), containers, g, docker)
+}
+
+// rules returns "container/rule" for each finding
+func rules(report *Report) []string {
+	var result []string
+	for _, finding := range report.Findings {
+		result = append(result, finding.Container+"/"+finding.Rule)
+	}
+	return result
+}
+
+func TestCheck_Clean(t *testing.T) {
+	report := check(t, &fakeDocker{healthchecks: map[string]bool{"postgres": true}},
+		summary("postgres", map[string]string{"com.github.saltbox.saltbox_managed": "true"}),
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed":         "true",
+			"com.github.saltbox.depends_on":              "postgres",
+			"com.github.saltbox.depends_on.delay":        "5",
+			"com.github.saltbox.depends_on.healthchecks": "true",
+		}),
+	)
+
+	assert.Empty(t, report.Findings)
+	assert.Zero(t, report.Errors+report.Warnings+report.Infos)
+}
+
+func TestCheck_Labels(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed":         "true",
+			"com.github.saltbox.saltbox_mangaed":         "true",
+			"com.github.saltbox.depends_on.delay":        "5s",
+			"com.github.saltbox.depends_on.healthchecks": "yes",
+			"com.github.saltbox.saltbox_controller":      "off",
+			"com.github.saltbox.whatever":                "1",
+			"com.github.saltbox.priority":                "high",
+			"org.opencontainers.image.title":             "app",
+		}),
+	)
+
+	assert.Equal(t, []string{
+		"app/invalid-value", "app/invalid-value", "app/invalid-value", "app/invalid-value",
+		"app/unknown-label", "app/unknown-label",
+	}, rules(report))
+	assert.Equal(t, 4, report.Errors)
+	assert.Equal(t, 2, report.Warnings)
+
+	var messages []string
+	for _, finding := range report.Findings {
+		if finding.Label == "com.github.saltbox.saltbox_mangaed" {
+			messages = append(messages, finding.Message)
+		}
+	}
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], `did you mean "com.github.saltbox.saltbox_managed"?`)
+}
+
+func TestCheck_Dependencies(t *testing.T) {
+	report := check(t, &fakeDocker{healthchecks: map[string]bool{"redis": false}},
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed":         "true",
+			"com.github.saltbox.depends_on":              "app,db,,vpn,redis",
+			"com.github.saltbox.depends_on.healthchecks": "true",
+		}),
+		summary("vpn", map[string]string{
+			"com.github.saltbox.saltbox_managed":    "true",
+			"com.github.saltbox.saltbox_controller": "false",
+		}),
+		summary("redis", map[string]string{"com.github.saltbox.saltbox_managed": "true"}),
+	)
+
+	assert.Equal(t, []string{
+		"app/missing-dependency",
+		"app/self-dependency",
+		"app/missing-healthcheck",
+		"app/unmanaged-dependency",
+		"app/invalid-value",
+	}, rules(report))
+}
+
+func TestCheck_UnmanagedDependency(t *testing.T) {
+	report := check(t, &fakeDocker{unlisted: []string{"postgres"}},
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed": "true",
+			"com.github.saltbox.depends_on":      "postgres,db",
+		}),
+	)
+
+	require.Len(t, report.Findings, 2)
+	assert.Equal(t, RuleMissingDependency, report.Findings[0].Rule)
+	assert.Contains(t, report.Findings[0].Message, `"db" does not exist`)
+	assert.Equal(t, RuleUnmanagedDependency, report.Findings[1].Rule)
+	assert.Equal(t, SeverityWarning, report.Findings[1].Severity)
+	assert.Contains(t, report.Findings[1].Message, `"postgres" exists but has no saltbox_managed label`)
+}
+
+func TestCheck_OptionalDependencies(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed":     "true",
+			"com.github.saltbox.depends_on":          "?authelia",
+			"com.github.saltbox.depends_on.optional": "crowdsec,",
+		}),
+		summary("crowdsec", map[string]string{
+			"com.github.saltbox.saltbox_managed":    "true",
+			"com.github.saltbox.saltbox_controller": "false",
+		}),
+	)
+
+	assert.Equal(t, []string{"app/invalid-value", "app/missing-dependency", "app/unmanaged-dependency"}, rules(report))
+	assert.Equal(t, 0, report.Errors+report.Warnings, "optional dependencies never fail a job")
+}
+
+func TestCheck_Patterns(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("postgres-main", map[string]string{"com.github.saltbox.saltbox_managed": "true"}),
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed": "true",
+			"com.github.saltbox.depends_on":      "postgres-*,mongo*,redis[",
+		}),
+	)
+
+	assert.Equal(t, []string{"app/invalid-value", "app/unmatched-pattern", "app/pattern"}, rules(report))
+	assert.Equal(t, `pattern "postgres-*" matches postgres-main`, report.Findings[2].Message)
+}
+
+func TestCheck_Cycle(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("a", map[string]string{"com.github.saltbox.saltbox_managed": "true", "com.github.saltbox.depends_on": "b"}),
+		summary("b", map[string]string{"com.github.saltbox.saltbox_managed": "true", "com.github.saltbox.depends_on": "a"}),
+	)
+
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, RuleCycle, report.Findings[0].Rule)
+	assert.Equal(t, SeverityError, report.Findings[0].Severity)
+	assert.Contains(t, report.Findings[0].Message, "a")
+}
+
+func TestCheck_UnusedHealthcheckLabel(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed":         "true",
+			"com.github.saltbox.depends_on.healthchecks": "true",
+		}),
+	)
+
+	assert.Equal(t, []string{"app/unused-label"}, rules(report))
+	assert.Equal(t, 1, report.Infos)
+}
+
+func TestCheck_Groups(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("plex", map[string]string{
+			"com.github.saltbox.saltbox_managed":   "true",
+			"com.github.saltbox.group":             "media",
+			"com.github.saltbox.group_concurrency": "2",
+		}),
+		summary("emby", map[string]string{
+			"com.github.saltbox.saltbox_managed":   "true",
+			"com.github.saltbox.group":             "media",
+			"com.github.saltbox.group_concurrency": "1",
+		}),
+		summary("sonarr", map[string]string{
+			"com.github.saltbox.saltbox_managed":   "true",
+			"com.github.saltbox.group_concurrency": "1",
+		}),
+		summary("radarr", map[string]string{
+			"com.github.saltbox.saltbox_managed":   "true",
+			"com.github.saltbox.group":             "arr",
+			"com.github.saltbox.group_concurrency": "none",
+		}),
+	)
+
+	assert.Equal(t, []string{"radarr/invalid-value", "/group-conflict", "sonarr/unused-label"}, rules(report))
+}
+
+func TestCheck_Hooks(t *testing.T) {
+	report := check(t, &fakeDocker{},
+		summary("postgres", map[string]string{
+			"com.github.saltbox.saltbox_managed":            "true",
+			"com.github.saltbox.hooks.pre_stop":             "exec:/app/flush.sh",
+			"com.github.saltbox.hooks.pre_stop.timeout":     "120",
+			"com.github.saltbox.hooks.pre_stop.on_failure":  "fail",
+			"com.github.saltbox.hooks.post_start":           "http://:8080/warmup",
+			"com.github.saltbox.hooks.pre_start":            "POST http://vault:8200/unseal",
+			"com.github.saltbox.hooks.post_stop.on_failure": "fail",
+		}),
+		summary("app", map[string]string{
+			"com.github.saltbox.saltbox_managed":            "true",
+			"com.github.saltbox.hooks.pre_start":            "exec:/app/prepare.sh",
+			"com.github.saltbox.hooks.post_start":           "ftp://files/warm",
+			"com.github.saltbox.hooks.post_start.timeout":   "soon",
+			"com.github.saltbox.hooks.post_start.on_failur": "fail",
+		}),
+	)
+
+	assert.Equal(t, []string{
+		"app/invalid-value", "app/invalid-value", "app/invalid-value",
+		"app/unknown-label", "postgres/unused-label",
+	}, rules(report))
+
+	for _, finding := range report.Findings {
+		switch finding.Label {
+		case "com.github.saltbox.hooks.pre_start":
+			assert.Contains(t, finding.Message, "explicit host")
+		case "com.github.saltbox.hooks.post_start.on_failur":
+			assert.Contains(t, finding.Message, `did you mean "com.github.saltbox.hooks.post_start.on_failure"?`)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 2, editDistance("managed", "mangaed"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+}
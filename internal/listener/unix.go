@@ -0,0 +1,111 @@
+package listener
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// DefaultSocketMode is the permission applied to sockets when none is given
+const DefaultSocketMode fs.FileMode = 0660
+
+// UnixOptions configures ownership and permissions of a Unix domain socket
+type UnixOptions struct {
+	Owner string      // User name or numeric UID (empty = leave unchanged)
+	Group string      // Group name or numeric GID (empty = leave unchanged)
+	Mode  fs.FileMode // Socket permissions (0 = DefaultSocketMode)
+}
+
+// ListenUnix creates a Unix domain socket at path with the given ownership and mode.
+// A stale socket left behind by a previous run is removed first; any other
+// kind of file at path is an error.
+func ListenUnix(path string, opts UnixOptions) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("refusing to replace non-socket file %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	mode := opts.Mode
+	if mode == 0 {
+		mode = DefaultSocketMode
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to set socket mode: %w", err)
+	}
+
+	uid, gid, err := lookupOwnership(opts.Owner, opts.Group)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to set socket ownership: %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// ParseMode parses an octal permission string such as "0660"
+func ParseMode(value string) (fs.FileMode, error) {
+	if value == "" {
+		return DefaultSocketMode, nil
+	}
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socket mode: %s", value)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// lookupOwnership resolves user and group names (or numeric IDs) to IDs.
+// Empty values resolve to -1, which os.Chown leaves unchanged.
+func lookupOwnership(owner, group string) (int, int, error) {
+	uid, gid := -1, -1
+
+	if owner != "" {
+		id, err := strconv.Atoi(owner)
+		if err != nil {
+			u, lookupErr := user.Lookup(owner)
+			if lookupErr != nil {
+				return 0, 0, fmt.Errorf("unknown socket owner %s: %w", owner, lookupErr)
+			}
+			id, _ = strconv.Atoi(u.Uid)
+		}
+		uid = id
+	}
+
+	if group != "" {
+		id, err := strconv.Atoi(group)
+		if err != nil {
+			g, lookupErr := user.LookupGroup(group)
+			if lookupErr != nil {
+				return 0, 0, fmt.Errorf("unknown socket group %s: %w", group, lookupErr)
+			}
+			id, _ = strconv.Atoi(g.Gid)
+		}
+		gid = id
+	}
+
+	return uid, gid, nil
+}
@@ -0,0 +1,79 @@
+package listener
+
+import (
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "sdc.sock")
+
+	l, err := ListenUnix(path, UnixOptions{Mode: 0600})
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&fs.ModeSocket)
+	assert.Equal(t, fs.FileMode(0600), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestListenUnix_ReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdc.sock")
+
+	// Leave a socket file behind without unlinking it
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := ListenUnix(path, UnixOptions{})
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSocketMode, info.Mode().Perm())
+}
+
+func TestListenUnix_RefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdc.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0644))
+
+	_, err := ListenUnix(path, UnixOptions{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "non-socket")
+}
+
+func TestListenUnix_UnknownOwner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdc.sock")
+
+	_, err := ListenUnix(path, UnixOptions{Owner: "no-such-user-sdc"})
+	assert.Error(t, err)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("0640")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0640), mode)
+
+	mode, err = ParseMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSocketMode, mode)
+
+	_, err = ParseMode("rw-rw----")
+	assert.Error(t, err)
+
+	_, err = ParseMode("1777")
+	assert.Error(t, err)
+}
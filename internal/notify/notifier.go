@@ -0,0 +1,345 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+)
+
+const (
+	// DefaultMaxAttempts is how often a delivery is tried when a webhook sets no limit
+	DefaultMaxAttempts = 3
+
+	// DefaultRetryDelay is the wait before the first retry, doubled for each later one
+	DefaultRetryDelay = 2 * time.Second
+
+	// DeliveryTimeout bounds a single delivery attempt
+	DeliveryTimeout = 10 * time.Second
+
+	// QueueCapacity is the number of events each webhook can hold while
+	// earlier deliveries are in flight; further events are dropped
+	QueueCapacity = 100
+)
+
+// Headers sent with every delivery
+const (
+	EventHeader     = "X-SDC-Event"
+	DeliveryHeader  = "X-SDC-Delivery"  // Unique per event, the same across retries
+	SignatureHeader = "X-SDC-Signature" // "sha256=<hex HMAC of the body>", when a secret is set
+)
+
+// Webhook configures a receiver of job events
+type Webhook struct {
+	Name        string            `json:"name,omitempty"` // Used in logs; defaults to the URL's host
+	URL         string            `json:"url"`
+	Events      []jobs.EventType  `json:"events,omitempty"`       // Empty = all events
+	Secret      string            `json:"secret,omitempty"`       // Signs each body with HMAC-SHA256
+	Template    string            `json:"template,omitempty"`     // Go template rendering the JSON body; empty = Payload
+	Headers     map[string]string `json:"headers,omitempty"`      // Extra request headers
+	MaxAttempts int               `json:"max_attempts,omitempty"` // Default DefaultMaxAttempts
+}
+
+// Payload is the default request body, and the data templates are executed with
+type Payload struct {
+	Event     jobs.EventType `json:"event"`
+	Timestamp time.Time      `json:"timestamp"`
+	Job       *jobs.Job      `json:"job"`
+}
+
+// Notifier delivers job events to webhooks without blocking the job manager.
+// Each webhook has its own queue, so a slow receiver only delays itself.
+type Notifier struct {
+	endpoints  []*endpoint
+	logger     *logger.Logger
+	client     *http.Client
+	userAgent  string
+	retryDelay time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.RWMutex
+	started bool
+	closed  bool
+}
+
+// endpoint is a validated webhook with its delivery queue
+type endpoint struct {
+	Webhook
+	events   map[jobs.EventType]bool // nil = all
+	template *template.Template      // nil = Payload
+	queue    chan jobs.Event
+}
+
+// NewNotifier validates the webhooks and returns a notifier for them. Call
+// Start to begin delivering.
+func NewNotifier(webhooks []Webhook, logger *logger.Logger) (*Notifier, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Notifier{
+		logger:     logger,
+		client:     &http.Client{},
+		userAgent:  "sdc-webhook/1.0",
+		retryDelay: DefaultRetryDelay,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+
+	for i, webhook := range webhooks {
+		ep, err := newEndpoint(webhook)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("invalid webhook %d: %w", i+1, err)
+		}
+		n.endpoints = append(n.endpoints, ep)
+	}
+	return n, nil
+}
+
+// newEndpoint validates a webhook and fills in its defaults
+func newEndpoint(webhook Webhook) (*endpoint, error) {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: must be an http(s) URL", webhook.URL)
+	}
+	if webhook.Name == "" {
+		webhook.Name = u.Host
+	}
+	if webhook.MaxAttempts < 0 {
+		return nil, fmt.Errorf("invalid max_attempts %d: must not be negative", webhook.MaxAttempts)
+	}
+	if webhook.MaxAttempts == 0 {
+		webhook.MaxAttempts = DefaultMaxAttempts
+	}
+
+	ep := &endpoint{Webhook: webhook, queue: make(chan jobs.Event, QueueCapacity)}
+
+	if len(webhook.Events) > 0 {
+		ep.events = make(map[jobs.EventType]bool)
+		for _, eventType := range webhook.Events {
+			if _, err := jobs.ParseEventType(string(eventType)); err != nil {
+				return nil, err
+			}
+			ep.events[eventType] = true
+		}
+	}
+
+	if webhook.Template != "" {
+		tmpl, err := template.New(webhook.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(webhook.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		ep.template = tmpl
+	}
+	return ep, nil
+}
+
+// templateFuncs are available in webhook templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. a string with quotes escaped
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+}
+
+// SetRetryDelay sets the wait before the first retry (default DefaultRetryDelay)
+func (n *Notifier) SetRetryDelay(delay time.Duration) {
+	n.retryDelay = delay
+}
+
+// SetUserAgent sets the User-Agent header of deliveries
+func (n *Notifier) SetUserAgent(userAgent string) {
+	n.userAgent = userAgent
+}
+
+// Start begins delivering queued events
+func (n *Notifier) Start() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.started || n.closed {
+		return
+	}
+	n.started = true
+
+	for _, ep := range n.endpoints {
+		n.wg.Add(1)
+		go n.deliverLoop(ep)
+	}
+}
+
+// Notify queues an event for every webhook subscribed to it. It never
+// blocks: events for a webhook whose queue is full are dropped.
+func (n *Notifier) Notify(event jobs.Event) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return
+	}
+
+	for _, ep := range n.endpoints {
+		if ep.events != nil && !ep.events[event.Type] {
+			continue
+		}
+		select {
+		case ep.queue <- event:
+		default:
+			n.logger.Warn("Webhook queue full, dropping event",
+				"webhook", ep.Name,
+				"event", string(event.Type),
+				"job_id", event.Job.ID)
+		}
+	}
+}
+
+// Stop stops accepting events and waits up to timeout for queued deliveries,
+// then abandons the rest
+func (n *Notifier) Stop(timeout time.Duration) {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return
+	}
+	n.closed = true
+	for _, ep := range n.endpoints {
+		close(ep.queue)
+	}
+	n.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		n.logger.Warn("Webhook deliveries still pending at shutdown, abandoning them")
+		n.cancel()
+		<-done
+	}
+	n.cancel()
+}
+
+// deliverLoop delivers the events of one webhook in order
+func (n *Notifier) deliverLoop(ep *endpoint) {
+	defer n.wg.Done()
+	for event := range ep.queue {
+		if n.ctx.Err() != nil {
+			continue // Abandoned; drain the queue
+		}
+		n.deliver(ep, event)
+	}
+}
+
+// deliver sends an event to a webhook, retrying transient failures with
+// exponential backoff
+func (n *Notifier) deliver(ep *endpoint, event jobs.Event) {
+	log := n.logger.With("webhook", ep.Name, "event", string(event.Type), "job_id", event.Job.ID)
+
+	body, err := ep.render(event)
+	if err != nil {
+		log.Error("Failed to render webhook payload", "error", err)
+		return
+	}
+
+	deliveryID := uuid.New().String()
+	delay := n.retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := n.send(ep, event.Type, deliveryID, body)
+		if err == nil {
+			log.Debug("Webhook delivered", "attempt", attempt)
+			return
+		}
+		if !retry || attempt >= ep.MaxAttempts {
+			log.Warn("Webhook delivery failed", "attempts", attempt, "error", err)
+			return
+		}
+
+		log.Debug("Webhook delivery failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-n.ctx.Done():
+			return
+		}
+		delay *= 2
+	}
+}
+
+// render builds the request body of an event
+func (ep *endpoint) render(event jobs.Event) ([]byte, error) {
+	payload := Payload{Event: event.Type, Timestamp: event.Time, Job: event.Job}
+	if ep.template == nil {
+		return json.Marshal(payload)
+	}
+
+	var buf bytes.Buffer
+	if err := ep.template.Execute(&buf, payload); err != nil {
+		return nil, err
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, errors.New("template did not produce valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// send makes one delivery attempt. It reports whether a failure is worth
+// retrying: network errors, 429 and 5xx responses are.
+func (n *Notifier) send(ep *endpoint, eventType jobs.EventType, deliveryID string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(n.ctx, DeliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range ep.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", n.userAgent)
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(DeliveryHeader, deliveryID)
+	if ep.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(ep.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("receiver returned %s", resp.Status)
+}
+
+// Sign returns the signature header value of body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// delivery is a request received by the test receiver
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver records deliveries and answers with the queued status codes, then 200
+type receiver struct {
+	mu         sync.Mutex
+	deliveries []delivery
+	statuses   []int
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.deliveries = append(r.deliveries, delivery{header: req.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) received() []delivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]delivery{}, r.deliveries...)
+}
+
+func newTestNotifier(t *testing.T, webhooks ...Webhook) *Notifier {
+	t.Helper()
+	log, _ := logger.New(false)
+	n, err := NewNotifier(webhooks, log)
+	require.NoError(t, err)
+	n.SetRetryDelay(time.Millisecond)
+	n.Start()
+	t.Cleanup(func() { n.Stop(time.Second) })
+	return n
+}
+
+func testEvent(eventType jobs.EventType) jobs.Event {
+	job := jobs.NewJob(jobs.JobTypeStart, 600, nil)
+	job.SetResults([]string{"postgres"}, nil, nil, []string{"app", "worker"})
+	job.SetStatus(jobs.JobStatusCompleted)
+	return jobs.Event{Type: eventType, Time: time.Date(2026, 10, 18, 4, 30, 0, 0, time.UTC), Job: job.Clone()}
+}
+
+func TestNotifier_DefaultPayloadAndSignature(t *testing.T) {
+	r, server := newReceiver(t)
+	n := newTestNotifier(t, Webhook{URL: server.URL, Secret: "s3cret", Headers: map[string]string{"X-Team": "ops"}})
+
+	event := testEvent(jobs.EventCompleted)
+	n.Notify(event)
+
+	require.Eventually(t, func() bool { return len(r.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	got := r.received()[0]
+
+	assert.Equal(t, "application/json", got.header.Get("Content-Type"))
+	assert.Equal(t, "job.completed", got.header.Get(EventHeader))
+	assert.NotEmpty(t, got.header.Get(DeliveryHeader))
+	assert.Equal(t, "ops", got.header.Get("X-Team"))
+	assert.Equal(t, Sign("s3cret", got.body), got.header.Get(SignatureHeader))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(got.body, &payload))
+	assert.Equal(t, jobs.EventCompleted, payload.Event)
+	assert.True(t, event.Time.Equal(payload.Timestamp))
+	assert.Equal(t, event.Job.ID, payload.Job.ID)
+	assert.Equal(t, []string{"app", "worker"}, payload.Job.Failed)
+}
+
+func TestNotifier_Template(t *testing.T) {
+	r, server := newReceiver(t)
+	n := newTestNotifier(t, Webhook{
+		URL:      server.URL,
+		Template: `{"content": {{json (printf "%s job failed for %s" .Job.Type (join .Job.Failed ", "))}}}`,
+	})
+
+	n.Notify(testEvent(jobs.EventContainersFailed))
+
+	require.Eventually(t, func() bool { return len(r.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	assert.JSONEq(t, `{"content": "start job failed for app, worker"}`, string(r.received()[0].body))
+	assert.Empty(t, r.received()[0].header.Get(SignatureHeader))
+}
+
+func TestNotifier_EventFilter(t *testing.T) {
+	r, server := newReceiver(t)
+	n := newTestNotifier(t, Webhook{URL: server.URL, Events: []jobs.EventType{jobs.EventContainersFailed, jobs.EventFailed}})
+
+	n.Notify(testEvent(jobs.EventSubmitted))
+	n.Notify(testEvent(jobs.EventCompleted))
+	n.Notify(testEvent(jobs.EventContainersFailed))
+
+	require.Eventually(t, func() bool { return len(r.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	n.Stop(time.Second)
+	require.Len(t, r.received(), 1)
+	assert.Equal(t, "job.containers_failed", r.received()[0].header.Get(EventHeader))
+}
+
+func TestNotifier_Retries(t *testing.T) {
+	// Transient failures are retried with the same delivery ID
+	r, server := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	n := newTestNotifier(t, Webhook{URL: server.URL})
+	n.Notify(testEvent(jobs.EventFailed))
+
+	require.Eventually(t, func() bool { return len(r.received()) == 3 }, 2*time.Second, 5*time.Millisecond)
+	deliveries := r.received()
+	assert.Equal(t, deliveries[0].header.Get(DeliveryHeader), deliveries[2].header.Get(DeliveryHeader))
+
+	// Attempts stop at max_attempts
+	r, server = newReceiver(t, 500, 500, 500, 500)
+	n = newTestNotifier(t, Webhook{URL: server.URL, MaxAttempts: 2})
+	n.Notify(testEvent(jobs.EventFailed))
+	n.Stop(time.Second)
+	assert.Len(t, r.received(), 2)
+
+	// Client errors are not retried
+	r, server = newReceiver(t, http.StatusBadRequest)
+	n = newTestNotifier(t, Webhook{URL: server.URL})
+	n.Notify(testEvent(jobs.EventFailed))
+	n.Stop(time.Second)
+	assert.Len(t, r.received(), 1)
+}
+
+func TestNotifier_DoesNotBlock(t *testing.T) {
+	var requests atomic.Int32
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	log, _ := logger.New(false)
+	n, err := NewNotifier([]Webhook{{URL: server.URL}}, log)
+	require.NoError(t, err)
+	n.Start()
+	defer n.Stop(10 * time.Millisecond)
+
+	// With the receiver stuck, events beyond the queue are dropped rather than waited for
+	done := make(chan struct{})
+	go func() {
+		for range QueueCapacity + 10 {
+			n.Notify(testEvent(jobs.EventSubmitted))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Notify blocked on a stuck receiver")
+	}
+	require.Eventually(t, func() bool { return requests.Load() == 1 }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestNotifier_Subscribed(t *testing.T) {
+	r, server := newReceiver(t)
+	n := newTestNotifier(t, Webhook{URL: server.URL, Events: []jobs.EventType{jobs.EventSubmitted}})
+
+	log, _ := logger.New(false)
+	mgr := jobs.NewManager(nil, log, 1)
+	mgr.Subscribe(n.Notify)
+
+	id, err := mgr.Submit(jobs.NewJob(jobs.JobTypeStart, 600, nil))
+	require.NoError(t, err)
+	mgr.Shutdown(time.Second)
+
+	require.Eventually(t, func() bool { return len(r.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	var payload Payload
+	require.NoError(t, json.Unmarshal(r.received()[0].body, &payload))
+	assert.Equal(t, id, payload.Job.ID)
+}
+
+func TestNewNotifier_Invalid(t *testing.T) {
+	log, _ := logger.New(false)
+	for _, webhook := range []Webhook{
+		{URL: "discord.com/api/webhooks/1"},
+		{URL: "ftp://example.com"},
+		{URL: "https://example.com", Events: []jobs.EventType{"job.exploded"}},
+		{URL: "https://example.com", Template: `{"content": {{.Job.ID}`},
+		{URL: "https://example.com", MaxAttempts: -1},
+	} {
+		_, err := NewNotifier([]Webhook{webhook}, log)
+		assert.Error(t, err, webhook)
+	}
+}
+
+func TestRender_InvalidJSON(t *testing.T) {
+	ep, err := newEndpoint(Webhook{URL: "https://example.com", Template: `{"content": {{.Job.ID}}}`})
+	require.NoError(t, err)
+
+	_, err = ep.render(testEvent(jobs.EventCompleted))
+	assert.ErrorContains(t, err, "valid JSON")
+}
@@ -0,0 +1,232 @@
+package orchestrator
+
+import (
+	"slices"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+// outcome is what happened to a container during a run
+type outcome int
+
+const (
+	outcomeDone    outcome = iota // Started or stopped
+	outcomeSkipped                // Ignored or blocked
+	outcomeFailed                 // The operation failed
+)
+
+// CriticalPath is the dependency chain that finished last, which bounds how
+// long the operation took
+type CriticalPath struct {
+	Steps    []PathStep    // In execution order
+	Duration time.Duration // From the first operation until the last step finished
+}
+
+// PathStep is one container on the critical path
+type PathStep struct {
+	Container string
+	Duration  time.Duration // Time spent on the container, including health waits and delays
+}
+
+// nodeRun records when a container's operation ran and how it ended
+type nodeRun struct {
+	outcome  outcome
+	reason   string // Why the container failed without being tried
+	started  time.Time
+	finished time.Time
+}
+
+// dagRun is the result of running an operation over a dependency graph
+type dagRun struct {
+	runs    map[*graph.Node]nodeRun
+	began   time.Time
+	reverse bool
+}
+
+// nodeOp runs the operation on a container. failed lists the dependencies
+// whose operation failed. It returns the outcome and, for containers that
+// failed without being tried, the reason.
+type nodeOp func(node *graph.Node, failed []*graph.Node) (outcome, string)
+
+// runDAG runs op on every node as soon as the nodes it waits on have finished:
+// its parents, or its children if reverse is set (shutdown). Nodes that become
+// ready together are passed to ready, if set, and then launched in the order
+// they appear in nodes. Only dependencies between the given nodes are
+// considered; op decides what a failed dependency means. The graph must not
+// contain cycles.
+func runDAG(nodes []*graph.Node, reverse bool, ready func([]*graph.Node), op nodeOp) *dagRun {
+	run := &dagRun{
+		runs:    make(map[*graph.Node]nodeRun, len(nodes)),
+		began:   time.Now(),
+		reverse: reverse,
+	}
+
+	position := make(map[*graph.Node]int, len(nodes))
+	for i, node := range nodes {
+		position[node] = i
+	}
+
+	// Count the unfinished dependencies of each node
+	waitingOn := make(map[*graph.Node]int, len(nodes))
+	for _, node := range nodes {
+		waitingOn[node] = len(run.dependencies(node, position))
+	}
+
+	type completion struct {
+		node *graph.Node
+		run  nodeRun
+	}
+	done := make(chan completion)
+
+	launch := func(node *graph.Node) {
+		// Dependencies have all finished, so their runs are final
+		var failed []*graph.Node
+		for _, dependency := range run.dependencies(node, position) {
+			if run.runs[dependency].outcome == outcomeFailed {
+				failed = append(failed, dependency)
+			}
+		}
+
+		go func() {
+			started := time.Now()
+			result, reason := op(node, failed)
+			done <- completion{node, nodeRun{outcome: result, reason: reason, started: started, finished: time.Now()}}
+		}()
+	}
+
+	launchAll := func(batch []*graph.Node) {
+		if len(batch) == 0 {
+			return
+		}
+		if ready != nil {
+			ready(batch)
+		}
+		for _, node := range batch {
+			launch(node)
+		}
+	}
+
+	var initial []*graph.Node
+	for _, node := range nodes {
+		if waitingOn[node] == 0 {
+			initial = append(initial, node)
+		}
+	}
+	launchAll(initial)
+
+	for range nodes {
+		c := <-done
+		run.runs[c.node] = c.run
+
+		// Launch dependents that were only waiting on this node
+		dependents := run.dependents(c.node, position)
+		slices.SortFunc(dependents, func(a, b *graph.Node) int {
+			return position[a] - position[b]
+		})
+		var batch []*graph.Node
+		for _, dependent := range dependents {
+			waitingOn[dependent]--
+			if waitingOn[dependent] == 0 {
+				batch = append(batch, dependent)
+			}
+		}
+		launchAll(batch)
+	}
+
+	return run
+}
+
+// dependencies returns the nodes that must finish before node runs
+func (r *dagRun) dependencies(node *graph.Node, position map[*graph.Node]int) []*graph.Node {
+	related := node.Parents
+	if r.reverse {
+		related = node.Children
+	}
+	return included(related, position)
+}
+
+// dependents returns the nodes waiting on node
+func (r *dagRun) dependents(node *graph.Node, position map[*graph.Node]int) []*graph.Node {
+	related := node.Children
+	if r.reverse {
+		related = node.Parents
+	}
+	return included(related, position)
+}
+
+// included returns the nodes that are part of the run
+func included(nodes []*graph.Node, position map[*graph.Node]int) []*graph.Node {
+	var result []*graph.Node
+	for _, node := range nodes {
+		if _, ok := position[node]; ok {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// criticalPath traces back from the node that finished last, through the
+// dependency that finished last at each step. It returns nil if nothing ran.
+func (r *dagRun) criticalPath() *CriticalPath {
+	if len(r.runs) == 0 {
+		return nil
+	}
+
+	position := make(map[*graph.Node]int, len(r.runs))
+	var last *graph.Node
+	for node := range r.runs {
+		position[node] = 0
+		if last == nil || r.finishedLater(node, last) {
+			last = node
+		}
+	}
+
+	path := &CriticalPath{Duration: r.runs[last].finished.Sub(r.began)}
+	for node := last; node != nil; {
+		run := r.runs[node]
+		path.Steps = append(path.Steps, PathStep{Container: node.Name, Duration: run.finished.Sub(run.started)})
+
+		var gate *graph.Node
+		for _, dependency := range r.dependencies(node, position) {
+			if gate == nil || r.finishedLater(dependency, gate) {
+				gate = dependency
+			}
+		}
+		node = gate
+	}
+	slices.Reverse(path.Steps)
+
+	return path
+}
+
+// finishedLater reports whether a finished after b, breaking ties by name
+func (r *dagRun) finishedLater(a, b *graph.Node) bool {
+	finishedA, finishedB := r.runs[a].finished, r.runs[b].finished
+	if !finishedA.Equal(finishedB) {
+		return finishedA.After(finishedB)
+	}
+	return a.Name < b.Name
+}
+
+// failReasons returns why containers failed without being tried
+func (r *dagRun) failReasons() map[string]string {
+	reasons := make(map[string]string)
+	for node, run := range r.runs {
+		if run.reason != "" {
+			reasons[node.Name] = run.reason
+		}
+	}
+	return reasons
+}
+
+// runOrder flattens components into the order their containers are scheduled
+func runOrder(components []*graph.ComponentBatches) []*graph.Node {
+	var nodes []*graph.Node
+	for _, component := range components {
+		for _, batch := range component.Batches {
+			nodes = append(nodes, batch...)
+		}
+	}
+	return nodes
+}
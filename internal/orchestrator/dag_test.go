@@ -0,0 +1,153 @@
+package orchestrator
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNode(name string) *graph.Node {
+	return graph.NewNode(container.Summary{Names: []string{"/" + name}})
+}
+
+func TestRunDAG_NoBatchBarrier(t *testing.T) {
+	// db and cache have no dependencies; app only waits for cache
+	db, cache, app, web := testNode("db"), testNode("cache"), testNode("app"), testNode("web")
+	app.AddParent(cache)
+	web.AddParent(db)
+
+	appDone := make(chan struct{})
+	run := runDAG([]*graph.Node{cache, db, app, web}, false, nil, func(n *graph.Node, _ []*graph.Node) (outcome, string) {
+		switch n {
+		case app:
+			close(appDone)
+		case db:
+			// db only finishes once app has run, which a batch barrier would prevent
+			select {
+			case <-appDone:
+			case <-time.After(2 * time.Second):
+				return outcomeFailed, ""
+			}
+		}
+		return outcomeDone, ""
+	})
+
+	for _, node := range []*graph.Node{db, cache, app, web} {
+		assert.Equal(t, outcomeDone, run.runs[node].outcome, node.Name)
+	}
+	assert.False(t, run.runs[web].started.Before(run.runs[db].finished), "web waits for db")
+}
+
+func TestRunDAG_Order(t *testing.T) {
+	db, app, worker := testNode("db"), testNode("app"), testNode("worker")
+	app.AddParent(db)
+	worker.AddParent(app)
+	nodes := []*graph.Node{db, app, worker}
+
+	record := func(order *[]string) nodeOp {
+		var mu sync.Mutex
+		return func(n *graph.Node, _ []*graph.Node) (outcome, string) {
+			mu.Lock()
+			defer mu.Unlock()
+			*order = append(*order, n.Name)
+			return outcomeDone, ""
+		}
+	}
+
+	var startOrder []string
+	runDAG(nodes, false, nil, record(&startOrder))
+	assert.Equal(t, []string{"db", "app", "worker"}, startOrder)
+
+	var stopOrder []string
+	runDAG(nodes, true, nil, record(&stopOrder))
+	assert.Equal(t, []string{"worker", "app", "db"}, stopOrder)
+}
+
+func TestRunDAG_FailedDependency(t *testing.T) {
+	db, app := testNode("db"), testNode("app")
+	app.AddParent(db)
+
+	var appFailedDeps []*graph.Node
+	run := runDAG([]*graph.Node{db, app}, false, nil, func(n *graph.Node, failed []*graph.Node) (outcome, string) {
+		if n == db {
+			return outcomeFailed, ""
+		}
+		appFailedDeps = failed
+		return outcomeFailed, "because of db"
+	})
+
+	assert.Equal(t, outcomeFailed, run.runs[db].outcome)
+	assert.Equal(t, []*graph.Node{db}, appFailedDeps, "dependents still run and see failed dependencies")
+	assert.Equal(t, map[string]string{"app": "because of db"}, run.failReasons())
+}
+
+func TestRequiredDependencyFailure(t *testing.T) {
+	db, auth, app := testNode("db"), testNode("auth"), testNode("app")
+	app.AddParent(db)
+	app.AddOptionalParent(auth)
+
+	assert.Empty(t, requiredDependencyFailure(app, nil))
+	assert.Empty(t, requiredDependencyFailure(app, []*graph.Node{auth}), "optional dependencies may fail")
+	assert.Equal(t, `required dependency "db" failed to start`, requiredDependencyFailure(app, []*graph.Node{db}))
+
+	app.UnmanagedDependencies = []string{"nfs"}
+	assert.Empty(t, requiredDependencyFailure(app, nil), "unmanaged dependencies don't block the start")
+
+	app.MissingDependencies = []string{"redis"}
+	assert.Equal(t, `required dependency "redis" does not exist`, requiredDependencyFailure(app, nil))
+}
+
+func TestRunDAG_IgnoresNodesOutsideRun(t *testing.T) {
+	db, app := testNode("db"), testNode("app")
+	app.AddParent(db)
+
+	run := runDAG([]*graph.Node{app}, false, nil, func(*graph.Node, []*graph.Node) (outcome, string) { return outcomeDone, "" })
+	assert.Len(t, run.runs, 1)
+}
+
+func TestCriticalPath(t *testing.T) {
+	db, cache, app, web := testNode("db"), testNode("cache"), testNode("app"), testNode("web")
+	app.AddParent(db)
+	app.AddParent(cache)
+	web.AddParent(cache)
+
+	began := time.Now()
+	at := func(seconds int) time.Time { return began.Add(time.Duration(seconds) * time.Second) }
+	run := &dagRun{
+		began: began,
+		runs: map[*graph.Node]nodeRun{
+			db:    {started: at(0), finished: at(10)},
+			cache: {started: at(0), finished: at(2)},
+			web:   {started: at(2), finished: at(3)},
+			app:   {started: at(10), finished: at(15)},
+		},
+	}
+
+	path := run.criticalPath()
+	require.NotNil(t, path)
+	assert.Equal(t, 15*time.Second, path.Duration)
+	assert.Equal(t, []PathStep{
+		{Container: "db", Duration: 10 * time.Second},
+		{Container: "app", Duration: 5 * time.Second},
+	}, path.Steps)
+
+	// Shutdown paths follow children
+	run.reverse = true
+	run.runs = map[*graph.Node]nodeRun{
+		app:   {started: at(0), finished: at(4)},
+		web:   {started: at(0), finished: at(1)},
+		db:    {started: at(4), finished: at(6)},
+		cache: {started: at(4), finished: at(5)},
+	}
+	path = run.criticalPath()
+	require.NotNil(t, path)
+	require.Len(t, path.Steps, 2)
+	assert.Equal(t, []string{"app", "db"}, []string{path.Steps[0].Container, path.Steps[1].Container})
+
+	assert.Nil(t, (&dagRun{runs: map[*graph.Node]nodeRun{}}).criticalPath())
+}
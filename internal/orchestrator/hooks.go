@@ -0,0 +1,190 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+// maxHookOutput caps the hook output kept in results
+const maxHookOutput = 4096
+
+// HookResult is the outcome of a lifecycle hook
+type HookResult struct {
+	Phase     string // pre_start, post_start, pre_stop or post_stop
+	Action    string // As written in the label
+	ExitCode  int    // Command exit code or HTTP status; -1 if the hook did not complete
+	Output    string // Command output or response body, truncated
+	Error     string // Empty if the hook succeeded
+	Duration  time.Duration
+	OnFailure docker.HookPolicy
+}
+
+// hookBackend runs commands in containers and looks up their addresses for
+// hooks. The Docker client implements it.
+type hookBackend interface {
+	Exec(ctx context.Context, containerID string, cmd []string) (string, int, error)
+	ContainerIP(ctx context.Context, containerID string) (string, error)
+}
+
+// hookRecorder collects hook results per container while a job runs
+type hookRecorder struct {
+	mu      sync.Mutex
+	results map[string][]HookResult
+}
+
+func newHookRecorder() *hookRecorder {
+	return &hookRecorder{results: make(map[string][]HookResult)}
+}
+
+func (r *hookRecorder) add(container string, result HookResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[container] = append(r.results[container], result)
+}
+
+// all returns the results by container, in the order the hooks ran
+func (r *hookRecorder) all() map[string][]HookResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results
+}
+
+// runHook runs the node's hook for phase, if it has one, and records the
+// result. It returns an error only if the hook failed under the fail policy.
+func (o *Orchestrator) runHook(ctx context.Context, node *graph.Node, phase string, recorder *hookRecorder) error {
+	hook, ok := node.Hooks[phase]
+	if !ok {
+		return nil
+	}
+
+	log := o.log(ctx)
+	log.Info("Running hook",
+		"container", node.Name,
+		"phase", phase,
+		"action", hook.Action)
+
+	result := executeHook(ctx, o.hooks, o.httpClient, node, phase, hook)
+	recorder.add(node.Name, result)
+
+	if result.Error == "" {
+		log.Info("Hook succeeded",
+			"container", node.Name,
+			"phase", phase,
+			"duration", result.Duration)
+		return nil
+	}
+
+	if hook.OnFailure == docker.HookPolicyFail {
+		return fmt.Errorf("%s hook failed: %s", phase, result.Error)
+	}
+
+	log.Warn("Hook failed, continuing",
+		"container", node.Name,
+		"phase", phase,
+		"error", result.Error)
+	return nil
+}
+
+// executeHook runs a hook through Docker exec or HTTP within its timeout
+func executeHook(ctx context.Context, backend hookBackend, httpClient *http.Client, node *graph.Node, phase string, hook docker.Hook) HookResult {
+	result := HookResult{
+		Phase:     phase,
+		Action:    hook.Action,
+		ExitCode:  -1,
+		OnFailure: hook.OnFailure,
+	}
+	started := time.Now()
+
+	action, err := docker.ValidateHook(phase, hook)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = docker.DefaultHookTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	var output string
+	switch action.Kind {
+	case docker.HookKindExec:
+		output, result.ExitCode, err = backend.Exec(hookCtx, node.ID, action.Command)
+		if err == nil && result.ExitCode != 0 {
+			err = fmt.Errorf("command exited with code %d", result.ExitCode)
+		}
+	case docker.HookKindHTTP:
+		output, result.ExitCode, err = callHookURL(hookCtx, backend, httpClient, node, action)
+	}
+
+	result.Output = truncateOutput(output)
+	result.Duration = time.Since(started)
+	if err != nil {
+		result.Error = err.Error()
+		if errors.Is(hookCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			result.Error = fmt.Sprintf("timed out after %ds", timeout)
+		}
+	}
+	return result
+}
+
+// callHookURL sends the hook's HTTP request. A URL without a host is sent to
+// the container's own IP address. Any 2xx status is a success.
+func callHookURL(ctx context.Context, backend hookBackend, httpClient *http.Client, node *graph.Node, action *docker.HookAction) (string, int, error) {
+	target := *action.URL
+	if target.Hostname() == "" {
+		ip, err := backend.ContainerIP(ctx, node.ID)
+		if err != nil {
+			return "", -1, err
+		}
+		port := target.Port()
+		if port == "" {
+			port = "80"
+			if target.Scheme == "https" {
+				port = "443"
+			}
+		}
+		target.Host = net.JoinHostPort(ip, port)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, action.Method, target.String(), nil)
+	if err != nil {
+		return "", -1, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", -1, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHookOutput+1))
+	if err != nil {
+		return string(body), resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return string(body), resp.StatusCode, fmt.Errorf("%s %s returned %s", action.Method, target.Redacted(), resp.Status)
+	}
+	return string(body), resp.StatusCode, nil
+}
+
+// truncateOutput trims output and cuts it to maxHookOutput bytes
+func truncateOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) <= maxHookOutput {
+		return output
+	}
+	return strings.ToValidUTF8(output[:maxHookOutput], "") + "... (truncated)"
+}
@@ -0,0 +1,191 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHookBackend records exec calls and serves a fixed container IP
+type fakeHookBackend struct {
+	output   string
+	exitCode int
+	err      error
+	block    bool // Wait for ctx instead of returning
+	ip       string
+
+	calls [][]string
+}
+
+func (b *fakeHookBackend) Exec(ctx context.Context, containerID string, cmd []string) (string, int, error) {
+	b.calls = append(b.calls, cmd)
+	if b.block {
+		<-ctx.Done()
+		return "partial", -1, ctx.Err()
+	}
+	return b.output, b.exitCode, b.err
+}
+
+func (b *fakeHookBackend) ContainerIP(ctx context.Context, containerID string) (string, error) {
+	if b.ip == "" {
+		return "", errors.New("container has no IP address")
+	}
+	return b.ip, nil
+}
+
+func newHookNode(hooks map[string]docker.Hook) *graph.Node {
+	return &graph.Node{ID: "abc123", Name: "app", Hooks: hooks}
+}
+
+func TestExecuteHook_Exec(t *testing.T) {
+	node := newHookNode(nil)
+	backend := &fakeHookBackend{output: "flushed\n", exitCode: 0}
+
+	result := executeHook(context.Background(), backend, http.DefaultClient, node, docker.HookPreStop,
+		docker.Hook{Action: "exec:/app/flush.sh --all", Timeout: 5, OnFailure: docker.HookPolicyFail})
+
+	assert.Equal(t, [][]string{{"/app/flush.sh", "--all"}}, backend.calls)
+	assert.Equal(t, docker.HookPreStop, result.Phase)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "flushed", result.Output)
+	assert.Empty(t, result.Error)
+	assert.Equal(t, docker.HookPolicyFail, result.OnFailure)
+
+	backend = &fakeHookBackend{output: "disk full", exitCode: 3}
+	result = executeHook(context.Background(), backend, http.DefaultClient, node, docker.HookPreStop,
+		docker.Hook{Action: "exec:/app/flush.sh", Timeout: 5})
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "disk full", result.Output)
+	assert.Equal(t, "command exited with code 3", result.Error)
+}
+
+func TestExecuteHook_Timeout(t *testing.T) {
+	backend := &fakeHookBackend{block: true}
+
+	result := executeHook(context.Background(), backend, http.DefaultClient, newHookNode(nil), docker.HookPostStart,
+		docker.Hook{Action: "exec:sleep 60", Timeout: 1})
+
+	assert.Equal(t, -1, result.ExitCode)
+	assert.Equal(t, "partial", result.Output)
+	assert.Equal(t, "timed out after 1s", result.Error)
+}
+
+func TestExecuteHook_InvalidForPhase(t *testing.T) {
+	backend := &fakeHookBackend{}
+
+	result := executeHook(context.Background(), backend, http.DefaultClient, newHookNode(nil), docker.HookPreStart,
+		docker.Hook{Action: "exec:/app/prepare.sh", Timeout: 5})
+
+	assert.Empty(t, backend.calls)
+	assert.Equal(t, -1, result.ExitCode)
+	assert.Contains(t, result.Error, "explicit host")
+}
+
+func TestExecuteHook_HTTP(t *testing.T) {
+	var gotMethod, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		if r.URL.Path == "/broken" {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("warm"))
+	}))
+	defer server.Close()
+
+	// An explicit host is called as is
+	result := executeHook(context.Background(), &fakeHookBackend{}, server.Client(), newHookNode(nil), docker.HookPreStart,
+		docker.Hook{Action: "POST " + server.URL + "/prepare", Timeout: 5})
+	assert.Empty(t, result.Error)
+	assert.Equal(t, http.StatusOK, result.ExitCode)
+	assert.Equal(t, "warm", result.Output)
+	assert.Equal(t, "POST", gotMethod)
+	assert.Equal(t, "/prepare", gotPath)
+
+	// A URL without a host goes to the container's IP address
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	backend := &fakeHookBackend{ip: host}
+
+	result = executeHook(context.Background(), backend, server.Client(), newHookNode(nil), docker.HookPostStart,
+		docker.Hook{Action: "http://:" + port + "/warmup", Timeout: 5})
+	assert.Empty(t, result.Error)
+	assert.Equal(t, "GET", gotMethod)
+	assert.Equal(t, "/warmup", gotPath)
+
+	// Non-2xx responses fail the hook and keep the status and body
+	result = executeHook(context.Background(), backend, server.Client(), newHookNode(nil), docker.HookPostStart,
+		docker.Hook{Action: "http://:" + port + "/broken", Timeout: 5})
+	assert.Equal(t, http.StatusServiceUnavailable, result.ExitCode)
+	assert.Equal(t, "not ready", result.Output)
+	assert.Contains(t, result.Error, "503")
+
+	// Without an IP address the hook cannot run
+	result = executeHook(context.Background(), &fakeHookBackend{}, server.Client(), newHookNode(nil), docker.HookPostStart,
+		docker.Hook{Action: "http://:" + port + "/warmup", Timeout: 5})
+	assert.Equal(t, -1, result.ExitCode)
+	assert.Contains(t, result.Error, "no IP address")
+}
+
+func TestRunHook_Policies(t *testing.T) {
+	log, _ := logger.New(false)
+	orch := New(&docker.Client{}, log)
+	backend := &fakeHookBackend{exitCode: 1}
+	orch.hooks = backend
+
+	node := newHookNode(map[string]docker.Hook{
+		docker.HookPreStop:   {Action: "exec:/app/flush.sh", Timeout: 5, OnFailure: docker.HookPolicyContinue},
+		docker.HookPostStart: {Action: "exec:/app/warm.sh", Timeout: 5, OnFailure: docker.HookPolicyFail},
+	})
+	recorder := newHookRecorder()
+
+	// Containers without a hook for the phase record nothing
+	require.NoError(t, orch.runHook(context.Background(), node, docker.HookPreStart, recorder))
+
+	assert.NoError(t, orch.runHook(context.Background(), node, docker.HookPreStop, recorder))
+	err := orch.runHook(context.Background(), node, docker.HookPostStart, recorder)
+	assert.EqualError(t, err, "post_start hook failed: command exited with code 1")
+
+	results := recorder.all()["app"]
+	require.Len(t, results, 2)
+	assert.Equal(t, docker.HookPreStop, results[0].Phase)
+	assert.Equal(t, docker.HookPostStart, results[1].Phase)
+}
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "ok", truncateOutput("  ok\n"))
+
+	long := truncateOutput(strings.Repeat("é", maxHookOutput))
+	assert.True(t, strings.HasSuffix(long, "... (truncated)"))
+	assert.LessOrEqual(t, len(long), maxHookOutput+len("... (truncated)"))
+	assert.NotContains(t, long, "�")
+}
+
+func TestHookRecorder_Concurrent(t *testing.T) {
+	recorder := newHookRecorder()
+	done := make(chan struct{})
+	for range 10 {
+		go func() {
+			recorder.add("app", HookResult{Duration: time.Millisecond})
+			done <- struct{}{}
+		}()
+	}
+	for range 10 {
+		<-done
+	}
+	assert.Len(t, recorder.all()["app"], 10)
+}
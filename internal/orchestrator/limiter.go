@@ -0,0 +1,177 @@
+package orchestrator
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/saltyorg/sdc/internal/graph"
+)
+
+// limiter bounds how many container operations run at once, overall and per
+// concurrency group. It is shared by every component and job. Waiting
+// operations are admitted in scheduling order (higher priority first, then by
+// name), skipping those whose group is full.
+//
+// Nodes that became ready together are expected before any of them asks for
+// a slot. An expected node keeps the slot it would be admitted to free until
+// it acquires it or is forgotten, so the Docker calls a node makes on the way
+// to acquire don't decide who gets the free slots.
+type limiter struct {
+	mu           sync.Mutex
+	global       int            // Maximum concurrent operations (0 = unlimited)
+	groupLimits  map[string]int // Overrides the group_concurrency labels
+	running      int
+	groupRunning map[string]int
+	waiting      []*waiter
+	expected     []*graph.Node
+}
+
+// waiter is an operation waiting for a slot
+type waiter struct {
+	node     *graph.Node
+	limit    int // Group limit (0 = unlimited)
+	admitted chan struct{}
+}
+
+// newLimiter creates a limiter. A global limit of 0 means unlimited.
+func newLimiter(global int, groupLimits map[string]int) *limiter {
+	return &limiter{
+		global:       global,
+		groupLimits:  groupLimits,
+		groupRunning: make(map[string]int),
+	}
+}
+
+// acquire blocks until an operation on node may run or ctx is done. The
+// returned function releases the slot and must be called exactly once.
+func (l *limiter) acquire(ctx context.Context, node *graph.Node) (func(), error) {
+	w := &waiter{node: node, limit: l.groupLimit(node), admitted: make(chan struct{})}
+
+	l.mu.Lock()
+	l.expected = slices.DeleteFunc(l.expected, func(other *graph.Node) bool { return other == node })
+	l.waiting = append(l.waiting, w)
+	l.admitLocked()
+	l.mu.Unlock()
+
+	select {
+	case <-w.admitted:
+		return func() { l.release(node) }, nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-w.admitted:
+			// Admitted while giving up; pass the slot on
+			l.releaseLocked(node)
+		default:
+			l.waiting = slices.DeleteFunc(l.waiting, func(other *waiter) bool { return other == w })
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// expect registers nodes that are about to acquire a slot
+func (l *limiter) expect(nodes []*graph.Node) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, node := range nodes {
+		if !slices.Contains(l.expected, node) {
+			l.expected = append(l.expected, node)
+		}
+	}
+}
+
+// forget drops an expected node that won't acquire a slot soon, e.g. because
+// it was skipped or waits for its dependencies first. Forgetting a node that
+// isn't expected does nothing.
+func (l *limiter) forget(node *graph.Node) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i := slices.Index(l.expected, node); i >= 0 {
+		l.expected = slices.Delete(l.expected, i, i+1)
+		l.admitLocked()
+	}
+}
+
+// groupLimit returns the concurrency limit of node's group
+func (l *limiter) groupLimit(node *graph.Node) int {
+	if node.Group == "" {
+		return 0
+	}
+	if limit, ok := l.groupLimits[node.Group]; ok {
+		return limit
+	}
+	return node.GroupConcurrency
+}
+
+// limited reports whether any limit applies to operations on node
+func (l *limiter) limited(node *graph.Node) bool {
+	return l.global > 0 || l.groupLimit(node) > 0
+}
+
+// release frees the slot held by an operation on node
+func (l *limiter) release(node *graph.Node) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked(node)
+}
+
+func (l *limiter) releaseLocked(node *graph.Node) {
+	l.running--
+	if node.Group != "" {
+		l.groupRunning[node.Group]--
+	}
+	l.admitLocked()
+}
+
+// admitLocked admits every waiting operation that fits within the limits.
+// Expected nodes hold back the slots they would be admitted to.
+func (l *limiter) admitLocked() {
+	type candidate struct {
+		node  *graph.Node
+		limit int
+		w     *waiter // nil for an expected node
+	}
+	candidates := make([]candidate, 0, len(l.waiting)+len(l.expected))
+	for _, w := range l.waiting {
+		candidates = append(candidates, candidate{w.node, w.limit, w})
+	}
+	for _, node := range l.expected {
+		candidates = append(candidates, candidate{node, l.groupLimit(node), nil})
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return graph.CompareNodes(a.node, b.node)
+	})
+
+	held := 0
+	groupHeld := make(map[string]int)
+	remaining := l.waiting[:0]
+	for _, c := range candidates {
+		group := c.node.Group
+		globalFull := l.global > 0 && l.running+held >= l.global
+		groupFull := group != "" && c.limit > 0 && l.groupRunning[group]+groupHeld[group] >= c.limit
+		if globalFull || groupFull {
+			if c.w != nil {
+				remaining = append(remaining, c.w)
+			}
+			continue
+		}
+
+		if c.w == nil {
+			held++
+			if group != "" {
+				groupHeld[group]++
+			}
+			continue
+		}
+
+		l.running++
+		if group != "" {
+			l.groupRunning[group]++
+		}
+		close(c.w.admitted)
+	}
+	clear(l.waiting[len(remaining):])
+	l.waiting = remaining
+}
@@ -0,0 +1,179 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// acquireAsync acquires a slot for node in the background and reports the
+// release function once admitted
+func acquireAsync(ctx context.Context, l *limiter, node *graph.Node) <-chan func() {
+	admitted := make(chan func(), 1)
+	go func() {
+		if release, err := l.acquire(ctx, node); err == nil {
+			admitted <- release
+		}
+	}()
+	return admitted
+}
+
+// waitForWaiters waits until n operations are queued in the limiter
+func waitForWaiters(t *testing.T, l *limiter, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return len(l.waiting) == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := newLimiter(0, nil)
+
+	var releases []func()
+	for _, name := range []string{"a", "b", "c"} {
+		release, err := l.acquire(context.Background(), &graph.Node{Name: name})
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+	assert.Equal(t, 3, l.running)
+
+	for _, release := range releases {
+		release()
+	}
+	assert.Equal(t, 0, l.running)
+}
+
+func TestLimiter_Global(t *testing.T) {
+	l := newLimiter(1, nil)
+
+	release, err := l.acquire(context.Background(), &graph.Node{Name: "a"})
+	require.NoError(t, err)
+
+	admitted := acquireAsync(context.Background(), l, &graph.Node{Name: "b"})
+	waitForWaiters(t, l, 1)
+	assert.Empty(t, admitted)
+
+	release()
+	select {
+	case releaseB := <-admitted:
+		releaseB()
+	case <-time.After(time.Second):
+		t.Fatal("waiting operation was not admitted")
+	}
+}
+
+func TestLimiter_Group(t *testing.T) {
+	l := newLimiter(0, nil)
+	plex := &graph.Node{Name: "plex", Group: "media", GroupConcurrency: 1}
+	emby := &graph.Node{Name: "emby", Group: "media", GroupConcurrency: 1}
+	sonarr := &graph.Node{Name: "sonarr", Group: "arr", GroupConcurrency: 1}
+
+	release, err := l.acquire(context.Background(), plex)
+	require.NoError(t, err)
+
+	// Another group is not held up
+	releaseSonarr, err := l.acquire(context.Background(), sonarr)
+	require.NoError(t, err)
+	releaseSonarr()
+
+	admitted := acquireAsync(context.Background(), l, emby)
+	waitForWaiters(t, l, 1)
+	assert.Empty(t, admitted)
+
+	release()
+	(<-admitted)()
+	assert.Equal(t, 0, l.groupRunning["media"])
+}
+
+func TestLimiter_GroupOverride(t *testing.T) {
+	l := newLimiter(0, map[string]int{"media": 2})
+
+	for _, name := range []string{"plex", "emby"} {
+		_, err := l.acquire(context.Background(), &graph.Node{Name: name, Group: "media", GroupConcurrency: 1})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, l.groupRunning["media"])
+}
+
+func TestLimiter_PriorityOrder(t *testing.T) {
+	l := newLimiter(1, nil)
+
+	release, err := l.acquire(context.Background(), &graph.Node{Name: "first"})
+	require.NoError(t, err)
+
+	low := acquireAsync(context.Background(), l, &graph.Node{Name: "a", Priority: -1})
+	waitForWaiters(t, l, 1)
+	high := acquireAsync(context.Background(), l, &graph.Node{Name: "b", Priority: 10})
+	waitForWaiters(t, l, 2)
+
+	release()
+	releaseHigh := <-high
+	assert.Empty(t, low)
+
+	releaseHigh()
+	(<-low)()
+}
+
+func TestLimiter_GroupFullDoesNotBlockOthers(t *testing.T) {
+	l := newLimiter(2, nil)
+
+	release, err := l.acquire(context.Background(), &graph.Node{Name: "plex", Group: "media", GroupConcurrency: 1})
+	require.NoError(t, err)
+	defer release()
+
+	// emby sorts first but its group is full, so sonarr takes the free slot
+	emby := acquireAsync(context.Background(), l, &graph.Node{Name: "emby", Group: "media", GroupConcurrency: 1, Priority: 5})
+	waitForWaiters(t, l, 1)
+	releaseSonarr, err := l.acquire(context.Background(), &graph.Node{Name: "sonarr"})
+	require.NoError(t, err)
+	releaseSonarr()
+	assert.Empty(t, emby)
+}
+
+func TestLimiter_Cancel(t *testing.T) {
+	l := newLimiter(1, nil)
+
+	release, err := l.acquire(context.Background(), &graph.Node{Name: "a"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.acquire(ctx, &graph.Node{Name: "b"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, l.waiting)
+
+	release()
+	assert.Equal(t, 0, l.running)
+}
+
+func TestLimiter_ExpectedHoldsSlot(t *testing.T) {
+	l := newLimiter(1, nil)
+	high := &graph.Node{Name: "high", Priority: 10}
+	low := &graph.Node{Name: "low"}
+
+	// high became ready with low but asks for a slot later
+	l.expect([]*graph.Node{high, low})
+	lowAdmitted := acquireAsync(context.Background(), l, low)
+	waitForWaiters(t, l, 1)
+	assert.Empty(t, lowAdmitted)
+
+	release, err := l.acquire(context.Background(), high)
+	require.NoError(t, err)
+	assert.Empty(t, lowAdmitted)
+	release()
+	(<-lowAdmitted)()
+
+	// A forgotten node no longer holds its slot
+	l.expect([]*graph.Node{high})
+	lowAdmitted = acquireAsync(context.Background(), l, low)
+	waitForWaiters(t, l, 1)
+	l.forget(high)
+	(<-lowAdmitted)()
+	assert.Empty(t, l.expected)
+}
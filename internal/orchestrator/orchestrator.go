@@ -3,39 +3,128 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"maps"
+	"net/http"
+	"slices"
 	"time"
 
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/blocks"
 	"github.com/saltyorg/sdc/internal/docker"
 	"github.com/saltyorg/sdc/internal/graph"
+	"github.com/saltyorg/sdc/internal/lint"
 	"github.com/saltyorg/sdc/pkg/logger"
 )
 
+// Skip reasons reported for skipped containers
+const (
+	SkipReasonIgnored = "ignored" // Listed in the request's ignore list
+	SkipReasonBlocked = "blocked" // Covered by an active block
+)
+
+// CyclePolicy decides what a job does when the dependency labels form a cycle
+type CyclePolicy string
+
+const (
+	// CyclePolicyFail fails the whole job
+	CyclePolicyFail CyclePolicy = "fail"
+	// CyclePolicyQuarantine fails only the containers connected to a cycle and
+	// processes the rest
+	CyclePolicyQuarantine CyclePolicy = "quarantine"
+)
+
+// ParseCyclePolicy parses a cycle policy name
+func ParseCyclePolicy(s string) (CyclePolicy, error) {
+	switch policy := CyclePolicy(s); policy {
+	case CyclePolicyFail, CyclePolicyQuarantine:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid cycle policy %q: must be %s or %s", s, CyclePolicyFail, CyclePolicyQuarantine)
+	}
+}
+
+// Blocker reports active blocks preventing an operation on a container
+type Blocker interface {
+	Blocking(op blocks.Operation, container string) (*blocks.Block, bool)
+}
+
+// containerRuntime starts, stops and inspects single containers. The Docker
+// client implements it.
+type containerRuntime interface {
+	IsContainerRunning(ctx context.Context, containerNameOrID string) (bool, error)
+	StartContainer(ctx context.Context, containerID string) error
+	StopContainer(ctx context.Context, containerID string, timeout int) error
+	HasHealthCheck(ctx context.Context, containerNameOrID string) (bool, error)
+	GetHealthStatus(ctx context.Context, containerNameOrID string) (string, error)
+}
+
 // Orchestrator manages container lifecycle operations with dependency awareness
 type Orchestrator struct {
 	docker  *docker.Client
+	runtime containerRuntime
 	builder *graph.Builder
 	logger  *logger.Logger
+	blocker Blocker
+
+	cyclePolicy CyclePolicy
+	limiter     *limiter
+
+	hooks      hookBackend
+	httpClient *http.Client // For HTTP hooks; each hook sets its own timeout
+
+	healthInterval time.Duration // How often health status is polled
 }
 
 // New creates a new orchestrator instance
 func New(dockerClient *docker.Client, logger *logger.Logger) *Orchestrator {
 	return &Orchestrator{
 		docker:  dockerClient,
+		runtime: dockerClient,
 		builder: graph.NewBuilder(dockerClient, logger),
 		logger:  logger,
+
+		cyclePolicy: CyclePolicyFail,
+		limiter:     newLimiter(0, nil),
+
+		hooks:      dockerClient,
+		httpClient: &http.Client{},
+
+		healthInterval: 500 * time.Millisecond,
 	}
 }
 
+// SetBlocker makes the orchestrator skip containers with an active block.
+// Passing nil (the default) disables block checks.
+func (o *Orchestrator) SetBlocker(blocker Blocker) {
+	o.blocker = blocker
+}
+
+// SetCyclePolicy sets how jobs handle dependency cycles (default: CyclePolicyFail)
+func (o *Orchestrator) SetCyclePolicy(policy CyclePolicy) {
+	o.cyclePolicy = policy
+}
+
+// SetConcurrencyLimits bounds how many container starts and stops run at once
+// across all jobs. global of 0 means unlimited. groups maps a concurrency group
+// to its limit, overriding the group_concurrency labels. Call it before any job runs.
+func (o *Orchestrator) SetConcurrencyLimits(global int, groups map[string]int) {
+	o.limiter = newLimiter(global, groups)
+}
+
 // StartContainersOptions configures container startup behavior
 type StartContainersOptions struct {
-	Timeout int      // Operation timeout in seconds
-	Ignore  []string // Container names to skip
+	Timeout             int      // Operation timeout in seconds
+	Ignore              []string // Container names to skip
+	Targets             []string // Container names to start (empty = all managed containers)
+	IncludeDependencies bool     // Also start the containers targets depend on
 }
 
 // StopContainersOptions configures container shutdown behavior
 type StopContainersOptions struct {
-	Timeout int      // Operation timeout in seconds
-	Ignore  []string // Container names to skip
+	Timeout             int      // Operation timeout in seconds
+	Ignore              []string // Container names to skip
+	Targets             []string // Container names to stop (empty = all managed containers)
+	IncludeDependencies bool     // Also stop the containers that depend on targets
 }
 
 // StartResult contains the results of a start operation
@@ -43,6 +132,13 @@ type StartResult struct {
 	Started []string // Names of containers that were started
 	Skipped []string // Names of containers that were skipped
 	Failed  []string // Names of containers that failed to start
+
+	SkipReasons map[string]string // Why each skipped container was skipped (SkipReason*)
+	FailReasons map[string]string // Why containers failed without being tried (quarantined cycles, missing or failed required dependencies)
+
+	CriticalPath *CriticalPath // Dependency chain that finished last (nil if nothing ran)
+
+	Hooks map[string][]HookResult // Lifecycle hook results by container
 }
 
 // StopResult contains the results of a stop operation
@@ -50,11 +146,25 @@ type StopResult struct {
 	Stopped []string // Names of containers that were stopped
 	Skipped []string // Names of containers that were skipped
 	Failed  []string // Names of containers that failed to stop
+
+	SkipReasons map[string]string // Why each skipped container was skipped (SkipReason*)
+	FailReasons map[string]string // Why containers failed without being tried (quarantined cycles)
+
+	CriticalPath *CriticalPath // Dependency chain that finished last (nil if nothing ran)
+
+	Hooks map[string][]HookResult // Lifecycle hook results by container
+}
+
+// log returns the job-scoped logger carried by ctx, falling back to the orchestrator's logger
+func (o *Orchestrator) log(ctx context.Context) *logger.Logger {
+	return logger.FromContext(ctx, o.logger)
 }
 
 // StartContainers starts all managed containers in dependency order
 func (o *Orchestrator) StartContainers(ctx context.Context, opts StartContainersOptions) (*StartResult, error) {
-	o.logger.Info("Starting container orchestration",
+	log := o.log(ctx)
+
+	log.Info("Starting container orchestration",
 		"timeout", opts.Timeout,
 		"ignore", opts.Ignore)
 
@@ -68,7 +178,7 @@ func (o *Orchestrator) StartContainers(ctx context.Context, opts StartContainers
 		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	o.logger.Info("Found managed containers", "count", len(containers))
+	log.Info("Found managed containers", "count", len(containers))
 
 	// Build dependency graph
 	g, err := o.builder.Build(ctx, containers)
@@ -76,125 +186,65 @@ func (o *Orchestrator) StartContainers(ctx context.Context, opts StartContainers
 		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
 	}
 
-	// Get connected components for parallel execution
-	components, err := g.GetConnectedComponents()
+	// Narrow the graph to the requested targets
+	g, err = selectTargets(g, opts.Targets, opts.IncludeDependencies, graph.ClosureAncestors)
 	if err != nil {
-		return nil, fmt.Errorf("failed to identify connected components: %w", err)
+		return nil, err
 	}
 
-	o.logger.Info("Identified connected components",
-		"component_count", len(components))
+	// Decide up front which containers are ignored or blocked
+	skipReasons := o.skipReasons(log, g, blocks.OperationStart, opts.Ignore)
 
-	// Create ignore map for fast lookup
-	ignoreMap := make(map[string]bool)
-	for _, name := range opts.Ignore {
-		ignoreMap[name] = true
-	}
+	// Set aside containers connected to a dependency cycle, if configured
+	g, quarantined := o.quarantineCycles(log, g, skipReasons)
 
-	// Process each component in parallel using goroutines
-	type componentResult struct {
-		started []string
-		skipped []string
-		failed  []string
+	// Get connected components, which fix the scheduling order
+	components, err := g.GetConnectedComponents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify connected components: %w", err)
 	}
 
-	resultChan := make(chan componentResult, len(components))
-
-	for componentIdx, component := range components {
-		go func(idx int, comp *graph.ComponentBatches) {
-			compResult := componentResult{
-				started: []string{},
-				skipped: []string{},
-				failed:  []string{},
-			}
-
-			// Get container names for this component
-			var containerNames []string
-			for _, batch := range comp.Batches {
-				for _, node := range batch {
-					containerNames = append(containerNames, node.Name)
-				}
-			}
-
-			// Only log multi-container components at INFO level
-			if len(containerNames) > 1 {
-				o.logger.Info("Processing component",
-					"containers", containerNames,
-					"batch_count", len(comp.Batches))
-			} else {
-				o.logger.Debug("Processing component",
-					"containers", containerNames,
-					"batch_count", len(comp.Batches))
-			}
+	log.Info("Identified connected components",
+		"component_count", len(components))
 
-			// Process batches sequentially (respecting dependencies between batches)
-			for batchIdx, batch := range comp.Batches {
-				o.logger.Debug("Processing batch within component",
-					"component", idx,
-					"batch", batchIdx,
-					"containers", len(batch))
-
-				// Process containers in this batch in parallel
-				type batchResult struct {
-					started []string
-					skipped []string
-					failed  []string
-				}
-				batchChan := make(chan batchResult, len(batch))
-
-				for _, node := range batch {
-					go func(n *graph.Node) {
-						br := batchResult{
-							started: []string{},
-							skipped: []string{},
-							failed:  []string{},
-						}
-
-						if ignoreMap[n.Name] {
-							br.skipped = append(br.skipped, n.Name)
-						} else if err := o.startContainer(timeoutCtx, n); err != nil {
-							o.logger.Error("Failed to start container",
-								"container", n.Name,
-								"component", idx,
-								"batch", batchIdx,
-								"error", err)
-							br.failed = append(br.failed, n.Name)
-						} else {
-							br.started = append(br.started, n.Name)
-						}
-
-						batchChan <- br
-					}(node)
-				}
-
-				// Collect results from this batch
-				for range batch {
-					br := <-batchChan
-					compResult.started = append(compResult.started, br.started...)
-					compResult.skipped = append(compResult.skipped, br.skipped...)
-					compResult.failed = append(compResult.failed, br.failed...)
-				}
-			}
+	// Start each container as soon as its own dependencies are done
+	hooks := newHookRecorder()
+	order := runOrder(components)
+	run := runDAG(order, false, o.limiter.expect, o.startOp(timeoutCtx, skipReasons, hooks))
 
-			resultChan <- compResult
-		}(componentIdx, component)
+	// Collect results in scheduling order
+	result := &StartResult{
+		Started:      []string{},
+		Skipped:      []string{},
+		Failed:       []string{},
+		SkipReasons:  skipReasons,
+		FailReasons:  run.failReasons(),
+		CriticalPath: run.criticalPath(),
+		Hooks:        hooks.all(),
 	}
 
-	// Collect results from all components
-	result := &StartResult{
-		Started: []string{},
-		Skipped: []string{},
-		Failed:  []string{},
+	// Quarantined containers are reported without being tried
+	maps.Copy(result.FailReasons, quarantined)
+	result.Failed = append(result.Failed, slices.Sorted(maps.Keys(quarantined))...)
+	for _, name := range slices.Sorted(maps.Keys(skipReasons)) {
+		if _, ok := g.Nodes[name]; !ok {
+			result.Skipped = append(result.Skipped, name)
+		}
 	}
 
-	for range components {
-		compResult := <-resultChan
-		result.Started = append(result.Started, compResult.started...)
-		result.Skipped = append(result.Skipped, compResult.skipped...)
-		result.Failed = append(result.Failed, compResult.failed...)
+	for _, node := range order {
+		switch run.runs[node].outcome {
+		case outcomeDone:
+			result.Started = append(result.Started, node.Name)
+		case outcomeSkipped:
+			result.Skipped = append(result.Skipped, node.Name)
+		case outcomeFailed:
+			result.Failed = append(result.Failed, node.Name)
+		}
 	}
+	logCriticalPath(log, result.CriticalPath)
 
-	o.logger.Info("Container startup complete",
+	log.Info("Container startup complete",
 		"started", len(result.Started),
 		"skipped", len(result.Skipped),
 		"failed", len(result.Failed))
@@ -204,7 +254,9 @@ func (o *Orchestrator) StartContainers(ctx context.Context, opts StartContainers
 
 // StopContainers stops all managed containers in reverse dependency order
 func (o *Orchestrator) StopContainers(ctx context.Context, opts StopContainersOptions) (*StopResult, error) {
-	o.logger.Info("Stopping container orchestration",
+	log := o.log(ctx)
+
+	log.Info("Stopping container orchestration",
 		"timeout", opts.Timeout,
 		"ignore", opts.Ignore)
 
@@ -218,7 +270,7 @@ func (o *Orchestrator) StopContainers(ctx context.Context, opts StopContainersOp
 		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	o.logger.Info("Found managed containers", "count", len(containers))
+	log.Info("Found managed containers", "count", len(containers))
 
 	// Build dependency graph
 	g, err := o.builder.Build(ctx, containers)
@@ -226,166 +278,342 @@ func (o *Orchestrator) StopContainers(ctx context.Context, opts StopContainersOp
 		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
 	}
 
-	// Get connected components for parallel execution (in shutdown order)
+	// Narrow the graph to the requested targets
+	g, err = selectTargets(g, opts.Targets, opts.IncludeDependencies, graph.ClosureDescendants)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decide up front which containers are ignored or blocked
+	skipReasons := o.skipReasons(log, g, blocks.OperationStop, opts.Ignore)
+
+	// Set aside containers connected to a dependency cycle, if configured
+	g, quarantined := o.quarantineCycles(log, g, skipReasons)
+
+	// Get connected components in shutdown order, which fix the scheduling order
 	components, err := g.GetConnectedComponentsForShutdown()
 	if err != nil {
 		return nil, fmt.Errorf("failed to identify connected components: %w", err)
 	}
 
-	o.logger.Info("Identified connected components for shutdown",
+	log.Info("Identified connected components for shutdown",
 		"component_count", len(components))
 
-	// Create ignore map for fast lookup
-	ignoreMap := make(map[string]bool)
-	for _, name := range opts.Ignore {
-		ignoreMap[name] = true
+	// Stop each container as soon as its dependents are done
+	hooks := newHookRecorder()
+	order := runOrder(components)
+	run := runDAG(order, true, o.limiter.expect, o.stopOp(timeoutCtx, skipReasons, hooks))
+
+	// Collect results in scheduling order
+	result := &StopResult{
+		Stopped:      []string{},
+		Skipped:      []string{},
+		Failed:       []string{},
+		SkipReasons:  skipReasons,
+		FailReasons:  run.failReasons(),
+		CriticalPath: run.criticalPath(),
+		Hooks:        hooks.all(),
 	}
 
-	// Process each component in parallel using goroutines
-	type componentResult struct {
-		stopped []string
-		skipped []string
-		failed  []string
+	// Quarantined containers are reported without being tried
+	maps.Copy(result.FailReasons, quarantined)
+	result.Failed = append(result.Failed, slices.Sorted(maps.Keys(quarantined))...)
+	for _, name := range slices.Sorted(maps.Keys(skipReasons)) {
+		if _, ok := g.Nodes[name]; !ok {
+			result.Skipped = append(result.Skipped, name)
+		}
 	}
 
-	resultChan := make(chan componentResult, len(components))
+	for _, node := range order {
+		switch run.runs[node].outcome {
+		case outcomeDone:
+			result.Stopped = append(result.Stopped, node.Name)
+		case outcomeSkipped:
+			result.Skipped = append(result.Skipped, node.Name)
+		case outcomeFailed:
+			result.Failed = append(result.Failed, node.Name)
+		}
+	}
+	logCriticalPath(log, result.CriticalPath)
 
-	for componentIdx, component := range components {
-		go func(idx int, comp *graph.ComponentBatches) {
-			compResult := componentResult{
-				stopped: []string{},
-				skipped: []string{},
-				failed:  []string{},
-			}
+	log.Info("Container shutdown complete",
+		"stopped", len(result.Stopped),
+		"skipped", len(result.Skipped),
+		"failed", len(result.Failed))
 
-			// Get container names for this component
-			var containerNames []string
-			for _, batch := range comp.Batches {
-				for _, node := range batch {
-					containerNames = append(containerNames, node.Name)
-				}
-			}
+	return result, nil
+}
 
-			// Only log multi-container components at INFO level
-			if len(containerNames) > 1 {
-				o.logger.Info("Processing shutdown component",
-					"containers", containerNames,
-					"batch_count", len(comp.Batches))
-			} else {
-				o.logger.Debug("Processing shutdown component",
-					"containers", containerNames,
-					"batch_count", len(comp.Batches))
-			}
+// logCriticalPath logs the dependency chain that bounded an operation
+func logCriticalPath(log *logger.Logger, path *CriticalPath) {
+	if path == nil {
+		return
+	}
 
-			// Process batches sequentially (respecting dependencies between batches)
-			for batchIdx, batch := range comp.Batches {
-				o.logger.Debug("Processing batch within component",
-					"component", idx,
-					"batch", batchIdx,
-					"containers", len(batch))
-
-				// Process containers in this batch in parallel
-				type batchResult struct {
-					stopped []string
-					skipped []string
-					failed  []string
-				}
-				batchChan := make(chan batchResult, len(batch))
-
-				for _, node := range batch {
-					go func(n *graph.Node) {
-						br := batchResult{
-							stopped: []string{},
-							skipped: []string{},
-							failed:  []string{},
-						}
-
-						if ignoreMap[n.Name] {
-							br.skipped = append(br.skipped, n.Name)
-						} else if err := o.stopContainer(timeoutCtx, n); err != nil {
-							o.logger.Error("Failed to stop container",
-								"container", n.Name,
-								"component", idx,
-								"batch", batchIdx,
-								"error", err)
-							br.failed = append(br.failed, n.Name)
-						} else {
-							br.stopped = append(br.stopped, n.Name)
-						}
-
-						batchChan <- br
-					}(node)
-				}
-
-				// Collect results from this batch
-				for range batch {
-					br := <-batchChan
-					compResult.stopped = append(compResult.stopped, br.stopped...)
-					compResult.skipped = append(compResult.skipped, br.skipped...)
-					compResult.failed = append(compResult.failed, br.failed...)
-				}
-			}
+	containers := make([]string, len(path.Steps))
+	for i, step := range path.Steps {
+		containers[i] = step.Container
+	}
+	log.Info("Critical path",
+		"containers", containers,
+		"duration", path.Duration.Round(time.Millisecond).String())
+}
 
-			resultChan <- compResult
-		}(componentIdx, component)
+// Inventory is a snapshot of the labelled containers and their dependency graph
+type Inventory struct {
+	Containers []container.Summary // Every container with the saltbox_managed label
+	Graph      *graph.Graph        // Built from the containers the controller manages
+}
+
+// Inventory lists the labelled containers and builds their dependency graph
+func (o *Orchestrator) Inventory(ctx context.Context) (*Inventory, error) {
+	containers, err := o.docker.ListManagedContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	// Collect results from all components
-	result := &StopResult{
-		Stopped: []string{},
-		Skipped: []string{},
-		Failed:  []string{},
+	g, err := o.builder.Build(ctx, containers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency graph: %w", err)
 	}
+	return &Inventory{Containers: containers, Graph: g}, nil
+}
 
-	for range components {
-		compResult := <-resultChan
-		result.Stopped = append(result.Stopped, compResult.stopped...)
-		result.Skipped = append(result.Skipped, compResult.skipped...)
-		result.Failed = append(result.Failed, compResult.failed...)
+// Graph builds the current dependency graph of the managed containers
+func (o *Orchestrator) Graph(ctx context.Context) (*graph.Graph, error) {
+	inventory, err := o.Inventory(ctx)
+	if err != nil {
+		return nil, err
 	}
+	return inventory.Graph, nil
+}
 
-	o.logger.Info("Container shutdown complete",
-		"stopped", len(result.Stopped),
-		"skipped", len(result.Skipped),
-		"failed", len(result.Failed))
+// Lint checks the labels of the managed containers and their dependency graph
+func (o *Orchestrator) Lint(ctx context.Context) (*lint.Report, error) {
+	inventory, err := o.Inventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return lint.Check(ctx, inventory.Containers, inventory.Graph, o.docker), nil
+}
 
-	return result, nil
+// AffectedContainers returns the names of the managed containers an operation
+// on targets may touch: the targets plus everything in the given closure.
+// Targets that don't exist are included as given.
+func (o *Orchestrator) AffectedContainers(ctx context.Context, targets []string, closure graph.Closure) ([]string, error) {
+	g, err := o.Graph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, unmatched := g.Resolve(targets)
+	selected, missing := g.Select(resolved, closure)
+
+	names := append(unmatched, missing...)
+	for name := range selected.Nodes {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// selectTargets restricts the graph to the target containers and groups,
+// optionally pulling in related containers in the given closure direction,
+// across groups. An empty target list selects the whole graph.
+func selectTargets(g *graph.Graph, targets []string, includeDependencies bool, closure graph.Closure) (*graph.Graph, error) {
+	if len(targets) == 0 {
+		return g, nil
+	}
+
+	if !includeDependencies {
+		closure = graph.ClosureNone
+	}
+
+	resolved, unmatched := g.Resolve(targets)
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("target groups and patterns match no containers: %v", unmatched)
+	}
+
+	selected, missing := g.Select(resolved, closure)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("target containers not found: %v", missing)
+	}
+
+	return selected, nil
+}
+
+// quarantineCycles removes the connected components containing a dependency
+// cycle from g under CyclePolicyQuarantine, returning why each removed
+// container failed. Removed containers that are skipped anyway are not
+// reported as failed. Otherwise g is returned as is, and a cycle fails the job.
+func (o *Orchestrator) quarantineCycles(log *logger.Logger, g *graph.Graph, skipReasons map[string]string) (*graph.Graph, map[string]string) {
+	if o.cyclePolicy != CyclePolicyQuarantine {
+		return g, nil
+	}
+
+	acyclic, quarantined := g.QuarantineCycles()
+	if len(quarantined) == 0 {
+		return g, nil
+	}
+
+	failReasons := make(map[string]string, len(quarantined))
+	for name, cycle := range quarantined {
+		if _, skip := skipReasons[name]; !skip {
+			failReasons[name] = "dependency cycle: " + cycle.String()
+		}
+	}
+
+	log.Error("Quarantined containers connected to dependency cycles",
+		"containers", slices.Sorted(maps.Keys(quarantined)),
+		"cycles", len(g.Cycles()))
+
+	return acyclic, failReasons
+}
+
+// skipReasons returns the containers of the graph that must not be touched
+// by op, keyed by name, with the reason why
+func (o *Orchestrator) skipReasons(log *logger.Logger, g *graph.Graph, op blocks.Operation, ignore []string) map[string]string {
+	reasons := make(map[string]string)
+
+	// Ignored groups that match no container are ignored like unknown names
+	names, _ := g.Resolve(ignore)
+	for _, name := range names {
+		if _, exists := g.Nodes[name]; exists {
+			reasons[name] = SkipReasonIgnored
+		}
+	}
+
+	if o.blocker == nil {
+		return reasons
+	}
+
+	for name, node := range g.Nodes {
+		if node.IsPlaceholder || reasons[name] != "" {
+			continue
+		}
+		if block, blocked := o.blocker.Blocking(op, name); blocked {
+			log.Info("Container blocked, skipping",
+				"container", name,
+				"operation", string(op),
+				"block_id", block.ID,
+				"reason", block.Reason)
+			reasons[name] = SkipReasonBlocked
+		}
+	}
+
+	return reasons
+}
+
+// requiredDependencyFailure explains why node can't start because a required
+// dependency is missing or failed to start, or returns "" if it can. A
+// dependency that exists without the saltbox_managed label doesn't block the
+// start: the controller never starts it, so it is up to whoever manages it.
+func requiredDependencyFailure(node *graph.Node, failedDeps []*graph.Node) string {
+	if len(node.MissingDependencies) > 0 {
+		return fmt.Sprintf("required dependency %q does not exist", node.MissingDependencies[0])
+	}
+	for _, dep := range failedDeps {
+		if !node.IsOptionalParent(dep) {
+			return fmt.Sprintf("required dependency %q failed to start", dep.Name)
+		}
+	}
+	return ""
+}
+
+// startOp returns the operation that starts each container of a run. Nodes
+// passed to runDAG's ready function are forgotten by the limiter once their
+// operation ends, whether or not it took a slot.
+func (o *Orchestrator) startOp(ctx context.Context, skipReasons map[string]string, hooks *hookRecorder) nodeOp {
+	log := o.log(ctx)
+	return func(n *graph.Node, failedDeps []*graph.Node) (outcome, string) {
+		defer o.limiter.forget(n)
+
+		if _, skip := skipReasons[n.Name]; skip {
+			return outcomeSkipped, ""
+		}
+		if reason := requiredDependencyFailure(n, failedDeps); reason != "" {
+			log.Error("Not starting container",
+				"container", n.Name,
+				"reason", reason)
+			return outcomeFailed, reason
+		}
+		if len(n.UnmanagedDependencies) > 0 {
+			log.Warn("Required dependencies are not managed, starting anyway",
+				"container", n.Name,
+				"dependencies", n.UnmanagedDependencies)
+		}
+		if err := o.startContainer(ctx, n, failedDeps, hooks); err != nil {
+			log.Error("Failed to start container",
+				"container", n.Name,
+				"error", err)
+			return outcomeFailed, ""
+		}
+		return outcomeDone, ""
+	}
+}
+
+// stopOp returns the operation that stops each container of a run
+func (o *Orchestrator) stopOp(ctx context.Context, skipReasons map[string]string, hooks *hookRecorder) nodeOp {
+	log := o.log(ctx)
+	return func(n *graph.Node, _ []*graph.Node) (outcome, string) {
+		defer o.limiter.forget(n)
+
+		if _, skip := skipReasons[n.Name]; skip {
+			return outcomeSkipped, ""
+		}
+		if err := o.stopContainer(ctx, n, hooks); err != nil {
+			log.Error("Failed to stop container",
+				"container", n.Name,
+				"error", err)
+			return outcomeFailed, ""
+		}
+		return outcomeDone, ""
+	}
 }
 
-// startContainer starts a single container with health check and delay support
-func (o *Orchestrator) startContainer(ctx context.Context, node *graph.Node) error {
+// startContainer starts a single container with health check, delay and hook
+// support. Health checks of failedDeps (optional dependencies that failed) are
+// not awaited. Hook results are added to hooks.
+func (o *Orchestrator) startContainer(ctx context.Context, node *graph.Node, failedDeps []*graph.Node, hooks *hookRecorder) error {
+	log := o.log(ctx)
+
 	// Check if already running
-	running, err := o.docker.IsContainerRunning(ctx, node.Name)
+	running, err := o.runtime.IsContainerRunning(ctx, node.Name)
 	if err != nil {
 		return fmt.Errorf("failed to check container status: %w", err)
 	}
 
 	if running {
-		o.logger.Debug("Container already running, skipping",
+		log.Debug("Container already running, skipping",
 			"container", node.Name)
 		return nil
 	}
 
-	o.logger.Info("Starting container",
+	log.Info("Starting container",
 		"container", node.Name,
 		"delay", node.StartupDelay,
 		"wait_healthcheck", node.WaitForHealthcheck)
 
+	// Waiting for dependencies or a delay must not hold back other
+	// containers' slots
+	if (node.WaitForHealthcheck && len(node.Parents) > 0) || node.StartupDelay > 0 {
+		o.limiter.forget(node)
+	}
+
 	// Wait for parent dependencies' health checks if configured
 	if node.WaitForHealthcheck && len(node.Parents) > 0 {
-		o.logger.Info("Waiting for parent dependencies' health checks",
+		log.Info("Waiting for parent dependencies' health checks",
 			"container", node.Name,
 			"parent_count", len(node.Parents))
 
 		for _, parent := range node.Parents {
-			if parent.IsPlaceholder {
+			if parent.IsPlaceholder || slices.Contains(failedDeps, parent) {
 				continue
 			}
 
 			// Check if parent has a healthcheck
-			hasHealthCheck, err := o.docker.HasHealthCheck(ctx, parent.Name)
+			hasHealthCheck, err := o.runtime.HasHealthCheck(ctx, parent.Name)
 			if err != nil {
-				o.logger.Warn("Failed to check parent health config",
+				log.Warn("Failed to check parent health config",
 					"container", node.Name,
 					"parent", parent.Name,
 					"error", err)
@@ -393,7 +621,7 @@ func (o *Orchestrator) startContainer(ctx context.Context, node *graph.Node) err
 			}
 
 			if !hasHealthCheck {
-				o.logger.Debug("Parent has no healthcheck, skipping",
+				log.Debug("Parent has no healthcheck, skipping",
 					"container", node.Name,
 					"parent", parent.Name)
 				continue
@@ -401,7 +629,7 @@ func (o *Orchestrator) startContainer(ctx context.Context, node *graph.Node) err
 
 			// Wait for parent to be healthy
 			if err := o.waitForHealthy(ctx, parent); err != nil {
-				o.logger.Warn("Parent health check wait failed, continuing anyway",
+				log.Warn("Parent health check wait failed, continuing anyway",
 					"container", node.Name,
 					"parent", parent.Name,
 					"error", err)
@@ -413,7 +641,7 @@ func (o *Orchestrator) startContainer(ctx context.Context, node *graph.Node) err
 	// Apply startup delay if configured
 	if node.StartupDelay > 0 {
 		delay := time.Duration(node.StartupDelay) * time.Second
-		o.logger.Debug("Applying startup delay",
+		log.Debug("Applying startup delay",
 			"container", node.Name,
 			"delay", delay)
 
@@ -425,27 +653,53 @@ func (o *Orchestrator) startContainer(ctx context.Context, node *graph.Node) err
 		}
 	}
 
-	// Start the container
-	if err := o.docker.StartContainer(ctx, node.ID); err != nil {
+	// Start the container once a concurrency slot is free. The slot is held
+	// for the whole start, including hooks and the container's own health wait.
+	release, err := o.limiter.acquire(ctx, node)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := o.runHook(ctx, node, docker.HookPreStart, hooks); err != nil {
+		return err
+	}
+
+	if err := o.runtime.StartContainer(ctx, node.ID); err != nil {
 		return fmt.Errorf("failed to start container: %w", err)
 	}
 
-	o.logger.Info("Container started successfully",
+	log.Info("Container started successfully",
 		"container", node.Name)
 
-	return nil
+	// Under a concurrency limit, a start lasts until the container is healthy,
+	// so limits bound the boot load rather than the API calls. The post_start
+	// hook also waits, so that it calls into a ready container.
+	_, hasPostStart := node.Hooks[docker.HookPostStart]
+	if o.limiter.limited(node) || hasPostStart {
+		if hasHealthCheck, err := o.runtime.HasHealthCheck(ctx, node.Name); err == nil && hasHealthCheck {
+			if err := o.waitForHealthy(ctx, node); err != nil {
+				return err
+			}
+		}
+	}
+
+	return o.runHook(ctx, node, docker.HookPostStart, hooks)
 }
 
-// stopContainer stops a single container using its configured StopTimeout
-func (o *Orchestrator) stopContainer(ctx context.Context, node *graph.Node) error {
+// stopContainer stops a single container using its configured StopTimeout,
+// running its stop hooks around it. Hook results are added to hooks.
+func (o *Orchestrator) stopContainer(ctx context.Context, node *graph.Node, hooks *hookRecorder) error {
+	log := o.log(ctx)
+
 	// Check if already stopped
-	running, err := o.docker.IsContainerRunning(ctx, node.Name)
+	running, err := o.runtime.IsContainerRunning(ctx, node.Name)
 	if err != nil {
 		return fmt.Errorf("failed to check container status: %w", err)
 	}
 
 	if !running {
-		o.logger.Debug("Container already stopped, skipping",
+		log.Debug("Container already stopped, skipping",
 			"container", node.Name)
 		return nil
 	}
@@ -454,46 +708,59 @@ func (o *Orchestrator) stopContainer(ctx context.Context, node *graph.Node) erro
 	var timeout int
 	if node.StopTimeout != nil {
 		timeout = *node.StopTimeout
-		o.logger.Info("Stopping container",
+		log.Info("Stopping container",
 			"container", node.Name,
 			"timeout", timeout)
 	} else {
 		timeout = 10 // Docker default
-		o.logger.Info("Stopping container",
+		log.Info("Stopping container",
 			"container", node.Name,
 			"timeout", "default (10s)")
 	}
 
-	// Stop the container
-	if err := o.docker.StopContainer(ctx, node.ID, timeout); err != nil {
+	// Stop the container once a concurrency slot is free. The slot is held
+	// for the whole stop, including hooks.
+	release, err := o.limiter.acquire(ctx, node)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := o.runHook(ctx, node, docker.HookPreStop, hooks); err != nil {
+		return err
+	}
+
+	if err := o.runtime.StopContainer(ctx, node.ID, timeout); err != nil {
 		return fmt.Errorf("failed to stop container: %w", err)
 	}
 
-	o.logger.Info("Container stopped successfully",
+	log.Info("Container stopped successfully",
 		"container", node.Name)
 
-	return nil
+	return o.runHook(ctx, node, docker.HookPostStop, hooks)
 }
 
 // waitForHealthy waits for a container to become healthy
 func (o *Orchestrator) waitForHealthy(ctx context.Context, node *graph.Node) error {
+	log := o.log(ctx)
+
 	// Check if container has health check configured
-	hasHealthCheck, err := o.docker.HasHealthCheck(ctx, node.Name)
+	hasHealthCheck, err := o.runtime.HasHealthCheck(ctx, node.Name)
 	if err != nil {
 		return fmt.Errorf("failed to check health config: %w", err)
 	}
 
 	if !hasHealthCheck {
-		o.logger.Warn("Health check expected but not configured",
+		log.Warn("Health check expected but not configured",
 			"container", node.Name)
 		return nil // Don't fail, just continue
 	}
 
-	o.logger.Info("Waiting for container to become healthy",
+	log.Info("Waiting for container to become healthy",
 		"container", node.Name)
 
 	// Poll for healthy status
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := time.NewTicker(o.healthInterval)
 	defer ticker.Stop()
 
 	timeout := time.After(60 * time.Second)
@@ -503,24 +770,24 @@ func (o *Orchestrator) waitForHealthy(ctx context.Context, node *graph.Node) err
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-timeout:
-			o.logger.Warn("Health check timeout, continuing anyway",
+			log.Warn("Health check timeout, continuing anyway",
 				"container", node.Name)
 			return nil // Don't fail, just warn
 		case <-ticker.C:
-			status, err := o.docker.GetHealthStatus(ctx, node.Name)
+			status, err := o.runtime.GetHealthStatus(ctx, node.Name)
 			if err != nil {
-				o.logger.Debug("Failed to get health status, retrying",
+				log.Debug("Failed to get health status, retrying",
 					"container", node.Name,
 					"error", err)
 				continue // Retry
 			}
 
-			o.logger.Debug("Health check status",
+			log.Debug("Health check status",
 				"container", node.Name,
 				"status", status)
 
 			if status == "healthy" {
-				o.logger.Info("Container is healthy",
+				log.Info("Container is healthy",
 					"container", node.Name)
 				return nil
 			}
@@ -1,11 +1,20 @@
 package orchestrator
 
 import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/moby/moby/api/types/container"
+	"github.com/saltyorg/sdc/internal/blocks"
 	"github.com/saltyorg/sdc/internal/docker"
+	"github.com/saltyorg/sdc/internal/graph"
 	"github.com/saltyorg/sdc/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -67,6 +76,125 @@ func TestStopResult(t *testing.T) {
 	assert.Len(t, result.Failed, 0)
 }
 
+type fakeBlocker struct {
+	blocked map[string]bool
+	op      blocks.Operation
+}
+
+func (f *fakeBlocker) Blocking(op blocks.Operation, container string) (*blocks.Block, bool) {
+	if op != f.op || !f.blocked[container] {
+		return nil, false
+	}
+	return &blocks.Block{ID: "block-1", Scope: blocks.ScopeContainers, Reason: "backup"}, true
+}
+
+func TestSkipReasons(t *testing.T) {
+	log, _ := logger.New(true)
+	orch := New(&docker.Client{}, log)
+
+	g := &graph.Graph{Nodes: map[string]*graph.Node{
+		"plex":     graph.NewNode(container.Summary{Names: []string{"/plex"}}),
+		"sonarr":   graph.NewNode(container.Summary{Names: []string{"/sonarr"}}),
+		"traefik":  graph.NewNode(container.Summary{Names: []string{"/traefik"}}),
+		"postgres": graph.NewPlaceholderNode("postgres"),
+	}}
+
+	// Without a blocker only the ignore list applies
+	reasons := orch.skipReasons(log, g, blocks.OperationStart, []string{"traefik", "unknown"})
+	assert.Equal(t, map[string]string{"traefik": SkipReasonIgnored}, reasons)
+
+	orch.SetBlocker(&fakeBlocker{
+		op:      blocks.OperationStart,
+		blocked: map[string]bool{"plex": true, "traefik": true, "postgres": true},
+	})
+
+	reasons = orch.skipReasons(log, g, blocks.OperationStart, []string{"traefik"})
+	assert.Equal(t, map[string]string{
+		"plex":    SkipReasonBlocked,
+		"traefik": SkipReasonIgnored, // Ignore takes precedence
+	}, reasons)
+
+	// Blocks for other operations don't apply
+	reasons = orch.skipReasons(log, g, blocks.OperationStop, nil)
+	assert.Empty(t, reasons)
+}
+
+func TestSkipReasons_Groups(t *testing.T) {
+	log, _ := logger.New(true)
+	orch := New(&docker.Client{}, log)
+
+	node := func(name string, groups ...string) *graph.Node {
+		n := graph.NewNode(container.Summary{Names: []string{"/" + name}})
+		n.Groups = groups
+		return n
+	}
+	g := &graph.Graph{Nodes: map[string]*graph.Node{
+		"plex":    node("plex", "media"),
+		"emby":    node("emby", "media"),
+		"sonarr":  node("sonarr", "arr"),
+		"traefik": node("traefik"),
+	}}
+
+	reasons := orch.skipReasons(log, g, blocks.OperationStop, []string{"group:media", "traefik", "group:unknown"})
+	assert.Equal(t, map[string]string{
+		"plex":    SkipReasonIgnored,
+		"emby":    SkipReasonIgnored,
+		"traefik": SkipReasonIgnored,
+	}, reasons)
+}
+
+func TestSelectTargets_Groups(t *testing.T) {
+	app := graph.NewNode(container.Summary{Names: []string{"/app"}})
+	db := graph.NewNode(container.Summary{Names: []string{"/db"}})
+	app.Groups = []string{"web"}
+	app.AddParent(db)
+	g := &graph.Graph{Nodes: map[string]*graph.Node{"app": app, "db": db}}
+
+	selected, err := selectTargets(g, []string{"group=web"}, true, graph.ClosureAncestors)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app", "db"}, slices.Collect(maps.Keys(selected.Nodes)))
+
+	_, err = selectTargets(g, []string{"group=nope"}, true, graph.ClosureAncestors)
+	assert.ErrorContains(t, err, "group=nope")
+}
+
+func TestParseCyclePolicy(t *testing.T) {
+	policy, err := ParseCyclePolicy("quarantine")
+	assert.NoError(t, err)
+	assert.Equal(t, CyclePolicyQuarantine, policy)
+
+	_, err = ParseCyclePolicy("ignore")
+	assert.Error(t, err)
+}
+
+func TestQuarantineCycles(t *testing.T) {
+	log, _ := logger.New(true)
+	orch := New(&docker.Client{}, log)
+
+	node := func(name string) *graph.Node {
+		return graph.NewNode(container.Summary{Names: []string{"/" + name}})
+	}
+	a, b, c, plex := node("a"), node("b"), node("c"), node("plex")
+	a.AddParent(b)
+	b.AddParent(a)
+	c.AddParent(a)
+	g := &graph.Graph{Nodes: map[string]*graph.Node{"a": a, "b": b, "c": c, "plex": plex}}
+
+	// The default policy leaves the cycle for the job to fail on
+	same, failReasons := orch.quarantineCycles(log, g, nil)
+	assert.Same(t, g, same)
+	assert.Nil(t, failReasons)
+
+	orch.SetCyclePolicy(CyclePolicyQuarantine)
+	acyclic, failReasons := orch.quarantineCycles(log, g, map[string]string{"c": SkipReasonIgnored})
+
+	assert.Equal(t, []string{"plex"}, graph.GetNodeNames(slices.Collect(maps.Values(acyclic.Nodes))))
+	assert.Equal(t, map[string]string{
+		"a": "dependency cycle: a -> b -> a",
+		"b": "dependency cycle: a -> b -> a",
+	}, failReasons, "ignored containers are not reported as failed")
+}
+
 // Note: Integration tests with actual Docker API would require:
 // 1. Running Docker daemon
 // 2. Test containers with proper labels
@@ -74,3 +202,193 @@ func TestStopResult(t *testing.T) {
 //
 // For now, we verify the basic structure and types compile correctly.
 // Full integration testing will be done in Phase 4 (E2E tests).
+
+// fakeRuntime is a container runtime and hook backend that records, in order,
+// which container each call was for. Containers report "starting" for
+// startingPolls health polls, hooks take hookDelay, and status checks take
+// checkDelay of the container.
+type fakeRuntime struct {
+	startingPolls int
+	hookDelay     time.Duration
+	checkDelay    map[string]time.Duration
+
+	mu      sync.Mutex
+	polls   map[string]int
+	running map[string]bool
+	calls   []string
+}
+
+func newFakeRuntime(startingPolls int, hookDelay time.Duration, running ...string) *fakeRuntime {
+	r := &fakeRuntime{startingPolls: startingPolls, hookDelay: hookDelay, polls: make(map[string]int), running: make(map[string]bool)}
+	for _, name := range running {
+		r.running[name] = true
+	}
+	return r
+}
+
+func (r *fakeRuntime) record(container string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, container)
+}
+
+func (r *fakeRuntime) IsContainerRunning(ctx context.Context, name string) (bool, error) {
+	time.Sleep(r.checkDelay[name])
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running[name], nil
+}
+
+func (r *fakeRuntime) StartContainer(ctx context.Context, id string) error {
+	r.record(id)
+	return nil
+}
+
+func (r *fakeRuntime) StopContainer(ctx context.Context, id string, timeout int) error {
+	r.record(id)
+	return nil
+}
+
+func (r *fakeRuntime) HasHealthCheck(ctx context.Context, name string) (bool, error) {
+	return true, nil
+}
+
+func (r *fakeRuntime) GetHealthStatus(ctx context.Context, name string) (string, error) {
+	r.record(name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.polls[name]++
+	if r.polls[name] <= r.startingPolls {
+		return "starting", nil
+	}
+	return "healthy", nil
+}
+
+func (r *fakeRuntime) Exec(ctx context.Context, id string, cmd []string) (string, int, error) {
+	r.record(id)
+	time.Sleep(r.hookDelay)
+	r.record(id)
+	return "", 0, nil
+}
+
+func (r *fakeRuntime) ContainerIP(ctx context.Context, id string) (string, error) {
+	return "", nil
+}
+
+// assertSerialized checks that the recorded calls of each container form one
+// unbroken run, i.e. no two container operations overlapped
+func assertSerialized(t *testing.T, calls []string, containers int) {
+	t.Helper()
+	runs := slices.Compact(slices.Clone(calls))
+	assert.Len(t, runs, containers, "operations overlapped: %v", calls)
+}
+
+func newRuntimeOrchestrator(r *fakeRuntime) *Orchestrator {
+	log, _ := logger.New(false)
+	o := New(&docker.Client{}, log)
+	o.runtime = r
+	o.hooks = r
+	o.healthInterval = time.Millisecond
+	o.SetConcurrencyLimits(1, nil)
+	return o
+}
+
+func TestStartContainer_HoldsSlotUntilHealthy(t *testing.T) {
+	r := newFakeRuntime(5, 5*time.Millisecond)
+	o := newRuntimeOrchestrator(r)
+	hooks := map[string]docker.Hook{
+		docker.HookPreStart:  {Action: "exec:warm"},
+		docker.HookPostStart: {Action: "exec:seed"},
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"app", "worker", "web"} {
+		node := &graph.Node{ID: name, Name: name, Hooks: hooks}
+		wg.Go(func() {
+			assert.NoError(t, o.startContainer(context.Background(), node, nil, newHookRecorder()))
+		})
+	}
+	wg.Wait()
+
+	// Each container's hooks, start and health polls ran without another
+	// container's operation in between
+	require.NotEmpty(t, r.calls)
+	assertSerialized(t, r.calls, 3)
+}
+
+func TestStartContainer_LimitWaitsForHealthWithoutHooks(t *testing.T) {
+	r := newFakeRuntime(5, 0)
+	o := newRuntimeOrchestrator(r)
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"app", "worker"} {
+		node := &graph.Node{ID: name, Name: name}
+		wg.Go(func() {
+			assert.NoError(t, o.startContainer(context.Background(), node, nil, newHookRecorder()))
+		})
+	}
+	wg.Wait()
+
+	assertSerialized(t, r.calls, 2)
+	assert.Equal(t, 6, r.polls["app"])
+	assert.Equal(t, 6, r.polls["worker"])
+}
+
+func TestStopContainer_HoldsSlotThroughHooks(t *testing.T) {
+	r := newFakeRuntime(0, 5*time.Millisecond, "app", "worker", "web")
+	o := newRuntimeOrchestrator(r)
+	hooks := map[string]docker.Hook{
+		docker.HookPreStop:  {Action: "exec:flush"},
+		docker.HookPostStop: {Action: "exec:notify"},
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"app", "worker", "web"} {
+		node := &graph.Node{ID: name, Name: name, Hooks: hooks}
+		wg.Go(func() {
+			assert.NoError(t, o.stopContainer(context.Background(), node, newHookRecorder()))
+		})
+	}
+	wg.Wait()
+
+	assertSerialized(t, r.calls, 3)
+}
+
+func TestRunDAG_StartAdmitsByPriority(t *testing.T) {
+	// The highest priority container is slowest to check, yet starts first
+	r := newFakeRuntime(0, 0)
+	r.checkDelay = map[string]time.Duration{"overseerr": 30 * time.Millisecond}
+	o := newRuntimeOrchestrator(r)
+
+	nodes := []*graph.Node{
+		{ID: "ignored", Name: "ignored", Priority: 200},
+		{ID: "overseerr", Name: "overseerr", Priority: 100},
+		{ID: "radarr", Name: "radarr"},
+		{ID: "sonarr", Name: "sonarr"},
+	}
+	skipReasons := map[string]string{"ignored": "ignored"}
+	run := runDAG(nodes, false, o.limiter.expect, o.startOp(context.Background(), skipReasons, newHookRecorder()))
+
+	assert.Equal(t, outcomeSkipped, run.runs[nodes[0]].outcome)
+	assert.Equal(t, []string{"overseerr", "radarr", "sonarr"}, slices.Compact(slices.Clone(r.calls)))
+	assert.Empty(t, o.limiter.expected)
+}
+
+func TestRunDAG_StopAdmitsGroupByPriority(t *testing.T) {
+	r := newFakeRuntime(0, 0, "plex", "emby", "jellyfin", "sonarr")
+	r.checkDelay = map[string]time.Duration{"emby": 20 * time.Millisecond, "jellyfin": 40 * time.Millisecond}
+	o := newRuntimeOrchestrator(r)
+	o.SetConcurrencyLimits(0, nil)
+
+	media := func(name string, priority int) *graph.Node {
+		return &graph.Node{ID: name, Name: name, Priority: priority, Group: "media", GroupConcurrency: 1}
+	}
+	nodes := []*graph.Node{media("jellyfin", 100), media("emby", 50), {ID: "sonarr", Name: "sonarr"}, media("plex", 0)}
+	runDAG(nodes, true, o.limiter.expect, o.stopOp(context.Background(), nil, newHookRecorder()))
+
+	// Members of the group are stopped one at a time by priority; others
+	// aren't held up
+	stopped := slices.DeleteFunc(slices.Clone(r.calls), func(name string) bool { return name == "sonarr" })
+	assert.Equal(t, []string{"jellyfin", "emby", "plex"}, stopped)
+	assert.Contains(t, r.calls, "sonarr")
+}
@@ -0,0 +1,226 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors are the supported @-shorthands for common expressions
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekdayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// field describes the accepted values of one cron field
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: weekdayNames}, // 7 is Sunday too
+}
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. Fields accept *, values, ranges (a-b), steps (*/n,
+// a-b/n) and comma-separated lists; months and weekdays also accept their
+// three-letter names. As in classic cron, when both day fields are
+// restricted a day matching either one fires.
+type Cron struct {
+	expr              string
+	minute, hour, dom uint64
+	month, dow        uint64
+	domStar, dowStar  bool
+}
+
+// ParseCron parses a cron expression or one of the descriptors @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight and @hourly
+func ParseCron(expr string) (*Cron, error) {
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron descriptor %q", spec)
+		}
+		spec = expanded
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields, got %d", expr, len(fields), len(parts))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday may be written as 0 or 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+
+	return &Cron{
+		expr:    strings.TrimSpace(expr),
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseField parses a comma-separated list of cron terms into a bit set
+func parseField(spec string, f field) (uint64, error) {
+	var set uint64
+	for term := range strings.SplitSeq(spec, ",") {
+		lo, hi, step, err := parseTerm(term, f)
+		if err != nil {
+			return 0, err
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// parseTerm parses one term: *, a value or a range, optionally with a /step.
+// A value with a step (a/n) runs up to the field maximum.
+func parseTerm(term string, f field) (lo, hi, step int, err error) {
+	rng, stepStr, hasStep := strings.Cut(term, "/")
+	step = 1
+	if hasStep {
+		step, err = strconv.Atoi(stepStr)
+		if err != nil || step < 1 {
+			return 0, 0, 0, fmt.Errorf("invalid step %q in %s field", stepStr, f.name)
+		}
+	}
+
+	switch {
+	case rng == "*":
+		return f.min, f.max, step, nil
+	case strings.Contains(rng, "-"):
+		first, last, _ := strings.Cut(rng, "-")
+		if lo, err = parseValue(first, f); err != nil {
+			return 0, 0, 0, err
+		}
+		if hi, err = parseValue(last, f); err != nil {
+			return 0, 0, 0, err
+		}
+		if lo > hi {
+			return 0, 0, 0, fmt.Errorf("invalid range %q in %s field", rng, f.name)
+		}
+		return lo, hi, step, nil
+	default:
+		if lo, err = parseValue(rng, f); err != nil {
+			return 0, 0, 0, err
+		}
+		if hasStep {
+			return lo, f.max, step, nil
+		}
+		return lo, lo, step, nil
+	}
+}
+
+// parseValue parses a number or name within the field's bounds
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field: must be between %d and %d", s, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression as written
+func (c *Cron) String() string {
+	return c.expr
+}
+
+// Next returns the first time after t that matches the expression, in t's
+// location, or the zero time if none falls within the next five years
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+wrap:
+	for t.Year() <= yearLimit {
+		for !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+
+		for !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+
+		for !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(time.Hour)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+
+		for !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches applies the classic cron rule: if either day field is *, both
+// must match; otherwise a match in either field is enough
+func (c *Cron) dayMatches(t time.Time) bool {
+	domMatch := has(c.dom, t.Day())
+	dowMatch := has(c.dow, int(t.Weekday()))
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<v) != 0
+}
@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@sometimes",
+	} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, time.March, 4, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 4, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.March, 4, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, time.March, 5, 10, 30, 0, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2026, time.March, 5, 4, 0, 0, 0, time.UTC)},
+		{"0 4 * * sun", time.Date(2026, time.March, 8, 4, 0, 0, 0, time.UTC)},
+		{"0 4 * * 7", time.Date(2026, time.March, 8, 4, 0, 0, 0, time.UTC)},
+		{"0 4 * * mon-fri", time.Date(2026, time.March, 5, 4, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 jan,jul *", time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, time.March, 4, 10, 45, 0, 0, time.UTC)},
+		{"0 8-18/4 * * *", time.Date(2026, time.March, 4, 12, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches
+		{"0 0 15 * fri", time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cron.Next(from))
+		})
+	}
+}
+
+func TestCron_NextNever(t *testing.T) {
+	cron, err := ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	assert.True(t, cron.Next(time.Now()).IsZero())
+}
+
+func TestCron_NextDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+
+	// 02:30 does not exist on the day clocks go forward
+	cron, err := ParseCron("30 2 * * *")
+	require.NoError(t, err)
+
+	next := cron.Next(time.Date(2026, time.March, 28, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, time.March, 30, 2, 30, 0, 0, loc), next)
+}
@@ -0,0 +1,550 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saltyorg/sdc/internal/blocks"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+)
+
+const (
+	// DefaultHistory is the number of runs kept per schedule
+	DefaultHistory = 10
+
+	// PollInterval is how often a run checks whether its job has finished
+	PollInterval = 2 * time.Second
+)
+
+var (
+	// ErrNotFound is returned for unknown schedule IDs
+	ErrNotFound = errors.New("schedule not found")
+
+	// ErrDuplicateName is returned when a schedule name is already taken
+	ErrDuplicateName = errors.New("schedule name already in use")
+
+	// ErrReadOnly is returned when removing a schedule defined in the configuration
+	ErrReadOnly = errors.New("schedule is defined in the configuration")
+)
+
+// Operation is what a schedule does when it fires
+type Operation string
+
+const (
+	OperationStart   Operation = "start"
+	OperationStop    Operation = "stop"
+	OperationRestart Operation = "restart" // A stop job followed by a start job
+)
+
+// Operations lists all schedule operations
+var Operations = []Operation{OperationStart, OperationStop, OperationRestart}
+
+// Source tells where a schedule was defined
+type Source string
+
+const (
+	SourceConfig Source = "config" // Schedule file; cannot be removed through the API
+	SourceAPI    Source = "api"    // POST /schedules; persisted in the state directory
+)
+
+// RunStatus is the outcome of one firing of a schedule
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusCompleted RunStatus = "completed"
+	RunStatusFailed    RunStatus = "failed"
+	RunStatusSkipped   RunStatus = "skipped" // Blocked, or the previous run was still running
+)
+
+// Definition describes when a schedule fires and the jobs it submits
+type Definition struct {
+	ID                  string    `json:"id"`
+	Name                string    `json:"name,omitempty"`
+	Cron                string    `json:"cron"`
+	Operation           Operation `json:"operation"`
+	Targets             []string  `json:"targets,omitempty"` // Empty = all managed containers
+	Ignore              []string  `json:"ignore,omitempty"`
+	Timeout             int       `json:"timeout"`              // Seconds, for each job
+	IncludeDependencies bool      `json:"include_dependencies"` // Pull in dependencies (start) or dependents (stop) of targets
+	Source              Source    `json:"source"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// Run records one firing of a schedule
+type Run struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Status      RunStatus `json:"status"`
+	JobIDs      []string  `json:"job_ids,omitempty"` // In submission order
+	Reason      string    `json:"reason,omitempty"`  // Why the run was skipped or failed
+}
+
+// Schedule is a definition with its next fire time and recent runs
+type Schedule struct {
+	Definition
+	NextRun time.Time `json:"next_run"` // Zero if the expression never matches again
+	Runs    []Run     `json:"runs"`     // Newest first
+}
+
+// Submitter queues jobs and reports their progress
+type Submitter interface {
+	Submit(job *jobs.Job) (string, error)
+	Get(id string) (*jobs.Job, error)
+}
+
+// Blocker reports blocks covering a whole operation
+type Blocker interface {
+	BlockingOperation(op blocks.Operation) (*blocks.Block, bool)
+}
+
+// entry is a registered schedule and its state
+type entry struct {
+	def    Definition
+	cron   *Cron
+	next   time.Time
+	runs   []*Run // Oldest first
+	active *Run   // Run still waiting for its jobs
+}
+
+// Scheduler submits jobs when schedules fire. A schedule is skipped while
+// its previous run is still going or while a block covers its operation.
+type Scheduler struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	submitter Submitter
+	blocker   Blocker
+	clock     Clock
+	logger    *logger.Logger
+	history   int
+	stateFile string        // Empty = API schedules are kept in memory only
+	wake      chan struct{} // Signals the loop that schedules changed
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler that submits jobs to submitter.
+// A nil blocker disables block checks.
+func NewScheduler(submitter Submitter, blocker Blocker, logger *logger.Logger) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		entries:   make(map[string]*entry),
+		submitter: submitter,
+		blocker:   blocker,
+		clock:     realClock{},
+		logger:    logger,
+		history:   DefaultHistory,
+		wake:      make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// SetClock replaces the system clock. It must be called before Start or Add.
+func (s *Scheduler) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetHistory sets how many runs are kept per schedule (DefaultHistory if n < 1)
+func (s *Scheduler) SetHistory(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n < 1 {
+		n = DefaultHistory
+	}
+	s.history = n
+}
+
+// Validate checks the definition. The cron expression is checked by Add.
+func (d *Definition) Validate() error {
+	if !slices.Contains(Operations, d.Operation) {
+		return fmt.Errorf("unknown schedule operation: %q", d.Operation)
+	}
+	if d.Timeout <= 0 {
+		return fmt.Errorf("invalid timeout %d: must be positive", d.Timeout)
+	}
+	if d.Source != SourceConfig && d.Source != SourceAPI {
+		return fmt.Errorf("unknown schedule source: %q", d.Source)
+	}
+	return nil
+}
+
+// Add validates and registers a schedule. The ID and creation time are
+// assigned unless already set, as for schedules restored from the state file.
+func (s *Scheduler) Add(def Definition) (*Schedule, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	cron, err := ParseCron(def.Cron)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if def.Name != "" {
+		for _, e := range s.entries {
+			if e.def.Name == def.Name {
+				return nil, fmt.Errorf("%w: %q", ErrDuplicateName, def.Name)
+			}
+		}
+	}
+
+	now := s.clock.Now()
+	if def.ID == "" {
+		def.ID = uuid.New().String()
+	}
+	if def.CreatedAt.IsZero() {
+		def.CreatedAt = now
+	}
+	def.Targets = slices.Clone(def.Targets)
+	def.Ignore = slices.Clone(def.Ignore)
+
+	e := &entry{def: def, cron: cron, next: cron.Next(now)}
+	s.entries[def.ID] = e
+	if def.Source == SourceAPI {
+		s.save()
+	}
+	s.notify()
+
+	s.logger.Info("Schedule added",
+		"schedule_id", def.ID,
+		"name", def.Name,
+		"cron", def.Cron,
+		"operation", string(def.Operation),
+		"targets", def.Targets,
+		"source", string(def.Source),
+		"next_run", e.next.Format(time.RFC3339))
+
+	result := s.snapshot(e)
+	return &result, nil
+}
+
+// Remove deletes a schedule added through the API. A run in progress is
+// left to finish.
+func (s *Scheduler) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, exists := s.entries[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if e.def.Source == SourceConfig {
+		return fmt.Errorf("%w: %s", ErrReadOnly, id)
+	}
+
+	delete(s.entries, id)
+	s.save()
+	s.notify()
+
+	s.logger.Info("Schedule removed", "schedule_id", id)
+	return nil
+}
+
+// Get returns the schedule with the given ID
+func (s *Scheduler) Get(id string) (*Schedule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, exists := s.entries[id]
+	if !exists {
+		return nil, false
+	}
+	result := s.snapshot(e)
+	return &result, true
+}
+
+// List returns all schedules, oldest first
+func (s *Scheduler) List() []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Schedule, 0, len(s.entries))
+	for _, e := range s.entries {
+		result = append(result, s.snapshot(e))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// snapshot copies an entry for callers. Caller must hold s.mu.
+func (s *Scheduler) snapshot(e *entry) Schedule {
+	result := Schedule{Definition: e.def, NextRun: e.next, Runs: make([]Run, 0, len(e.runs))}
+	result.Targets = slices.Clone(e.def.Targets)
+	result.Ignore = slices.Clone(e.def.Ignore)
+	for _, run := range slices.Backward(e.runs) {
+		copied := *run
+		copied.JobIDs = slices.Clone(run.JobIDs)
+		result.Runs = append(result.Runs, copied)
+	}
+	return result
+}
+
+// notify wakes the loop to recompute the next fire time
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start fires schedules in the background until Stop is called
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop stops firing schedules and waits for runs in progress to give up
+// waiting on their jobs. The jobs themselves are left to the job manager.
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// loop sleeps until the next schedule is due and fires it
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	for {
+		now := s.clock.Now()
+		s.fireDue(now)
+
+		var timer <-chan time.Time
+		if next, ok := s.nextFire(); ok {
+			timer = s.clock.After(next.Sub(now))
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer:
+		}
+	}
+}
+
+// nextFire returns the earliest fire time across all schedules
+func (s *Scheduler) nextFire() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, e := range s.entries {
+		if !e.next.IsZero() && (next.IsZero() || e.next.Before(next)) {
+			next = e.next
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// fireDue fires every schedule whose fire time has come. Fire times missed
+// while the controller was busy fire once, not once per miss. Fire times are
+// not persisted, so those that passed while the controller was down are not
+// caught up.
+func (s *Scheduler) fireDue(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*entry
+	for _, e := range s.entries {
+		if !e.next.IsZero() && !e.next.After(now) {
+			due = append(due, e)
+		}
+	}
+	slices.SortFunc(due, func(a, b *entry) int {
+		return a.next.Compare(b.next)
+	})
+
+	for _, e := range due {
+		scheduledAt := e.next
+		e.next = e.cron.Next(now)
+		s.fireLocked(e, scheduledAt)
+	}
+}
+
+// fireLocked starts a run of the schedule unless it must be skipped.
+// Caller must hold s.mu.
+func (s *Scheduler) fireLocked(e *entry, scheduledAt time.Time) {
+	log := s.logger.With("schedule_id", e.def.ID, "name", e.def.Name)
+
+	if e.active != nil {
+		reason := "previous run still running"
+		s.recordLocked(e, &Run{ScheduledAt: scheduledAt, FinishedAt: scheduledAt, Status: RunStatusSkipped, Reason: reason})
+		log.Warn("Scheduled run skipped", "reason", reason)
+		return
+	}
+
+	if block, blocked := s.blocking(e.def.Operation); blocked {
+		reason := "blocked by block " + block.ID
+		if block.Reason != "" {
+			reason += ": " + block.Reason
+		}
+		s.recordLocked(e, &Run{ScheduledAt: scheduledAt, FinishedAt: scheduledAt, Status: RunStatusSkipped, Reason: reason})
+		log.Warn("Scheduled run skipped", "reason", reason)
+		return
+	}
+
+	run := &Run{ScheduledAt: scheduledAt, Status: RunStatusRunning}
+	s.recordLocked(e, run)
+	e.active = run
+
+	log.Info("Schedule fired",
+		"operation", string(e.def.Operation),
+		"scheduled_at", scheduledAt.Format(time.RFC3339))
+
+	s.wg.Add(1)
+	go s.execute(e, run, log)
+}
+
+// blocking returns a block covering any job the operation submits
+func (s *Scheduler) blocking(op Operation) (*blocks.Block, bool) {
+	if s.blocker == nil {
+		return nil, false
+	}
+
+	var ops []blocks.Operation
+	switch op {
+	case OperationStart:
+		ops = []blocks.Operation{blocks.OperationStart}
+	case OperationStop:
+		ops = []blocks.Operation{blocks.OperationStop}
+	case OperationRestart:
+		ops = []blocks.Operation{blocks.OperationStop, blocks.OperationStart}
+	}
+
+	for _, blockOp := range ops {
+		if block, blocked := s.blocker.BlockingOperation(blockOp); blocked {
+			return block, true
+		}
+	}
+	return nil, false
+}
+
+// recordLocked appends a run, dropping the oldest beyond the history limit.
+// Caller must hold s.mu.
+func (s *Scheduler) recordLocked(e *entry, run *Run) {
+	e.runs = append(e.runs, run)
+	if excess := len(e.runs) - s.history; excess > 0 {
+		clear(e.runs[:excess])
+		e.runs = e.runs[excess:]
+	}
+}
+
+// execute submits the run's jobs and records the outcome
+func (s *Scheduler) execute(e *entry, run *Run, log *logger.Logger) {
+	defer s.wg.Done()
+
+	err := s.runJobs(e.def, run)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run.FinishedAt = s.clock.Now()
+	run.Status = RunStatusCompleted
+	if err != nil {
+		run.Status = RunStatusFailed
+		run.Reason = err.Error()
+	}
+	e.active = nil
+
+	if err != nil {
+		log.Error("Scheduled run failed", "job_ids", run.JobIDs, "error", err)
+	} else {
+		log.Info("Scheduled run completed", "job_ids", run.JobIDs)
+	}
+}
+
+// runJobs submits the jobs for the operation and waits for them to finish.
+// A restart starts the targets again, along with the dependents that were
+// stopped with them, once the stop job has run, even if some containers
+// failed to stop, so that none are left down.
+func (s *Scheduler) runJobs(def Definition, run *Run) error {
+	switch def.Operation {
+	case OperationStart:
+		_, err := s.runJob(def, run, jobs.JobTypeStart, def.Targets)
+		return err
+	case OperationStop:
+		_, err := s.runJob(def, run, jobs.JobTypeStop, def.Targets)
+		return err
+	case OperationRestart:
+		stopJob, stopErr := s.runJob(def, run, jobs.JobTypeStop, def.Targets)
+		if stopJob == nil || stopJob.Status == jobs.JobStatusCancelled {
+			return fmt.Errorf("not starting containers: %w", stopErr)
+		}
+		_, startErr := s.runJob(def, run, jobs.JobTypeStart, jobs.RestartTargets(def.Targets, stopJob.Stopped))
+		return errors.Join(stopErr, startErr)
+	default:
+		return fmt.Errorf("unknown schedule operation: %q", def.Operation)
+	}
+}
+
+// runJob submits one job and waits for it, returning an error unless it
+// completed without failed containers
+func (s *Scheduler) runJob(def Definition, run *Run, jobType jobs.JobType, targets []string) (*jobs.Job, error) {
+	job := jobs.NewJob(jobType, def.Timeout, slices.Clone(def.Ignore))
+	job.Targets = slices.Clone(targets)
+	job.IncludeDependencies = def.IncludeDependencies
+
+	id, err := s.submitter.Submit(job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit %s job: %w", jobType, err)
+	}
+
+	s.mu.Lock()
+	run.JobIDs = append(run.JobIDs, id)
+	s.mu.Unlock()
+
+	finished, err := s.wait(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch finished.Status {
+	case jobs.JobStatusCompleted:
+		if len(finished.Failed) > 0 {
+			return finished, fmt.Errorf("%s job %s: %d containers failed", jobType, id, len(finished.Failed))
+		}
+		return finished, nil
+	case jobs.JobStatusCancelled:
+		return finished, fmt.Errorf("%s job %s was cancelled: %s", jobType, id, finished.Error)
+	default:
+		return finished, fmt.Errorf("%s job %s failed: %s", jobType, id, finished.Error)
+	}
+}
+
+// wait polls the job until it finishes or the scheduler stops
+func (s *Scheduler) wait(id string) (*jobs.Job, error) {
+	for {
+		job, err := s.submitter.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if job.IsFinished() {
+			return job, nil
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for job %s: scheduler shutting down", id)
+		case <-s.clock.After(PollInterval):
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saltyorg/sdc/internal/blocks"
+	"github.com/saltyorg/sdc/internal/jobs"
+	"github.com/saltyorg/sdc/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock only moves when advanced
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward and fires the waiters that are due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = remaining
+}
+
+// Waiters returns how many callers are waiting on the clock
+func (c *fakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// fakeSubmitter records jobs; onSubmit may finish them straight away
+type fakeSubmitter struct {
+	mu       sync.Mutex
+	jobs     map[string]*jobs.Job
+	order    []*jobs.Job
+	onSubmit func(job *jobs.Job)
+}
+
+func newFakeSubmitter(onSubmit func(job *jobs.Job)) *fakeSubmitter {
+	return &fakeSubmitter{jobs: make(map[string]*jobs.Job), onSubmit: onSubmit}
+}
+
+func (f *fakeSubmitter) Submit(job *jobs.Job) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.jobs[job.ID] = job
+	f.order = append(f.order, job)
+	if f.onSubmit != nil {
+		f.onSubmit(job)
+	}
+	return job.ID, nil
+}
+
+func (f *fakeSubmitter) Get(id string) (*jobs.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	job, ok := f.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	return job.Clone(), nil
+}
+
+func (f *fakeSubmitter) Submitted() []*jobs.Job {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := make([]*jobs.Job, len(f.order))
+	for i, job := range f.order {
+		result[i] = job.Clone()
+	}
+	return result
+}
+
+// Finish marks a job as completed
+func (f *fakeSubmitter) Finish(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs[id].SetStatus(jobs.JobStatusCompleted)
+}
+
+// completeJobs finishes every job as soon as it is submitted
+func completeJobs(job *jobs.Job) {
+	job.SetStatus(jobs.JobStatusCompleted)
+}
+
+func newTestScheduler(t *testing.T, submitter Submitter, blocker Blocker, clock Clock) *Scheduler {
+	t.Helper()
+	log, _ := logger.New(false)
+	s := NewScheduler(submitter, blocker, log)
+	s.SetClock(clock)
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// waitForRun waits until the newest run of the schedule has finished
+func waitForRun(t *testing.T, s *Scheduler, id string) Run {
+	t.Helper()
+	var run Run
+	require.Eventually(t, func() bool {
+		schedule, ok := s.Get(id)
+		if !ok || len(schedule.Runs) == 0 || schedule.Runs[0].Status == RunStatusRunning {
+			return false
+		}
+		run = schedule.Runs[0]
+		return true
+	}, 2*time.Second, 5*time.Millisecond)
+	return run
+}
+
+var testStart = time.Date(2026, time.March, 4, 3, 59, 30, 0, time.UTC)
+
+func TestScheduler_Add(t *testing.T) {
+	s := newTestScheduler(t, newFakeSubmitter(nil), nil, newFakeClock(testStart))
+
+	valid := Definition{Name: "nightly", Cron: "0 4 * * *", Operation: OperationRestart, Timeout: 600, Source: SourceAPI}
+
+	schedule, err := s.Add(valid)
+	require.NoError(t, err)
+	assert.NotEmpty(t, schedule.ID)
+	assert.Equal(t, testStart, schedule.CreatedAt)
+	assert.Equal(t, time.Date(2026, time.March, 4, 4, 0, 0, 0, time.UTC), schedule.NextRun)
+	assert.Empty(t, schedule.Runs)
+
+	_, err = s.Add(valid)
+	assert.ErrorIs(t, err, ErrDuplicateName)
+
+	invalid := []Definition{
+		{Cron: "0 4 * *", Operation: OperationStart, Timeout: 600, Source: SourceAPI},
+		{Cron: "0 4 * * *", Operation: "reload", Timeout: 600, Source: SourceAPI},
+		{Cron: "0 4 * * *", Operation: OperationStart, Source: SourceAPI},
+		{Cron: "0 4 * * *", Operation: OperationStart, Timeout: 600},
+	}
+	for _, def := range invalid {
+		_, err := s.Add(def)
+		assert.Error(t, err, def)
+	}
+
+	config, err := s.Add(Definition{Cron: "@daily", Operation: OperationStop, Timeout: 300, Source: SourceConfig})
+	require.NoError(t, err)
+
+	assert.Len(t, s.List(), 2)
+	assert.ErrorIs(t, s.Remove(config.ID), ErrReadOnly)
+	assert.ErrorIs(t, s.Remove("missing"), ErrNotFound)
+	require.NoError(t, s.Remove(schedule.ID))
+
+	_, ok := s.Get(schedule.ID)
+	assert.False(t, ok)
+}
+
+func TestScheduler_FireStart(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(completeJobs)
+	s := newTestScheduler(t, submitter, nil, clock)
+
+	schedule, err := s.Add(Definition{
+		Cron:                "0 4 * * *",
+		Operation:           OperationStart,
+		Targets:             []string{"group=media"},
+		Ignore:              []string{"plex"},
+		Timeout:             120,
+		IncludeDependencies: true,
+		Source:              SourceAPI,
+	})
+	require.NoError(t, err)
+
+	// Not due yet
+	s.fireDue(clock.Now())
+	assert.Empty(t, submitter.Submitted())
+
+	clock.Advance(30 * time.Second)
+	s.fireDue(clock.Now())
+
+	run := waitForRun(t, s, schedule.ID)
+	assert.Equal(t, RunStatusCompleted, run.Status)
+	assert.Equal(t, time.Date(2026, time.March, 4, 4, 0, 0, 0, time.UTC), run.ScheduledAt)
+
+	submitted := submitter.Submitted()
+	require.Len(t, submitted, 1)
+	job := submitted[0]
+	assert.Equal(t, []string{job.ID}, run.JobIDs)
+	assert.Equal(t, jobs.JobTypeStart, job.Type)
+	assert.Equal(t, []string{"group=media"}, job.Targets)
+	assert.Equal(t, []string{"plex"}, job.Ignore)
+	assert.Equal(t, 120, job.Timeout)
+	assert.True(t, job.IncludeDependencies)
+
+	schedule, _ = s.Get(schedule.ID)
+	assert.Equal(t, time.Date(2026, time.March, 5, 4, 0, 0, 0, time.UTC), schedule.NextRun)
+}
+
+func TestScheduler_Restart(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(func(job *jobs.Job) {
+		if job.Type == jobs.JobTypeStop {
+			// A dependent was stopped along with the target
+			job.SetResults(nil, []string{"plex", "tautulli"}, nil, nil)
+		}
+		job.SetStatus(jobs.JobStatusCompleted)
+	})
+	s := newTestScheduler(t, submitter, nil, clock)
+
+	schedule, err := s.Add(Definition{Cron: "0 4 * * *", Operation: OperationRestart, Targets: []string{"plex"}, Timeout: 300, Source: SourceAPI})
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+
+	run := waitForRun(t, s, schedule.ID)
+	assert.Equal(t, RunStatusCompleted, run.Status)
+
+	submitted := submitter.Submitted()
+	require.Len(t, submitted, 2)
+	assert.Equal(t, jobs.JobTypeStop, submitted[0].Type)
+	assert.Equal(t, []string{"plex"}, submitted[0].Targets)
+	assert.Equal(t, jobs.JobTypeStart, submitted[1].Type)
+	assert.Equal(t, []string{"plex", "tautulli"}, submitted[1].Targets)
+	assert.Equal(t, []string{submitted[0].ID, submitted[1].ID}, run.JobIDs)
+}
+
+func TestScheduler_RestartStopFailed(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(func(job *jobs.Job) {
+		if job.Type == jobs.JobTypeStop {
+			// The target failed to stop, but its dependent was stopped
+			job.SetResults(nil, []string{"tautulli"}, nil, []string{"plex"})
+		}
+		job.SetStatus(jobs.JobStatusCompleted)
+	})
+	s := newTestScheduler(t, submitter, nil, clock)
+
+	schedule, err := s.Add(Definition{Cron: "0 4 * * *", Operation: OperationRestart, Targets: []string{"plex"}, Timeout: 300, Source: SourceAPI})
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+
+	// The containers are started again, and the stop failure is reported
+	run := waitForRun(t, s, schedule.ID)
+	assert.Equal(t, RunStatusFailed, run.Status)
+	assert.Contains(t, run.Reason, "stop job")
+	assert.Contains(t, run.Reason, "1 containers failed")
+
+	submitted := submitter.Submitted()
+	require.Len(t, submitted, 2)
+	assert.Equal(t, jobs.JobTypeStart, submitted[1].Type)
+	assert.Equal(t, []string{"plex", "tautulli"}, submitted[1].Targets)
+	assert.Len(t, run.JobIDs, 2)
+}
+
+func TestScheduler_RestartStopCancelled(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(func(job *jobs.Job) {
+		job.Cancel("other-job")
+	})
+	s := newTestScheduler(t, submitter, nil, clock)
+
+	schedule, err := s.Add(Definition{Cron: "0 4 * * *", Operation: OperationRestart, Timeout: 300, Source: SourceAPI})
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+
+	run := waitForRun(t, s, schedule.ID)
+	assert.Equal(t, RunStatusFailed, run.Status)
+	assert.Contains(t, run.Reason, "not starting containers")
+	assert.Len(t, submitter.Submitted(), 1)
+}
+
+func TestScheduler_SkipWhileRunning(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(nil) // Jobs stay pending
+	s := newTestScheduler(t, submitter, nil, clock)
+
+	schedule, err := s.Add(Definition{Cron: "* * * * *", Operation: OperationStop, Timeout: 300, Source: SourceAPI})
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+
+	schedule, _ = s.Get(schedule.ID)
+	require.Len(t, schedule.Runs, 2)
+	assert.Equal(t, RunStatusSkipped, schedule.Runs[0].Status)
+	assert.Equal(t, "previous run still running", schedule.Runs[0].Reason)
+	assert.Equal(t, RunStatusRunning, schedule.Runs[1].Status)
+
+	// Once the job finishes, the next run goes ahead
+	require.Eventually(t, func() bool { return len(submitter.Submitted()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	submitter.Finish(submitter.Submitted()[0].ID)
+
+	require.Eventually(t, func() bool {
+		clock.Advance(PollInterval)
+		schedule, _ := s.Get(schedule.ID)
+		return schedule.Runs[1].Status == RunStatusCompleted
+	}, 2*time.Second, 5*time.Millisecond)
+
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+	require.Eventually(t, func() bool { return len(submitter.Submitted()) == 2 }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestScheduler_SkipWhenBlocked(t *testing.T) {
+	log, _ := logger.New(false)
+	registry := blocks.NewRegistry(log)
+	defer registry.Close()
+
+	block, err := registry.Add(blocks.Block{Scope: blocks.ScopeStart, Reason: "upgrading"}, time.Hour)
+	require.NoError(t, err)
+
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(completeJobs)
+	s := newTestScheduler(t, submitter, registry, clock)
+
+	restart, err := s.Add(Definition{Cron: "0 4 * * *", Operation: OperationRestart, Timeout: 300, Source: SourceAPI})
+	require.NoError(t, err)
+	stop, err := s.Add(Definition{Cron: "0 4 * * *", Operation: OperationStop, Timeout: 300, Source: SourceAPI})
+	require.NoError(t, err)
+
+	clock.Advance(time.Minute)
+	s.fireDue(clock.Now())
+
+	// A restart starts containers, so a start block skips it
+	restarted, _ := s.Get(restart.ID)
+	require.Len(t, restarted.Runs, 1)
+	assert.Equal(t, RunStatusSkipped, restarted.Runs[0].Status)
+	assert.Equal(t, "blocked by block "+block.ID+": upgrading", restarted.Runs[0].Reason)
+
+	run := waitForRun(t, s, stop.ID)
+	assert.Equal(t, RunStatusCompleted, run.Status)
+	assert.Len(t, submitter.Submitted(), 1)
+}
+
+func TestScheduler_History(t *testing.T) {
+	log, _ := logger.New(false)
+	registry := blocks.NewRegistry(log)
+	defer registry.Close()
+	_, err := registry.Add(blocks.Block{Scope: blocks.ScopeGlobal}, time.Hour)
+	require.NoError(t, err)
+
+	clock := newFakeClock(testStart)
+	s := newTestScheduler(t, newFakeSubmitter(nil), registry, clock)
+	s.SetHistory(3)
+
+	schedule, err := s.Add(Definition{Cron: "* * * * *", Operation: OperationStart, Timeout: 600, Source: SourceAPI})
+	require.NoError(t, err)
+
+	for range 5 {
+		clock.Advance(time.Minute)
+		s.fireDue(clock.Now())
+	}
+
+	schedule, _ = s.Get(schedule.ID)
+	require.Len(t, schedule.Runs, 3)
+	assert.Equal(t, time.Date(2026, time.March, 4, 4, 4, 0, 0, time.UTC), schedule.Runs[0].ScheduledAt)
+	assert.Equal(t, time.Date(2026, time.March, 4, 4, 2, 0, 0, time.UTC), schedule.Runs[2].ScheduledAt)
+}
+
+func TestScheduler_MissedFiresRunOnce(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(completeJobs)
+	s := newTestScheduler(t, submitter, nil, clock)
+
+	schedule, err := s.Add(Definition{Cron: "*/5 * * * *", Operation: OperationStart, Timeout: 600, Source: SourceAPI})
+	require.NoError(t, err)
+
+	clock.Advance(time.Hour)
+	s.fireDue(clock.Now())
+
+	waitForRun(t, s, schedule.ID)
+	schedule, _ = s.Get(schedule.ID)
+	assert.Len(t, schedule.Runs, 1)
+	assert.Equal(t, time.Date(2026, time.March, 4, 5, 0, 0, 0, time.UTC), schedule.NextRun)
+}
+
+func TestScheduler_Loop(t *testing.T) {
+	clock := newFakeClock(testStart)
+	submitter := newFakeSubmitter(completeJobs)
+	s := newTestScheduler(t, submitter, nil, clock)
+	s.Start()
+
+	schedule, err := s.Add(Definition{Cron: "0 4 * * *", Operation: OperationStart, Timeout: 600, Source: SourceAPI})
+	require.NoError(t, err)
+
+	// The loop sleeps until the schedule is due
+	require.Eventually(t, func() bool { return clock.Waiters() > 0 }, 2*time.Second, 5*time.Millisecond)
+	assert.Empty(t, submitter.Submitted())
+
+	clock.Advance(30 * time.Second)
+
+	run := waitForRun(t, s, schedule.ID)
+	assert.Equal(t, RunStatusCompleted, run.Status)
+	assert.Len(t, submitter.Submitted(), 1)
+}
@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/saltyorg/sdc/internal/statefile"
+)
+
+// stateVersion is the format version of the state file
+const stateVersion = 1
+
+// state is the on-disk representation of the schedules added through the API
+type state struct {
+	Version   int          `json:"version"`
+	Schedules []Definition `json:"schedules"`
+}
+
+// Persist restores schedules added through the API from the state file and
+// keeps the file up to date from then on. Run history is not persisted.
+// A missing state file is not an error.
+func (s *Scheduler) Persist(path string) error {
+	restored, err := readState(path)
+	if err != nil {
+		return err
+	}
+
+	for _, def := range restored {
+		def.Source = SourceAPI
+		if _, err := s.Add(def); err != nil {
+			return fmt.Errorf("invalid schedule %s in %s: %w", def.ID, path, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stateFile = path
+
+	// Write back immediately so the file is known to be writable
+	if err := s.writeState(); err != nil {
+		s.stateFile = ""
+		return err
+	}
+
+	return nil
+}
+
+// readState loads schedule definitions from the state file
+func readState(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule state: %w", err)
+	}
+
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule state %s: %w", path, err)
+	}
+	if st.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported schedule state version %d in %s", st.Version, path)
+	}
+
+	return st.Schedules, nil
+}
+
+// save writes the state file, logging failures. Caller must hold s.mu.
+func (s *Scheduler) save() {
+	if err := s.writeState(); err != nil {
+		s.logger.Error("Failed to save schedule state",
+			"path", s.stateFile,
+			"error", err)
+	}
+}
+
+// writeState atomically replaces the state file. Caller must hold s.mu.
+func (s *Scheduler) writeState() error {
+	if s.stateFile == "" {
+		return nil
+	}
+
+	st := state{Version: stateVersion, Schedules: []Definition{}}
+	for _, e := range s.entries {
+		if e.def.Source == SourceAPI {
+			st.Schedules = append(st.Schedules, e.def)
+		}
+	}
+	sort.Slice(st.Schedules, func(i, j int) bool {
+		return st.Schedules[i].ID < st.Schedules[j].ID
+	})
+
+	if err := statefile.WriteJSON(s.stateFile, st); err != nil {
+		return fmt.Errorf("failed to save schedule state: %w", err)
+	}
+	return nil
+}
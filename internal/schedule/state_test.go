@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "schedules.json")
+
+	s := newTestScheduler(t, newFakeSubmitter(nil), nil, newFakeClock(testStart))
+	require.NoError(t, s.Persist(path))
+
+	api, err := s.Add(Definition{Name: "nightly", Cron: "0 4 * * *", Operation: OperationRestart, Targets: []string{"plex"}, Timeout: 600, Source: SourceAPI})
+	require.NoError(t, err)
+	_, err = s.Add(Definition{Cron: "@daily", Operation: OperationStop, Timeout: 300, Source: SourceConfig})
+	require.NoError(t, err)
+
+	// Only schedules added through the API are restored
+	restored := newTestScheduler(t, newFakeSubmitter(nil), nil, newFakeClock(testStart))
+	require.NoError(t, restored.Persist(path))
+
+	schedules := restored.List()
+	require.Len(t, schedules, 1)
+	assert.Equal(t, api.Definition, schedules[0].Definition)
+	assert.Equal(t, api.NextRun, schedules[0].NextRun)
+
+	require.NoError(t, restored.Remove(api.ID))
+
+	again := newTestScheduler(t, newFakeSubmitter(nil), nil, newFakeClock(testStart))
+	require.NoError(t, again.Persist(path))
+	assert.Empty(t, again.List())
+}
+
+func TestPersist_InvalidState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	s := newTestScheduler(t, newFakeSubmitter(nil), nil, newFakeClock(testStart))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "schedules": []}`), 0600))
+	assert.ErrorContains(t, s.Persist(path), "unsupported schedule state version")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "schedules": [{"id": "x", "cron": "bogus", "operation": "start", "timeout": 600}]}`), 0600))
+	assert.ErrorContains(t, s.Persist(path), "invalid schedule x")
+}
@@ -0,0 +1,36 @@
+package statefile
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+
+	require.NoError(t, WriteJSON(path, map[string]int{"version": 1}))
+	require.NoError(t, WriteJSON(path, map[string]int{"version": 2}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var got map[string]int
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, map[string]int{"version": 2}, got)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are cleaned up")
+}
+
+func TestWriteJSON_EncodeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	require.Error(t, WriteJSON(path, make(chan int)))
+	assert.NoFileExists(t, path)
+}
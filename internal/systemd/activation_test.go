@@ -0,0 +1,30 @@
+package systemd
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListeners_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	t.Setenv("LISTEN_FDS", "")
+
+	listeners, err := Listeners()
+	assert.NoError(t, err)
+	assert.Nil(t, listeners)
+}
+
+func TestListeners_OtherProcess(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+
+	listeners, err := Listeners()
+	assert.NoError(t, err)
+	assert.Nil(t, listeners)
+
+	// Variables are cleared either way
+	assert.Empty(t, os.Getenv("LISTEN_FDS"))
+}
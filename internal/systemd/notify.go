@@ -0,0 +1,124 @@
+package systemd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notifier sends sd_notify(3) state updates to the service manager.
+// A nil or disabled Notifier silently ignores all updates, so callers
+// don't need to check whether they run under systemd.
+type Notifier struct {
+	addr *net.UnixAddr
+}
+
+// NewNotifier creates a notifier for the socket in NOTIFY_SOCKET.
+// The returned notifier is disabled when the variable is not set.
+func NewNotifier() *Notifier {
+	return NewNotifierForSocket(os.Getenv("NOTIFY_SOCKET"))
+}
+
+// NewNotifierForSocket creates a notifier for the given socket path.
+// A leading '@' denotes a Linux abstract socket.
+func NewNotifierForSocket(socket string) *Notifier {
+	if socket == "" {
+		return &Notifier{}
+	}
+
+	name := socket
+	if strings.HasPrefix(name, "@") {
+		name = "\x00" + name[1:]
+	}
+
+	return &Notifier{addr: &net.UnixAddr{Name: name, Net: "unixgram"}}
+}
+
+// Enabled reports whether notifications are delivered anywhere
+func (n *Notifier) Enabled() bool {
+	return n != nil && n.addr != nil
+}
+
+// Notify sends a raw state string such as "READY=1" or "STATUS=...".
+// Multiple assignments may be separated by newlines.
+func (n *Notifier) Notify(state string) error {
+	if !n.Enabled() {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return nil
+}
+
+// Ready tells the service manager that startup is complete
+func (n *Notifier) Ready() error {
+	return n.Notify("READY=1")
+}
+
+// Stopping tells the service manager that the service is shutting down
+func (n *Notifier) Stopping() error {
+	return n.Notify("STOPPING=1")
+}
+
+// Status sets the free-form status line shown by systemctl status
+func (n *Notifier) Status(format string, args ...any) error {
+	status := fmt.Sprintf(format, args...)
+	// Newlines would start a new assignment
+	status = strings.ReplaceAll(status, "\n", " ")
+	return n.Notify("STATUS=" + status)
+}
+
+// Watchdog sends a keep-alive ping
+func (n *Notifier) Watchdog() error {
+	return n.Notify("WATCHDOG=1")
+}
+
+// WatchdogInterval returns the watchdog timeout configured by WatchdogSec=
+// (WATCHDOG_USEC), or false when the watchdog is disabled for this process
+func WatchdogInterval() (time.Duration, bool) {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// RunWatchdog sends WATCHDOG=1 every interval for as long as healthy reports
+// true, until ctx is cancelled. Pings are withheld while unhealthy so the
+// service manager restarts a service that has lost its dependencies.
+func (n *Notifier) RunWatchdog(ctx context.Context, interval time.Duration, healthy func(context.Context) bool) {
+	if !n.Enabled() || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if healthy(ctx) {
+				n.Watchdog()
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package systemd
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenNotifySocket stands in for systemd's notification socket
+func listenNotifySocket(t *testing.T) (string, *net.UnixConn) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return path, conn
+}
+
+func readNotification(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func TestNotifier_Disabled(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	n := NewNotifier()
+	assert.False(t, n.Enabled())
+	assert.NoError(t, n.Ready())
+
+	var nilNotifier *Notifier
+	assert.NoError(t, nilNotifier.Status("ignored"))
+}
+
+func TestNotifier_SendsStates(t *testing.T) {
+	path, conn := listenNotifySocket(t)
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	n := NewNotifier()
+	require.True(t, n.Enabled())
+
+	require.NoError(t, n.Status("Starting containers (job %s)", "abc"))
+	assert.Equal(t, "STATUS=Starting containers (job abc)", readNotification(t, conn))
+
+	require.NoError(t, n.Ready())
+	assert.Equal(t, "READY=1", readNotification(t, conn))
+
+	require.NoError(t, n.Status("multi\nline"))
+	assert.Equal(t, "STATUS=multi line", readNotification(t, conn))
+
+	require.NoError(t, n.Stopping())
+	assert.Equal(t, "STOPPING=1", readNotification(t, conn))
+}
+
+func TestNotifier_RunWatchdog(t *testing.T) {
+	path, conn := listenNotifySocket(t)
+	n := NewNotifierForSocket(path)
+
+	var healthy atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go n.RunWatchdog(ctx, 20*time.Millisecond, func(context.Context) bool {
+		return healthy.Load()
+	})
+
+	// No pings while unhealthy
+	conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 64))
+	assert.Error(t, err)
+
+	healthy.Store(true)
+	assert.Equal(t, "WATCHDOG=1", readNotification(t, conn))
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "")
+	_, ok := WatchdogInterval()
+	assert.False(t, ok)
+
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	interval, ok := WatchdogInterval()
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, interval)
+
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	_, ok = WatchdogInterval()
+	assert.False(t, ok)
+}
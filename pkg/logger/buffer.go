@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBufferSize is the default number of entries retained by a Buffer
+const DefaultBufferSize = 500
+
+// Entry is a single captured log line
+type Entry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+}
+
+// Buffer is a bounded, thread-safe ring buffer of log entries.
+// Once full, the oldest entries are discarded.
+type Buffer struct {
+	mu      sync.RWMutex
+	entries []Entry
+	start   int
+	count   int
+	dropped int
+}
+
+// NewBuffer creates a buffer holding at most size entries
+func NewBuffer(size int) *Buffer {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Buffer{entries: make([]Entry, size)}
+}
+
+// Append adds an entry, evicting the oldest one if the buffer is full
+func (b *Buffer) Append(e Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.count < len(b.entries) {
+		b.entries[(b.start+b.count)%len(b.entries)] = e
+		b.count++
+		return
+	}
+
+	b.entries[b.start] = e
+	b.start = (b.start + 1) % len(b.entries)
+	b.dropped++
+}
+
+// Entries returns a copy of the buffered entries, oldest first
+func (b *Buffer) Entries() []Entry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	result := make([]Entry, b.count)
+	for i := 0; i < b.count; i++ {
+		result[i] = b.entries[(b.start+i)%len(b.entries)]
+	}
+	return result
+}
+
+// Dropped returns how many entries were evicted because the buffer was full
+func (b *Buffer) Dropped() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.dropped
+}
+
+// Len returns the number of buffered entries
+func (b *Buffer) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.count
+}
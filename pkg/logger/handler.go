@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// textHandler renders records as "2006/01/02 15:04:05 INFO: msg [k=v, k=v]"
+type textHandler struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	opts   *slog.HandlerOptions
+	attrs  []slog.Attr
+	prefix string // Group prefix applied to attribute keys
+}
+
+func newTextHandler(out io.Writer, opts *slog.HandlerOptions) *textHandler {
+	return &textHandler{out: out, mu: &sync.Mutex{}, opts: opts}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts != nil && h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var sb strings.Builder
+
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	sb.WriteString(t.Format("2006/01/02 15:04:05"))
+	sb.WriteByte(' ')
+	sb.WriteString(r.Level.String())
+	sb.WriteString(": ")
+	sb.WriteString(r.Message)
+
+	fields := make([]string, 0, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		fields = appendAttr(fields, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	if len(fields) > 0 {
+		sb.WriteString(" [")
+		sb.WriteString(strings.Join(fields, ", "))
+		sb.WriteByte(']')
+	}
+	sb.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, sb.String())
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = append(clone.attrs, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &clone
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// appendAttr formats an attribute as key=value, flattening groups
+func appendAttr(fields []string, prefix string, a slog.Attr) []string {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, groupPrefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, fmt.Sprintf("%s=%v", prefix+a.Key, a.Value.Any()))
+}
+
+// captureHandler forwards records to the next handler and records them in a Buffer
+type captureHandler struct {
+	next   slog.Handler
+	buf    *Buffer
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *captureHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *captureHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		collectAttr(fields, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		collectAttr(fields, h.prefix, a)
+		return true
+	})
+
+	h.buf.Append(Entry{
+		Time:    r.Time,
+		Level:   r.Level.String(),
+		Message: r.Message,
+		Fields:  fields,
+	})
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = append(clone.attrs, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &clone
+}
+
+func (h *captureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// collectAttr stores an attribute in fields, flattening groups into dotted keys
+func collectAttr(fields map[string]any, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix = prefix + a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			collectAttr(fields, groupPrefix, ga)
+		}
+		return
+	}
+
+	value := a.Value.Any()
+	switch v := value.(type) {
+	case error:
+		value = v.Error()
+	case time.Duration:
+		value = v.String()
+	case fmt.Stringer:
+		value = v.String()
+	}
+	fields[prefix+a.Key] = value
+}
@@ -1,67 +1,147 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 )
 
-// Logger wraps stdlib log.Logger with convenience methods
+// Format selects the output encoding of log lines
+type Format string
+
+const (
+	// FormatText writes human readable lines (matching sdhm style)
+	FormatText Format = "text"
+	// FormatJSON writes one JSON object per line
+	FormatJSON Format = "json"
+)
+
+// Options configures a logger
+type Options struct {
+	Level  slog.Level
+	Format Format
+	Output io.Writer // Defaults to os.Stdout
+}
+
+// Logger wraps slog.Logger with convenience methods
 type Logger struct {
-	*log.Logger
-	debug bool
+	slog *slog.Logger
 }
 
-// New creates a new logger with timestamp formatting (matching sdhm style)
+// New creates a new text logger writing to stdout (matching sdhm style)
 func New(development bool) (*Logger, error) {
-	l := log.New(os.Stdout, "", log.LstdFlags)
-	return &Logger{Logger: l, debug: development}, nil
+	level := slog.LevelInfo
+	if development {
+		level = slog.LevelDebug
+	}
+	return NewWithOptions(Options{Level: level, Format: FormatText})
+}
+
+// NewWithOptions creates a new logger with the given level, format and output
+func NewWithOptions(opts Options) (*Logger, error) {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatText, "":
+		handler = newTextHandler(out, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", opts.Format)
+	}
+
+	return &Logger{slog: slog.New(handler)}, nil
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog.Level
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level: %s", level)
+	}
+}
+
+// ParseFormat validates a log format name (text, json)
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(format))) {
+	case FormatText, "":
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown log format: %s", format)
+	}
 }
 
 // Helper methods for structured logging with level prefixes
 
 func (l *Logger) Info(msg string, keysAndValues ...any) {
-	l.Printf("INFO: %s%s", msg, formatFields(keysAndValues...))
+	l.slog.Info(msg, keysAndValues...)
 }
 
 func (l *Logger) Error(msg string, keysAndValues ...any) {
-	l.Printf("ERROR: %s%s", msg, formatFields(keysAndValues...))
+	l.slog.Error(msg, keysAndValues...)
 }
 
 func (l *Logger) Warn(msg string, keysAndValues ...any) {
-	l.Printf("WARN: %s%s", msg, formatFields(keysAndValues...))
+	l.slog.Warn(msg, keysAndValues...)
 }
 
 func (l *Logger) Debug(msg string, keysAndValues ...any) {
-	if l.debug {
-		l.Printf("DEBUG: %s%s", msg, formatFields(keysAndValues...))
-	}
+	l.slog.Debug(msg, keysAndValues...)
+}
+
+// With returns a logger that adds the given key-value pairs to every line
+func (l *Logger) With(keysAndValues ...any) *Logger {
+	return &Logger{slog: l.slog.With(keysAndValues...)}
+}
+
+// WithCapture returns a logger that also records every line it emits into buf.
+// Lines below the parent's level are neither written nor captured.
+func (l *Logger) WithCapture(buf *Buffer) *Logger {
+	return &Logger{slog: slog.New(&captureHandler{next: l.slog.Handler(), buf: buf})}
 }
 
-// Sync is a no-op for stdlib logger (for compatibility with zap)
+// Slog returns the underlying slog.Logger
+func (l *Logger) Slog() *slog.Logger {
+	return l.slog
+}
+
+// Sync is a no-op for slog (for compatibility with zap)
 func (l *Logger) Sync() error {
 	return nil
 }
 
-// formatFields formats key-value pairs for logging
-func formatFields(keysAndValues ...any) string {
-	if len(keysAndValues) == 0 {
-		return ""
-	}
+type contextKey struct{}
 
-	result := " ["
-	for i := 0; i < len(keysAndValues); i += 2 {
-		if i > 0 {
-			result += ", "
-		}
-		if i+1 < len(keysAndValues) {
-			result += formatField(keysAndValues[i], keysAndValues[i+1])
-		}
-	}
-	result += "]"
-	return result
+// NewContext returns a copy of ctx carrying the given logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
 }
 
-func formatField(key, value any) string {
-	return fmt.Sprintf("%v=%v", key, value)
+// FromContext returns the logger stored in ctx, or fallback if there is none
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return fallback
 }
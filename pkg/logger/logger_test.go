@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextFormat(t *testing.T) {
+	var out bytes.Buffer
+	log, err := NewWithOptions(Options{Level: slog.LevelInfo, Format: FormatText, Output: &out})
+	require.NoError(t, err)
+
+	log.Info("Container started", "container", "plex", "delay", 5)
+
+	line := out.String()
+	assert.Contains(t, line, "INFO: Container started [container=plex, delay=5]")
+	assert.True(t, strings.HasSuffix(line, "\n"))
+}
+
+func TestJSONFormat(t *testing.T) {
+	var out bytes.Buffer
+	log, err := NewWithOptions(Options{Level: slog.LevelInfo, Format: FormatJSON, Output: &out})
+	require.NoError(t, err)
+
+	log.With("job_id", "abc").Warn("Health check timeout", "container", "plex")
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "Health check timeout", record["msg"])
+	assert.Equal(t, "abc", record["job_id"])
+	assert.Equal(t, "plex", record["container"])
+}
+
+func TestLevelFiltering(t *testing.T) {
+	var out bytes.Buffer
+	log, err := NewWithOptions(Options{Level: slog.LevelWarn, Output: &out})
+	require.NoError(t, err)
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.Error("error")
+
+	assert.NotContains(t, out.String(), "DEBUG: debug")
+	assert.NotContains(t, out.String(), "INFO: info")
+	assert.Contains(t, out.String(), "WARN: warn")
+	assert.Contains(t, out.String(), "ERROR: error")
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", slog.LevelDebug, false},
+		{"INFO", slog.LevelInfo, false},
+		{"", slog.LevelInfo, false},
+		{"warning", slog.LevelWarn, false},
+		{"error", slog.LevelError, false},
+		{"verbose", slog.LevelInfo, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, level)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestWithCapture(t *testing.T) {
+	var out bytes.Buffer
+	base, err := NewWithOptions(Options{Level: slog.LevelInfo, Output: &out})
+	require.NoError(t, err)
+
+	buf := NewBuffer(10)
+	log := base.With("job_id", "job-1").WithCapture(buf)
+
+	log.Info("Starting container", "container", "plex")
+	log.Debug("Not captured below level")
+
+	assert.Contains(t, out.String(), "job_id=job-1")
+
+	entries := buf.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "INFO", entries[0].Level)
+	assert.Equal(t, "Starting container", entries[0].Message)
+	assert.Equal(t, "plex", entries[0].Fields["container"])
+}
+
+func TestBuffer_Bounded(t *testing.T) {
+	buf := NewBuffer(3)
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		buf.Append(Entry{Message: msg})
+	}
+
+	entries := buf.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "c", entries[0].Message)
+	assert.Equal(t, "d", entries[1].Message)
+	assert.Equal(t, "e", entries[2].Message)
+	assert.Equal(t, 2, buf.Dropped())
+}
+
+func TestContext(t *testing.T) {
+	fallback, _ := New(false)
+	scoped := fallback.With("job_id", "x")
+
+	assert.Same(t, fallback, FromContext(context.Background(), fallback))
+	assert.Same(t, scoped, FromContext(NewContext(context.Background(), scoped), fallback))
+}